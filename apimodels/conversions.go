@@ -34,10 +34,12 @@ func ConvertTxModelToTxResponse(tx *dbmodels.Transaction, selectedTipBlueScore u
 		Mass:            tx.Mass,
 		Version:         tx.Version,
 		Raw:             hex.EncodeToString(tx.RawTransaction.TransactionData),
+		Status:          TransactionStatusIncluded,
 	}
 	if tx.AcceptingBlock != nil {
 		txRes.AcceptingBlockHash = &tx.AcceptingBlock.BlockHash
 		txRes.AcceptingBlockBlueScore = &tx.AcceptingBlock.BlueScore
+		txRes.Status = TransactionStatusAccepted
 	}
 
 	txRes.Confirmations = pointers.Uint64(confirmations(txRes.AcceptingBlockBlueScore, selectedTipBlueScore))
@@ -75,6 +77,57 @@ func ConvertTxModelToTxResponse(tx *dbmodels.Transaction, selectedTipBlueScore u
 	return txRes
 }
 
+// ConvertMempoolTxModelToTxResponse converts a mempool transaction database
+// object into a TransactionResponse with a pending status
+func ConvertMempoolTxModelToTxResponse(tx *dbmodels.MempoolTransaction) *TransactionResponse {
+	txRes := &TransactionResponse{
+		TransactionHash: tx.TransactionHash,
+		TransactionID:   tx.TransactionID,
+		SubnetworkID:    tx.SubnetworkID,
+		LockTime:        serializer.BytesToUint64(tx.LockTime),
+		Gas:             tx.Gas,
+		PayloadHash:     tx.PayloadHash,
+		Payload:         hex.EncodeToString(tx.Payload),
+		Inputs:          make([]*TransactionInputResponse, len(tx.MempoolTransactionInputs)),
+		Outputs:         make([]*TransactionOutputResponse, len(tx.MempoolTransactionOutputs)),
+		Version:         tx.Version,
+		Raw:             hex.EncodeToString(tx.RawTransaction),
+		Status:          TransactionStatusPending,
+	}
+
+	for i, txOut := range tx.MempoolTransactionOutputs {
+		txRes.Outputs[i] = &TransactionOutputResponse{
+			Value:        txOut.Value,
+			ScriptPubKey: hex.EncodeToString(txOut.ScriptPubKey),
+			Index:        txOut.Index,
+		}
+		if txOut.Address != nil {
+			txRes.Outputs[i].Address = *txOut.Address
+		}
+	}
+	sort.Slice(txRes.Outputs, func(i, j int) bool {
+		return txRes.Outputs[i].Index < txRes.Outputs[j].Index
+	})
+
+	for i, txIn := range tx.MempoolTransactionInputs {
+		txRes.Inputs[i] = &TransactionInputResponse{
+			PreviousTransactionID:          txIn.PreviousTransactionID,
+			PreviousTransactionOutputIndex: txIn.PreviousTransactionOutputIndex,
+			SignatureScript:                hex.EncodeToString(txIn.SignatureScript),
+			Sequence:                       serializer.BytesToUint64(txIn.Sequence),
+			Index:                          txIn.Index,
+		}
+		if txIn.Address != nil {
+			txRes.Inputs[i].Address = *txIn.Address
+		}
+	}
+	sort.Slice(txRes.Inputs, func(i, j int) bool {
+		return txRes.Inputs[i].Index < txRes.Inputs[j].Index
+	})
+
+	return txRes
+}
+
 // ConvertBlockModelToBlockResponse converts a block database object into a BlockResponse
 func ConvertBlockModelToBlockResponse(block *dbmodels.Block, selectedTipBlueScore uint64) *BlockResponse {
 	blockRes := &BlockResponse{
@@ -138,8 +191,27 @@ func ConvertTransactionOutputModelToTransactionOutputResponse(transactionOutput
 		AcceptingBlockHash:      acceptingBlockHash,
 		AcceptingBlockBlueScore: acceptingBlockBlueScore,
 		Index:                   transactionOutput.Index,
+		IsSpent:                 isSpent,
 		IsCoinbase:              &isCoinbase,
 		Confirmations:           &utxoConfirmations,
 		IsSpendable:             &isSpendable,
 	}, nil
 }
+
+// ConvertSyncStateModelToSyncStatusResponse converts a sync state
+// database object into a SyncStatusResponse
+func ConvertSyncStateModelToSyncStatusResponse(syncState *dbmodels.SyncState, blockCount uint64,
+	selectedTipBlueScore uint64) *SyncStatusResponse {
+
+	return &SyncStatusResponse{
+		Phase:                 syncState.Phase,
+		LastBlockHash:         syncState.LastBlockHash,
+		LastChainBlockHash:    syncState.LastChainBlockHash,
+		SyncedBlockCount:      syncState.SyncedBlockCount,
+		SyncedChainBlockCount: syncState.SyncedChainBlockCount,
+		BlockCount:            blockCount,
+		SelectedTipBlueScore:  selectedTipBlueScore,
+		StartTime:             uint64(syncState.StartedAt.Unix()),
+		UpdateTime:            uint64(syncState.UpdatedAt.Unix()),
+	}
+}
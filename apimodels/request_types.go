@@ -4,3 +4,33 @@ package apimodels
 type RawTransaction struct {
 	RawTransaction string `json:"rawTransaction"`
 }
+
+// TransactionIDsRequest is a json representation of a request
+// to look up several transactions by their IDs
+type TransactionIDsRequest struct {
+	TransactionIDs []string `json:"transactionIds"`
+}
+
+// BlockHashesRequest is a json representation of a request
+// to look up several blocks by their hashes
+type BlockHashesRequest struct {
+	BlockHashes []string `json:"blockHashes"`
+}
+
+// AddressesRequest is a json representation of a request
+// to look up data of several addresses
+type AddressesRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+// OutpointsRequest is a json representation of a request
+// to look up several transaction outputs by their outpoints
+type OutpointsRequest struct {
+	Outpoints []*Outpoint `json:"outpoints"`
+}
+
+// Outpoint is a json representation of a transaction outpoint
+type Outpoint struct {
+	TransactionID string `json:"transactionId"`
+	Index         uint32 `json:"index"`
+}
@@ -17,8 +17,45 @@ type TransactionResponse struct {
 	Version                 int32                        `json:"version"`
 	Raw                     string                       `json:"raw"`
 	Confirmations           *uint64                      `json:"confirmations,omitempty"`
+	Status                  string                       `json:"status"`
+	NetValueChange          *int64                       `json:"netValueChange,omitempty"`
 }
 
+// UniqueAddresses returns the addresses that the transaction involves, whether
+// it sends to them or spends from them, without duplicates
+func (tx *TransactionResponse) UniqueAddresses() []string {
+	addressesMap := make(map[string]struct{})
+	addresses := []string{}
+	for _, output := range tx.Outputs {
+		if _, exists := addressesMap[output.Address]; !exists {
+			addresses = append(addresses, output.Address)
+			addressesMap[output.Address] = struct{}{}
+		}
+	}
+	for _, input := range tx.Inputs {
+		if _, exists := addressesMap[input.Address]; !exists {
+			addresses = append(addresses, input.Address)
+			addressesMap[input.Address] = struct{}{}
+		}
+	}
+	return addresses
+}
+
+// Transaction statuses, as reported in TransactionResponse.Status
+const (
+	// TransactionStatusPending is the status of transactions
+	// that are in the mempool and not in any block yet
+	TransactionStatusPending = "pending"
+
+	// TransactionStatusIncluded is the status of transactions that
+	// are included in blocks but are not accepted yet
+	TransactionStatusIncluded = "included"
+
+	// TransactionStatusAccepted is the status of transactions that
+	// are accepted by a block in the selected parent chain
+	TransactionStatusAccepted = "accepted"
+)
+
 // TransactionOutputResponse is a json representation of a transaction output
 type TransactionOutputResponse struct {
 	TransactionID           string  `json:"transactionId,omitempty"`
@@ -32,6 +69,17 @@ type TransactionOutputResponse struct {
 	IsCoinbase              *bool   `json:"isCoinbase,omitempty"`
 	IsSpendable             *bool   `json:"isSpendable,omitempty"`
 	Confirmations           *uint64 `json:"confirmations,omitempty"`
+
+	SpentBy *TransactionOutputSpenderResponse `json:"spentBy,omitempty"`
+}
+
+// TransactionOutputSpenderResponse is a json representation of the
+// transaction input that spends a transaction output
+type TransactionOutputSpenderResponse struct {
+	TransactionID           string  `json:"transactionId"`
+	InputIndex              uint32  `json:"inputIndex"`
+	AcceptingBlockHash      *string `json:"acceptingBlockHash"`
+	AcceptingBlockBlueScore *uint64 `json:"acceptingBlockBlueScore,omitempty"`
 }
 
 // TransactionInputResponse is a json representation of a transaction input
@@ -48,6 +96,14 @@ type TransactionInputResponse struct {
 // TransactionsResponse is a json representation of a transactions response
 type TransactionsResponse struct {
 	Transactions []*TransactionResponse `json:"transactions"`
+	NextCursor   string                 `json:"nextCursor,omitempty"`
+}
+
+// MempoolResponse is a json representation of a page of the pending
+// transactions in the mempool
+type MempoolResponse struct {
+	Transactions []*TransactionResponse `json:"transactions"`
+	Size         uint64                 `json:"size"`
 }
 
 // BlockResponse is a json representation of a block
@@ -70,9 +126,231 @@ type BlockResponse struct {
 	Confirmations           *uint64  `json:"confirmations,omitempty"`
 }
 
-// FeeEstimateResponse is a json representation of a fee estimate
+// BlockNeighborhoodResponse is a json representation of the blocks
+// surrounding a block in the DAG, up to a certain distance from it.
+// Levels[i] holds the blocks at distance i+1 from the block.
+type BlockNeighborhoodResponse struct {
+	BlockHash string             `json:"blockHash"`
+	Direction string             `json:"direction"`
+	Levels    [][]*BlockResponse `json:"levels"`
+	Truncated bool               `json:"truncated"`
+}
+
+// ChainChangesResponse is a json representation of the changes in the selected
+// parent chain since a certain block. HasMore is set if not all the added chain
+// blocks fit in the response.
+type ChainChangesResponse struct {
+	SelectedParentChainNotification
+	HasMore bool `json:"hasMore"`
+}
+
+// ChainChangeResponse is a json representation of a change in the selected parent
+// chain. Depth is the number of chain blocks that were removed in the change.
+type ChainChangeResponse struct {
+	ID                       uint64   `json:"id"`
+	ChangedAt                uint64   `json:"changedAt"`
+	Depth                    uint64   `json:"depth"`
+	RemovedBlockHashes       []string `json:"removedBlockHashes"`
+	AddedBlockHashes         []string `json:"addedBlockHashes"`
+	UnacceptedTransactionIDs []string `json:"unacceptedTransactionIds"`
+	AcceptedTransactionIDs   []string `json:"acceptedTransactionIds"`
+}
+
+// TransactionAcceptanceChangeResponse is a json representation of a transaction
+// being accepted or unaccepted by a chain block in a selected parent chain change
+type TransactionAcceptanceChangeResponse struct {
+	ChainChangeID   uint64 `json:"chainChangeId"`
+	ChangedAt       uint64 `json:"changedAt"`
+	TransactionHash string `json:"transactionHash"`
+	BlockHash       string `json:"blockHash"`
+	BlockBlueScore  uint64 `json:"blockBlueScore"`
+	IsAccepted      bool   `json:"isAccepted"`
+}
+
+// NetworkStatsResponse is a json representation of the network statistics.
+// All the values except CirculatingSupply are measured over the last
+// WindowSeconds seconds.
+type NetworkStatsResponse struct {
+	WindowSeconds         uint64  `json:"windowSeconds"`
+	BlocksPerSecond       float64 `json:"blocksPerSecond"`
+	TransactionsPerSecond float64 `json:"transactionsPerSecond"`
+	AverageMass           float64 `json:"averageMass"`
+	Difficulty            float64 `json:"difficulty"`
+	Hashrate              float64 `json:"hashrate"`
+	ActiveAddresses       uint64  `json:"activeAddresses"`
+	CirculatingSupply     uint64  `json:"circulatingSupply"`
+}
+
+// StatsTimeseriesResponse is a json representation of the values
+// of a network statistics metric over time
+type StatsTimeseriesResponse struct {
+	Metric   string            `json:"metric"`
+	Interval string            `json:"interval"`
+	Points   []*StatsDataPoint `json:"points"`
+}
+
+// StatsDataPoint is a json representation of the value of a network
+// statistics metric in the interval that starts at Timestamp
+type StatsDataPoint struct {
+	Timestamp uint64  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// AddressBalanceResponse is a json representation of an address balance
+type AddressBalanceResponse struct {
+	Total               uint64 `json:"total"`
+	Spendable           uint64 `json:"spendable"`
+	PendingConfirmation uint64 `json:"pendingConfirmation"`
+	ImmatureCoinbase    uint64 `json:"immatureCoinbase"`
+}
+
+// TopAddressResponse is a json representation of an address in the address leaderboard
+type TopAddressResponse struct {
+	Address            string `json:"address"`
+	Balance            uint64 `json:"balance"`
+	TransactionCount   uint64 `json:"txCount"`
+	FirstSeenBlueScore uint64 `json:"firstSeenBlueScore"`
+	LastSeenBlueScore  uint64 `json:"lastSeenBlueScore"`
+}
+
+// SearchResponse is a json representation of the results of a search query
+type SearchResponse struct {
+	Query   string                  `json:"query"`
+	Results []*SearchResultResponse `json:"results"`
+}
+
+// SearchResultResponse is a json representation of a single search result.
+// Type is one of block, transaction or address, and MatchedBy is the field
+// that matched the query: blockHash, transactionHash, transactionId or address.
+// Only the field corresponding to Type is set.
+type SearchResultResponse struct {
+	Type        string               `json:"type"`
+	MatchedBy   string               `json:"matchedBy"`
+	Block       *BlockResponse       `json:"block,omitempty"`
+	Transaction *TransactionResponse `json:"transaction,omitempty"`
+	Address     string               `json:"address,omitempty"`
+}
+
+// BlocksResponse is a json representation of a blocks response
+type BlocksResponse struct {
+	Blocks     []*BlockResponse `json:"blocks"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
+
+// TransactionLookupResponse is a json representation of
+// a single transaction lookup in a batch lookup response
+type TransactionLookupResponse struct {
+	TransactionID string               `json:"transactionId"`
+	Found         bool                 `json:"found"`
+	Error         string               `json:"error,omitempty"`
+	Transaction   *TransactionResponse `json:"transaction,omitempty"`
+}
+
+// BlockLookupResponse is a json representation of
+// a single block lookup in a batch lookup response
+type BlockLookupResponse struct {
+	BlockHash string         `json:"blockHash"`
+	Found     bool           `json:"found"`
+	Error     string         `json:"error,omitempty"`
+	Block     *BlockResponse `json:"block,omitempty"`
+}
+
+// UTXOsLookupResponse is a json representation of
+// a single address UTXOs lookup in a batch lookup response
+type UTXOsLookupResponse struct {
+	Address string                       `json:"address"`
+	Found   bool                         `json:"found"`
+	Error   string                       `json:"error,omitempty"`
+	UTXOs   []*TransactionOutputResponse `json:"utxos,omitempty"`
+}
+
+// TransactionOutputLookupResponse is a json representation of
+// a single transaction output lookup in a batch lookup response
+type TransactionOutputLookupResponse struct {
+	TransactionID string                     `json:"transactionId"`
+	Index         uint32                     `json:"index"`
+	Found         bool                       `json:"found"`
+	Error         string                     `json:"error,omitempty"`
+	Output        *TransactionOutputResponse `json:"output,omitempty"`
+}
+
+// SyncStatusResponse is a json representation of the sync status of kasparovsyncd
+type SyncStatusResponse struct {
+	Phase                 string  `json:"phase"`
+	LastBlockHash         *string `json:"lastBlockHash"`
+	LastChainBlockHash    *string `json:"lastChainBlockHash"`
+	SyncedBlockCount      uint64  `json:"syncedBlockCount"`
+	SyncedChainBlockCount uint64  `json:"syncedChainBlockCount"`
+	BlockCount            uint64  `json:"blockCount"`
+	SelectedTipBlueScore  uint64  `json:"selectedTipBlueScore"`
+	StartTime             uint64  `json:"startTime"`
+	UpdateTime            uint64  `json:"updateTime"`
+}
+
+// FeeEstimateResponse is a json representation of a fee estimate.
+// Fee rates are in sompi per gram of mass.
 type FeeEstimateResponse struct {
-	HighPriority   float64 `json:"highPriority"`
-	NormalPriority float64 `json:"normalPriority"`
-	LowPriority    float64 `json:"lowPriority"`
+	HighPriority   float64                `json:"highPriority"`
+	NormalPriority float64                `json:"normalPriority"`
+	LowPriority    float64                `json:"lowPriority"`
+	Confidence     *FeeEstimateConfidence `json:"confidence"`
+}
+
+// FeeEstimateConfidence is a json representation of the data
+// a fee estimate is based on. MempoolSize is null if the
+// node couldn't be reached.
+type FeeEstimateConfidence struct {
+	Level         string  `json:"level"`
+	IsFallback    bool    `json:"isFallback"`
+	SampleCount   uint64  `json:"sampleCount"`
+	FromBlueScore uint64  `json:"fromBlueScore"`
+	ToBlueScore   uint64  `json:"toBlueScore"`
+	MempoolSize   *uint64 `json:"mempoolSize"`
+	IsCongested   bool    `json:"isCongested"`
+}
+
+// PostTransactionResponse is a json representation of
+// the response to a submitted transaction
+type PostTransactionResponse struct {
+	TransactionID   string `json:"transactionId"`
+	TransactionHash string `json:"transactionHash"`
+}
+
+// Lifecycle states of submitted transactions, as reported in
+// SubmittedTransactionResponse.Status
+const (
+	SubmittedTransactionStatusSubmitted = "submitted"
+	SubmittedTransactionStatusInMempool = "in-mempool"
+	SubmittedTransactionStatusIncluded  = "included"
+	SubmittedTransactionStatusAccepted  = "accepted"
+	SubmittedTransactionStatusRejected  = "rejected"
+)
+
+// SubmittedTransactionResponse is a json representation of the
+// lifecycle state of a transaction submitted through Kasparov
+type SubmittedTransactionResponse struct {
+	TransactionID        string   `json:"transactionId"`
+	TransactionHash      string   `json:"transactionHash"`
+	Status               string   `json:"status"`
+	IncludingBlockHashes []string `json:"includingBlockHashes,omitempty"`
+	AcceptingBlockHash   *string  `json:"acceptingBlockHash,omitempty"`
+	RejectionError       *string  `json:"rejectionError,omitempty"`
+	BroadcastCount       uint64   `json:"broadcastCount"`
+	SubmittedAt          uint64   `json:"submittedAt"`
+	LastBroadcastAt      uint64   `json:"lastBroadcastAt"`
+}
+
+// HealthResponse is a json representation of the health of the server
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse is a json representation of the readiness of the
+// server, including how far the database trails the node
+type ReadinessResponse struct {
+	Status                   string `json:"status"`
+	SelectedTipBlueScore     uint64 `json:"selectedTipBlueScore"`
+	NodeSelectedTipBlueScore uint64 `json:"nodeSelectedTipBlueScore"`
+	BlueScoreLag             uint64 `json:"blueScoreLag"`
+	TimeLagSeconds           uint64 `json:"timeLagSeconds"`
 }
@@ -0,0 +1,60 @@
+package apimodels
+
+// Webhook events
+const (
+	// WebhookEventActivity is sent when a transaction that pays to or
+	// spends from a watched address is included in a block
+	WebhookEventActivity = "activity"
+
+	// WebhookEventConfirmed is sent when an accepted transaction that pays to or
+	// spends from a watched address reaches the confirmation threshold
+	WebhookEventConfirmed = "confirmed"
+
+	// WebhookEventUnaccepted is sent when a transaction that pays to or
+	// spends from a watched address is no longer accepted by the selected
+	// parent chain
+	WebhookEventUnaccepted = "unaccepted"
+)
+
+// WebhookSubscriptionRequest is a json representation of
+// a request to create a webhook subscription
+type WebhookSubscriptionRequest struct {
+	URL                   string   `json:"url"`
+	Addresses             []string `json:"addresses"`
+	ConfirmationThreshold uint64   `json:"confirmationThreshold"`
+}
+
+// WebhookSubscriptionResponse is a json representation of a webhook subscription.
+// Secret is only returned when the subscription is created.
+type WebhookSubscriptionResponse struct {
+	ID                    string   `json:"id"`
+	URL                   string   `json:"url"`
+	Addresses             []string `json:"addresses"`
+	ConfirmationThreshold uint64   `json:"confirmationThreshold"`
+	Secret                string   `json:"secret,omitempty"`
+	CreatedAt             uint64   `json:"createdAt"`
+}
+
+// WebhookDeliveryResponse is a json representation of
+// a webhook delivery and the state of its attempts
+type WebhookDeliveryResponse struct {
+	ID             uint64  `json:"id"`
+	Event          string  `json:"event"`
+	Address        string  `json:"address"`
+	TransactionID  string  `json:"transactionId"`
+	CreatedAt      uint64  `json:"createdAt"`
+	Attempts       uint64  `json:"attempts"`
+	NextAttemptAt  *uint64 `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *uint64 `json:"deliveredAt,omitempty"`
+	LastStatusCode *int    `json:"lastStatusCode,omitempty"`
+	LastError      *string `json:"lastError,omitempty"`
+}
+
+// WebhookPayload is a json representation of the body
+// that is POSTed to the URL of a webhook subscription
+type WebhookPayload struct {
+	SubscriptionID string               `json:"subscriptionId"`
+	Event          string               `json:"event"`
+	Address        string               `json:"address"`
+	Transaction    *TransactionResponse `json:"transaction"`
+}
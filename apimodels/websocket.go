@@ -0,0 +1,26 @@
+package apimodels
+
+// WebSocket request actions
+const (
+	WebSocketActionSubscribe   = "subscribe"
+	WebSocketActionUnsubscribe = "unsubscribe"
+)
+
+// WebSocketRequest is a json representation of a message
+// sent by a client over the /ws WebSocket.
+type WebSocketRequest struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// WebSocketMessage is a json representation of a message sent to
+// a client over the /ws WebSocket. Notifications have a Topic and Data,
+// which is the same as the data of the MQTT notification of that topic.
+// Replies to requests have an Action and a Topic, and an Error if the
+// request failed.
+type WebSocketMessage struct {
+	Topic  string      `json:"topic,omitempty"`
+	Action string      `json:"action,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
@@ -0,0 +1,185 @@
+package dagevents
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/pkg/errors"
+)
+
+// Channel is the Postgres notification channel on which
+// DAG events are sent from kasparovsyncd to kasparovd.
+const Channel = "kasparov_dag_events"
+
+// Event kinds
+const (
+	KindBlocksAdded            = "blocks-added"
+	KindChainChanged           = "chain-changed"
+	KindSelectedTipChanged     = "selected-tip-changed"
+	KindTransactionsUnaccepted = "transactions-unaccepted"
+)
+
+// maxPayloadSize is the maximum size of a notification payload. Postgres
+// requires payloads to be shorter than 8000 bytes, so events only reference
+// database objects, and large events are split into several smaller ones.
+const maxPayloadSize = 7999
+
+// Event is a notification about a change in the DAG data stored in
+// the database. Events only reference the changed objects - listeners
+// are expected to read the objects themselves from the database.
+type Event struct {
+	Kind                    string   `json:"kind"`
+	BlockHashes             []string `json:"blockHashes,omitempty"`
+	RemovedChainBlockHashes []string `json:"removedChainBlockHashes,omitempty"`
+	AddedChainBlockHashes   []string `json:"addedChainBlockHashes,omitempty"`
+	TransactionIDs          []uint64 `json:"transactionIds,omitempty"`
+}
+
+// NotifyBlocksAdded sends an event about blocks that were added to the database.
+// Events sent within a database transaction are only delivered once it commits.
+func NotifyBlocksAdded(ctx database.Context, blockHashes []string) error {
+	splitter := newEventSplitter(KindBlocksAdded, func(event *Event) error {
+		return notify(ctx, event)
+	})
+	for _, blockHash := range blockHashes {
+		err := splitter.addHash(blockHash, func(event *Event) *[]string { return &event.BlockHashes })
+		if err != nil {
+			return err
+		}
+	}
+	return splitter.flush()
+}
+
+// NotifyChainChanged sends an event about a change in the selected parent chain.
+// If the event is split, all the removed chain blocks are sent before the added ones.
+// Events sent within a database transaction are only delivered once it commits.
+func NotifyChainChanged(ctx database.Context, removedChainBlockHashes []string, addedChainBlockHashes []string) error {
+	splitter := newEventSplitter(KindChainChanged, func(event *Event) error {
+		return notify(ctx, event)
+	})
+	for _, blockHash := range removedChainBlockHashes {
+		err := splitter.addHash(blockHash, func(event *Event) *[]string { return &event.RemovedChainBlockHashes })
+		if err != nil {
+			return err
+		}
+	}
+	for _, blockHash := range addedChainBlockHashes {
+		err := splitter.addHash(blockHash, func(event *Event) *[]string { return &event.AddedChainBlockHashes })
+		if err != nil {
+			return err
+		}
+	}
+	return splitter.flush()
+}
+
+// NotifySelectedTipChanged sends an event about a new selected tip.
+func NotifySelectedTipChanged(ctx database.Context, selectedTipHash string) error {
+	return notify(ctx, &Event{
+		Kind:        KindSelectedTipChanged,
+		BlockHashes: []string{selectedTipHash},
+	})
+}
+
+// NotifyTransactionsUnaccepted sends an event about transactions that are no longer
+// accepted by the selected parent chain. The transactions are referenced by their
+// database IDs, since the same transaction may be included in several blocks.
+// Events sent within a database transaction are only delivered once it commits.
+func NotifyTransactionsUnaccepted(ctx database.Context, transactionIDs []uint64) error {
+	splitter := newEventSplitter(KindTransactionsUnaccepted, func(event *Event) error {
+		return notify(ctx, event)
+	})
+	for _, transactionID := range transactionIDs {
+		err := splitter.add(len(strconv.FormatUint(transactionID, 10)), func(event *Event) {
+			event.TransactionIDs = append(event.TransactionIDs, transactionID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return splitter.flush()
+}
+
+// eventSplitter sends the objects referenced by an event in as
+// many events of the same kind as needed for the payload of
+// each of them to fit in maxPayloadSize
+type eventSplitter struct {
+	kind      string
+	send      func(event *Event) error
+	event     *Event
+	size      int
+	emptySize int
+}
+
+func newEventSplitter(kind string, send func(event *Event) error) *eventSplitter {
+	// The size of an event whose lists all hold a single empty item
+	// is an upper bound on the size of the event without its items
+	emptyEvent, _ := json.Marshal(&Event{
+		Kind:                    kind,
+		BlockHashes:             []string{""},
+		RemovedChainBlockHashes: []string{""},
+		AddedChainBlockHashes:   []string{""},
+		TransactionIDs:          []uint64{0},
+	})
+	return &eventSplitter{
+		kind:      kind,
+		send:      send,
+		event:     &Event{Kind: kind},
+		size:      len(emptyEvent),
+		emptySize: len(emptyEvent),
+	}
+}
+
+// addHash adds a hash to the list of the current event that's returned by `list`
+func (s *eventSplitter) addHash(hash string, list func(event *Event) *[]string) error {
+	// Hashes are hex-encoded, so they're encoded in JSON without escaping
+	encodedSize := len(hash) + len(`""`)
+	return s.add(encodedSize, func(event *Event) {
+		*list(event) = append(*list(event), hash)
+	})
+}
+
+// add adds an object whose JSON encoding is `encodedSize` bytes long to
+// the current event with `addToEvent`. The current event is sent first
+// if the object doesn't fit in it.
+func (s *eventSplitter) add(encodedSize int, addToEvent func(event *Event)) error {
+	// Every item but the first of a list is preceded by a comma
+	itemSize := encodedSize + len(",")
+	if s.size+itemSize > maxPayloadSize && s.size > s.emptySize {
+		err := s.flush()
+		if err != nil {
+			return err
+		}
+	}
+	addToEvent(s.event)
+	s.size += itemSize
+	return nil
+}
+
+// flush sends the current event, unless it's empty
+func (s *eventSplitter) flush() error {
+	if s.size == s.emptySize {
+		return nil
+	}
+	err := s.send(s.event)
+	if err != nil {
+		return err
+	}
+	s.event = &Event{Kind: s.kind}
+	s.size = s.emptySize
+	return nil
+}
+
+func notify(ctx database.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("SELECT pg_notify(?, ?)", Channel, string(payload))
+	return err
+}
@@ -0,0 +1,74 @@
+package dagevents
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventSplitter(t *testing.T) {
+	var sentEvents []*Event
+	splitter := newEventSplitter(KindChainChanged, func(event *Event) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("json.Marshal: %s", err)
+		}
+		if len(payload) > maxPayloadSize {
+			t.Errorf("Payload of %d bytes is longer than %d bytes", len(payload), maxPayloadSize)
+		}
+		sentEvents = append(sentEvents, event)
+		return nil
+	})
+
+	hash := strings.Repeat("ab", 32)
+	const hashCount = 300
+	for i := 0; i < hashCount; i++ {
+		list := func(event *Event) *[]string { return &event.RemovedChainBlockHashes }
+		if i%2 == 1 {
+			list = func(event *Event) *[]string { return &event.AddedChainBlockHashes }
+		}
+		err := splitter.addHash(hash, list)
+		if err != nil {
+			t.Fatalf("addHash: %s", err)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		transactionID := uint64(math.MaxUint64)
+		err := splitter.add(len("18446744073709551615"), func(event *Event) {
+			event.TransactionIDs = append(event.TransactionIDs, transactionID)
+		})
+		if err != nil {
+			t.Fatalf("add: %s", err)
+		}
+	}
+	err := splitter.flush()
+	if err != nil {
+		t.Fatalf("flush: %s", err)
+	}
+	err = splitter.flush()
+	if err != nil {
+		t.Fatalf("flush: %s", err)
+	}
+
+	if len(sentEvents) < 2 {
+		t.Fatalf("Expected the event to be split, but got %d events", len(sentEvents))
+	}
+	var hashes []string
+	var transactionIDs []uint64
+	for _, event := range sentEvents {
+		if event.Kind != KindChainChanged {
+			t.Errorf("Expected kind %s but got %s", KindChainChanged, event.Kind)
+		}
+		hashes = append(hashes, event.RemovedChainBlockHashes...)
+		hashes = append(hashes, event.AddedChainBlockHashes...)
+		transactionIDs = append(transactionIDs, event.TransactionIDs...)
+	}
+	if !reflect.DeepEqual(hashes, strings.Split(strings.Repeat(hash+",", hashCount-1)+hash, ",")) {
+		t.Errorf("Expected %d hashes but got %d", hashCount, len(hashes))
+	}
+	if len(transactionIDs) != 1000 {
+		t.Errorf("Expected 1000 transaction IDs but got %d", len(transactionIDs))
+	}
+}
@@ -0,0 +1,41 @@
+package dagevents
+
+import (
+	"encoding/json"
+
+	"github.com/kaspanet/kasparov/database"
+)
+
+// eventChannelSize is the number of received events that
+// may wait to be handled before the listener blocks.
+const eventChannelSize = 1000
+
+// Listen starts listening to DAG events. It returns a channel on which the events
+// are delivered, and a function that stops listening and closes that channel.
+func Listen() (events <-chan *Event, stop func() error, err error) {
+	listener, err := database.Listen(Channel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eventChan := make(chan *Event, eventChannelSize)
+	spawn("dagevents-Listen", func() {
+		defer close(eventChan)
+		for notification := range listener.Channel() {
+			// The listener may deliver internal notifications, such as
+			// health check pings, on other channels.
+			if notification.Channel != Channel {
+				continue
+			}
+			event := &Event{}
+			err := json.Unmarshal([]byte(notification.Payload), event)
+			if err != nil {
+				log.Warnf("Received a malformed DAG event %s: %s", notification.Payload, err)
+				continue
+			}
+			eventChan <- event
+		}
+	})
+
+	return eventChan, listener.Close, nil
+}
@@ -0,0 +1,11 @@
+package dagevents
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kasparov/logger"
+)
+
+var (
+	log   = logger.Logger("DEVT")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
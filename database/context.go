@@ -15,6 +15,8 @@ type DB interface {
 	Update(model interface{}) error
 	Delete(model interface{}) error
 	QueryOne(model, query interface{}, params ...interface{}) (orm.Result, error)
+	Query(model, query interface{}, params ...interface{}) (orm.Result, error)
+	Exec(query interface{}, params ...interface{}) (orm.Result, error)
 }
 
 // Context is an interface type representing the context in which queries run, currently relating to the
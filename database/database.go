@@ -6,6 +6,7 @@ import (
 	"github.com/go-pg/pg/v9"
 	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/kaspanet/kasparov/config"
+	"github.com/kaspanet/kasparov/metrics"
 	"github.com/pkg/errors"
 	"os"
 	"strings"
@@ -31,6 +32,17 @@ func DBInstance() (*pg.DB, error) {
 	return db, nil
 }
 
+// Listen starts listening to notifications sent on the given channels
+// with Postgres NOTIFY. The caller is responsible for closing the returned
+// listener.
+func Listen(channels ...string) (*pg.Listener, error) {
+	db, err := DBInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.Listen(channels...), nil
+}
+
 // Connect connects to the database mentioned in the config variable.
 func Connect(cfg *config.KasparovFlags) error {
 	migrator, driver, err := openMigrator(cfg)
@@ -52,6 +64,7 @@ func Connect(cfg *config.KasparovFlags) error {
 	}
 
 	db = pg.Connect(connectionOptions)
+	db.AddQueryHook(metrics.DBQueryHook{})
 
 	return validateTimeZone(db)
 }
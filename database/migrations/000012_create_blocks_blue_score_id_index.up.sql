@@ -0,0 +1 @@
+CREATE INDEX idx_blocks_blue_score_id ON blocks (blue_score, id);
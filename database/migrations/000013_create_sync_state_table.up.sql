@@ -0,0 +1,12 @@
+CREATE TABLE sync_state
+(
+    id                       SMALLINT    NOT NULL,
+    phase                    VARCHAR(16) NOT NULL,
+    last_block_hash          CHAR(64)    NULL,
+    last_chain_block_hash    CHAR(64)    NULL,
+    synced_block_count       BIGINT      NOT NULL,
+    synced_chain_block_count BIGINT      NOT NULL,
+    started_at               TIMESTAMP   NOT NULL,
+    updated_at               TIMESTAMP   NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE mempool_transaction_inputs;
+DROP TABLE mempool_transaction_outputs;
+DROP TABLE mempool_transactions;
@@ -0,0 +1,57 @@
+CREATE TABLE mempool_transactions
+(
+    id               BIGSERIAL,
+    transaction_hash CHAR(64) NOT NULL,
+    transaction_id   CHAR(64) NOT NULL,
+    lock_time        BYTEA NOT NULL,
+    subnetwork_id    CHAR(40) NOT NULL,
+    gas              BIGINT CHECK (gas >= 0) NOT NULL,
+    payload_hash     CHAR(64) NOT NULL,
+    payload          BYTEA NOT NULL,
+    version          INT NOT NULL,
+    raw_transaction  BYTEA NOT NULL,
+    first_seen_at    TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_mempool_transactions_transaction_id UNIQUE (transaction_id)
+);
+
+CREATE INDEX idx_mempool_transactions_first_seen_at ON mempool_transactions (first_seen_at);
+
+CREATE TABLE mempool_transaction_outputs
+(
+    id                     BIGSERIAL,
+    mempool_transaction_id BIGINT NOT NULL,
+    index                  BIGINT CHECK (index >= 0 AND index <= 4294967295) NOT NULL, -- index should be in range of uint32,
+    value                  BIGINT CHECK (value >= 0) NOT NULL,
+    script_pub_key         BYTEA NOT NULL,
+    address                VARCHAR(64) NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_mempool_transaction_outputs_mempool_transaction_id
+        FOREIGN KEY (mempool_transaction_id)
+            REFERENCES mempool_transactions (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_mempool_transaction_outputs_mempool_transaction_id ON mempool_transaction_outputs (mempool_transaction_id);
+CREATE INDEX idx_mempool_transaction_outputs_address ON mempool_transaction_outputs (address);
+
+CREATE TABLE mempool_transaction_inputs
+(
+    id                                BIGSERIAL,
+    mempool_transaction_id            BIGINT NOT NULL,
+    index                             BIGINT CHECK (index >= 0 AND index <= 4294967295) NOT NULL, -- index should be in range of uint32,
+    previous_transaction_id           CHAR(64) NOT NULL,
+    previous_transaction_output_index BIGINT CHECK (previous_transaction_output_index >= 0 AND previous_transaction_output_index <= 4294967295) NOT NULL,
+    signature_script                  BYTEA NOT NULL,
+    sequence                          BYTEA NOT NULL,
+    address                           VARCHAR(64) NULL,
+    value                             BIGINT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_mempool_transaction_inputs_mempool_transaction_id
+        FOREIGN KEY (mempool_transaction_id)
+            REFERENCES mempool_transactions (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_mempool_transaction_inputs_mempool_transaction_id ON mempool_transaction_inputs (mempool_transaction_id);
+CREATE INDEX idx_mempool_transaction_inputs_address ON mempool_transaction_inputs (address);
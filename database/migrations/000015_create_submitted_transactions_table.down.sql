@@ -0,0 +1 @@
+DROP TABLE submitted_transactions;
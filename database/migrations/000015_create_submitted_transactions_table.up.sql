@@ -0,0 +1,15 @@
+CREATE TABLE submitted_transactions
+(
+    id                BIGSERIAL,
+    transaction_id    CHAR(64) NOT NULL,
+    transaction_hash  CHAR(64) NOT NULL,
+    raw_transaction   BYTEA NOT NULL,
+    rejection_error   TEXT NULL,
+    broadcast_count   INT NOT NULL,
+    submitted_at      TIMESTAMP NOT NULL,
+    last_broadcast_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_submitted_transactions_transaction_id UNIQUE (transaction_id)
+);
+
+CREATE INDEX idx_submitted_transactions_last_broadcast_at ON submitted_transactions (last_broadcast_at);
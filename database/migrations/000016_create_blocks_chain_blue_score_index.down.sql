@@ -0,0 +1 @@
+DROP INDEX idx_blocks_chain_blue_score;
@@ -0,0 +1 @@
+CREATE INDEX idx_blocks_chain_blue_score ON blocks (blue_score) WHERE is_chain_block;
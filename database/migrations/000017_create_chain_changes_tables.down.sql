@@ -0,0 +1,3 @@
+DROP TABLE chain_change_transactions;
+DROP TABLE chain_change_blocks;
+DROP TABLE chain_changes;
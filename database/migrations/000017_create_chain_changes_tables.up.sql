@@ -0,0 +1,46 @@
+CREATE TABLE chain_changes
+(
+    id         BIGSERIAL,
+    changed_at TIMESTAMP NOT NULL,
+    depth      BIGINT NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_chain_changes_depth ON chain_changes (depth);
+
+CREATE TABLE chain_change_blocks
+(
+    chain_change_id BIGINT NOT NULL,
+    block_id        BIGINT NOT NULL,
+    is_added        BOOLEAN NOT NULL,
+    index           INT NOT NULL,
+    PRIMARY KEY (chain_change_id, is_added, index),
+    CONSTRAINT fk_chain_change_blocks_chain_change_id
+        FOREIGN KEY (chain_change_id)
+            REFERENCES chain_changes (id),
+    CONSTRAINT fk_chain_change_blocks_block_id
+        FOREIGN KEY (block_id)
+            REFERENCES blocks (id)
+);
+
+CREATE INDEX idx_chain_change_blocks_block_id ON chain_change_blocks (block_id);
+
+CREATE TABLE chain_change_transactions
+(
+    chain_change_id BIGINT NOT NULL,
+    transaction_id  BIGINT NOT NULL,
+    block_id        BIGINT NOT NULL,
+    PRIMARY KEY (chain_change_id, transaction_id),
+    CONSTRAINT fk_chain_change_transactions_chain_change_id
+        FOREIGN KEY (chain_change_id)
+            REFERENCES chain_changes (id),
+    CONSTRAINT fk_chain_change_transactions_transaction_id
+        FOREIGN KEY (transaction_id)
+            REFERENCES transactions (id),
+    CONSTRAINT fk_chain_change_transactions_block_id
+        FOREIGN KEY (block_id)
+            REFERENCES blocks (id)
+);
+
+CREATE INDEX idx_chain_change_transactions_transaction_id ON chain_change_transactions (transaction_id);
+CREATE INDEX idx_chain_change_transactions_block_id ON chain_change_transactions (block_id);
@@ -0,0 +1,2 @@
+DROP TABLE hourly_active_addresses;
+DROP TABLE hourly_stats;
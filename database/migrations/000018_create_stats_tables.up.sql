@@ -0,0 +1,75 @@
+CREATE TABLE hourly_stats
+(
+    hour              TIMESTAMP(0) NOT NULL,
+    block_count       BIGINT NOT NULL,
+    transaction_count BIGINT NOT NULL,
+    total_mass        BIGINT NOT NULL,
+    total_work        DOUBLE PRECISION NOT NULL,
+    coinbase_value    BIGINT NOT NULL,
+    PRIMARY KEY (hour)
+);
+
+CREATE TABLE hourly_active_addresses
+(
+    hour       TIMESTAMP(0) NOT NULL,
+    address_id BIGINT NOT NULL,
+    PRIMARY KEY (hour, address_id),
+    CONSTRAINT fk_hourly_active_addresses_address_id
+        FOREIGN KEY (address_id)
+            REFERENCES addresses (id)
+);
+
+-- Backfill the rollups from the blocks that were synced before they existed.
+-- Keep in sync with dbaccess.UpdateHourlyStats.
+INSERT INTO hourly_stats (hour, block_count, transaction_count, total_mass, total_work, coinbase_value)
+SELECT block_stats.hour,
+       COUNT(*),
+       0,
+       SUM(block_stats.mass),
+       COALESCE(SUM(block_stats.work), 0),
+       0
+FROM (
+         SELECT date_trunc('hour', blocks.timestamp) AS hour,
+                blocks.mass,
+                2::DOUBLE PRECISION ^ 256 / NULLIF((blocks.bits & 8388607)::DOUBLE PRECISION *
+                                                   256::DOUBLE PRECISION ^ ((blocks.bits >> 24) - 3), 0) AS work
+         FROM blocks
+     ) AS block_stats
+GROUP BY block_stats.hour;
+
+-- Keep in sync with dbaccess.UpdateHourlyAcceptanceStats.
+INSERT INTO hourly_stats (hour, block_count, transaction_count, total_mass, total_work, coinbase_value)
+SELECT accepted_transactions.hour,
+       0,
+       COUNT(*),
+       0,
+       0,
+       SUM(accepted_transactions.coinbase_value)
+FROM (
+         SELECT date_trunc('hour', blocks.timestamp) AS hour,
+                (SELECT COALESCE(SUM(transaction_outputs.value), 0)
+                 FROM transactions_to_blocks
+                          INNER JOIN transaction_outputs
+                                     ON transaction_outputs.transaction_id = transactions_to_blocks.transaction_id
+                 WHERE transactions_to_blocks.transaction_id = transactions.id
+                   AND transactions_to_blocks.index = 0) AS coinbase_value
+         FROM transactions
+                  INNER JOIN blocks ON blocks.id = transactions.accepting_block_id
+     ) AS accepted_transactions
+GROUP BY accepted_transactions.hour
+ON CONFLICT (hour) DO UPDATE SET transaction_count = EXCLUDED.transaction_count,
+                                 coinbase_value    = EXCLUDED.coinbase_value;
+
+INSERT INTO hourly_active_addresses (hour, address_id)
+SELECT date_trunc('hour', blocks.timestamp), transaction_outputs.address_id
+FROM blocks
+         INNER JOIN transactions_to_blocks ON transactions_to_blocks.block_id = blocks.id
+         INNER JOIN transaction_outputs ON transaction_outputs.transaction_id = transactions_to_blocks.transaction_id
+WHERE transaction_outputs.address_id IS NOT NULL
+UNION
+SELECT date_trunc('hour', blocks.timestamp), transaction_outputs.address_id
+FROM blocks
+         INNER JOIN transactions_to_blocks ON transactions_to_blocks.block_id = blocks.id
+         INNER JOIN transaction_inputs ON transaction_inputs.transaction_id = transactions_to_blocks.transaction_id
+         INNER JOIN transaction_outputs ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+WHERE transaction_outputs.address_id IS NOT NULL;
@@ -0,0 +1,54 @@
+CREATE TABLE address_balances
+(
+    address_id            BIGINT NOT NULL,
+    balance               BIGINT NOT NULL,
+    transaction_count     BIGINT NOT NULL,
+    first_seen_blue_score BIGINT NOT NULL,
+    last_seen_blue_score  BIGINT NOT NULL,
+    PRIMARY KEY (address_id),
+    CONSTRAINT fk_address_balances_address_id
+        FOREIGN KEY (address_id)
+            REFERENCES addresses (id)
+);
+
+CREATE INDEX idx_address_balances_balance ON address_balances (balance DESC, address_id);
+CREATE INDEX idx_address_balances_transaction_count ON address_balances (transaction_count DESC, address_id);
+
+-- Backfill the balances of the addresses that were synced before the table existed.
+-- An address is counted as participating in a transaction if the transaction
+-- pays to it or spends one of its outputs, and only accepted transactions count.
+INSERT INTO address_balances (address_id, balance, transaction_count, first_seen_blue_score, last_seen_blue_score)
+SELECT activity.address_id,
+       COALESCE(balances.balance, 0),
+       activity.transaction_count,
+       activity.first_seen_blue_score,
+       activity.last_seen_blue_score
+FROM (
+         SELECT address_transactions.address_id,
+                COUNT(*)             AS transaction_count,
+                MIN(blocks.blue_score) AS first_seen_blue_score,
+                MAX(blocks.blue_score) AS last_seen_blue_score
+         FROM (
+                  SELECT transaction_outputs.address_id, transaction_outputs.transaction_id
+                  FROM transaction_outputs
+                  WHERE transaction_outputs.address_id IS NOT NULL
+                  UNION
+                  SELECT transaction_outputs.address_id, transaction_inputs.transaction_id
+                  FROM transaction_inputs
+                           INNER JOIN transaction_outputs
+                                      ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+                  WHERE transaction_outputs.address_id IS NOT NULL
+              ) AS address_transactions
+                  INNER JOIN transactions ON transactions.id = address_transactions.transaction_id
+                  INNER JOIN blocks ON blocks.id = transactions.accepting_block_id
+         GROUP BY address_transactions.address_id
+     ) AS activity
+         LEFT JOIN (
+    SELECT transaction_outputs.address_id, SUM(transaction_outputs.value) AS balance
+    FROM transaction_outputs
+             INNER JOIN transactions ON transactions.id = transaction_outputs.transaction_id
+    WHERE transaction_outputs.address_id IS NOT NULL
+      AND transaction_outputs.is_spent = FALSE
+      AND transactions.accepting_block_id IS NOT NULL
+    GROUP BY transaction_outputs.address_id
+) AS balances ON balances.address_id = activity.address_id;
@@ -0,0 +1,3 @@
+DROP INDEX idx_blocks_block_hash_prefix;
+DROP INDEX idx_transactions_transaction_hash_prefix;
+DROP INDEX idx_transactions_transaction_id_prefix;
@@ -0,0 +1,5 @@
+-- The existing indexes on these columns use the database's collation, which
+-- prevents them from being used by prefix (LIKE 'abc%') queries.
+CREATE INDEX idx_blocks_block_hash_prefix ON blocks (block_hash bpchar_pattern_ops);
+CREATE INDEX idx_transactions_transaction_hash_prefix ON transactions (transaction_hash bpchar_pattern_ops);
+CREATE INDEX idx_transactions_transaction_id_prefix ON transactions (transaction_id bpchar_pattern_ops);
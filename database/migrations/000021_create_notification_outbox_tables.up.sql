@@ -0,0 +1,22 @@
+CREATE TABLE notification_topic_sequences
+(
+    topic         VARCHAR(255) NOT NULL,
+    last_sequence BIGINT       NOT NULL,
+    PRIMARY KEY (topic)
+);
+
+CREATE TABLE notification_outbox
+(
+    id         BIGSERIAL,
+    topic      VARCHAR(255) NOT NULL,
+    sequence   BIGINT       NOT NULL,
+    payload    BYTEA        NOT NULL,
+    created_at TIMESTAMP    NOT NULL,
+    sent_at    TIMESTAMP    NULL,
+    attempts   INT          NOT NULL,
+    last_error TEXT         NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_notification_outbox_unsent ON notification_outbox (id) WHERE sent_at IS NULL;
+CREATE INDEX idx_notification_outbox_sent_at ON notification_outbox (sent_at);
@@ -0,0 +1,4 @@
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_confirmations;
+DROP TABLE webhook_subscription_addresses;
+DROP TABLE webhook_subscriptions;
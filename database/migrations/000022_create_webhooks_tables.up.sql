@@ -0,0 +1,70 @@
+CREATE TABLE webhook_subscriptions
+(
+    id                     CHAR(32)  NOT NULL,
+    url                    TEXT      NOT NULL,
+    secret                 CHAR(64)  NOT NULL,
+    api_key                TEXT      NOT NULL,
+    confirmation_threshold BIGINT    NOT NULL,
+    created_at             TIMESTAMP NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE webhook_subscription_addresses
+(
+    subscription_id CHAR(32)    NOT NULL,
+    address         VARCHAR(64) NOT NULL,
+    PRIMARY KEY (subscription_id, address),
+    CONSTRAINT fk_webhook_subscription_addresses_subscription_id
+        FOREIGN KEY (subscription_id)
+            REFERENCES webhook_subscriptions (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_subscription_addresses_address ON webhook_subscription_addresses (address);
+
+-- webhook_confirmations marks the transactions for which a confirmation
+-- event was already delivered to a subscription. Marks are removed when
+-- the transaction is unaccepted, so that the event fires again once the
+-- transaction is re-accepted and reaches the threshold.
+CREATE TABLE webhook_confirmations
+(
+    subscription_id CHAR(32) NOT NULL,
+    transaction_id  BIGINT   NOT NULL,
+    PRIMARY KEY (subscription_id, transaction_id),
+    CONSTRAINT fk_webhook_confirmations_subscription_id
+        FOREIGN KEY (subscription_id)
+            REFERENCES webhook_subscriptions (id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_webhook_confirmations_transaction_id
+        FOREIGN KEY (transaction_id)
+            REFERENCES transactions (id)
+);
+
+CREATE INDEX idx_webhook_confirmations_transaction_id ON webhook_confirmations (transaction_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id               BIGSERIAL,
+    subscription_id  CHAR(32)    NOT NULL,
+    event            VARCHAR(32) NOT NULL,
+    address          VARCHAR(64) NOT NULL,
+    transaction_id   BIGINT      NOT NULL,
+    payload          BYTEA       NOT NULL,
+    created_at       TIMESTAMP   NOT NULL,
+    next_attempt_at  TIMESTAMP   NULL,
+    attempts         INT         NOT NULL,
+    delivered_at     TIMESTAMP   NULL,
+    last_status_code INT         NULL,
+    last_error       TEXT        NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhook_deliveries_subscription_id
+        FOREIGN KEY (subscription_id)
+            REFERENCES webhook_subscriptions (id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_webhook_deliveries_transaction_id
+        FOREIGN KEY (transaction_id)
+            REFERENCES transactions (id)
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, id);
+CREATE INDEX idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at) WHERE next_attempt_at IS NOT NULL;
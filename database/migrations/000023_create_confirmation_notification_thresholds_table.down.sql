@@ -0,0 +1 @@
+DROP TABLE confirmation_notification_thresholds;
@@ -0,0 +1,9 @@
+-- confirmation_notification_thresholds holds, for every confirmation threshold
+-- that notifications are published for, the highest blue score of a chain block
+-- whose accepted transactions were already notified as having reached it.
+CREATE TABLE confirmation_notification_thresholds
+(
+    threshold           BIGINT NOT NULL,
+    notified_blue_score BIGINT NOT NULL,
+    PRIMARY KEY (threshold)
+);
@@ -27,3 +27,26 @@ func AddressesByAddressStrings(ctx database.Context, addressStrings []string, pr
 
 	return addresses, nil
 }
+
+// addressIDByAddress returns the database ID of `address`,
+// or nil if it's not in the database
+func addressIDByAddress(ctx database.Context, address string) (*uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uint64
+	err = db.Model(&dbmodels.Address{}).
+		Column("id").
+		Where("address = ?", address).
+		Select(&id)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
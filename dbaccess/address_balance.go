@@ -0,0 +1,122 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+// AddressRanking is the field by which addresses are ranked in the address leaderboard
+type AddressRanking string
+
+// AddressRanking constants
+const (
+	AddressRankingBalance          AddressRanking = "balance"
+	AddressRankingTransactionCount AddressRanking = "txCount"
+)
+
+// StringToAddressRanking converts a ranking string into an AddressRanking type.
+// Returns an error if passed string is not balance or txCount
+func StringToAddressRanking(rankingString string) (AddressRanking, error) {
+	ranking := AddressRanking(rankingString)
+	if ranking != AddressRankingBalance && ranking != AddressRankingTransactionCount {
+		return "", errors.Errorf("'%s' is not a valid ranking", rankingString)
+	}
+	return ranking, nil
+}
+
+func (ranking AddressRanking) column() string {
+	if ranking == AddressRankingTransactionCount {
+		return "address_balance.transaction_count"
+	}
+	return "address_balance.balance"
+}
+
+// AddressBalanceChange is a change in the balance and the transaction count of an address
+type AddressBalanceChange struct {
+	AddressID              uint64
+	BalanceChange          int64
+	TransactionCountChange int64
+}
+
+// UpdateAddressBalances applies the given changes to the balances of their addresses.
+// If seenAtBlueScore is not nil, the changes were caused by transactions that were
+// accepted at that blue score: addresses that don't have a balance yet are created,
+// and the first-seen and last-seen blue scores of the addresses are extended to include
+// it. Otherwise, the changes were caused by transactions that were unaccepted, and the
+// first-seen and last-seen blue scores are left as they are.
+// The changes are applied in a single statement, so there must be at most one
+// change per address.
+func UpdateAddressBalances(ctx database.Context, changes []*AddressBalanceChange, seenAtBlueScore *uint64) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	addressIDs := make([]int64, len(changes))
+	balanceChanges := make([]int64, len(changes))
+	transactionCountChanges := make([]int64, len(changes))
+	for i, change := range changes {
+		addressIDs[i] = int64(change.AddressID)
+		balanceChanges[i] = change.BalanceChange
+		transactionCountChanges[i] = change.TransactionCountChange
+	}
+
+	if seenAtBlueScore == nil {
+		_, err = db.Exec(`UPDATE address_balances
+			SET balance = address_balances.balance + changes.balance_change,
+				transaction_count = address_balances.transaction_count + changes.transaction_count_change
+			FROM unnest(?0::BIGINT[], ?1::BIGINT[], ?2::BIGINT[])
+				AS changes (address_id, balance_change, transaction_count_change)
+			WHERE address_balances.address_id = changes.address_id`,
+			pg.Array(addressIDs), pg.Array(balanceChanges), pg.Array(transactionCountChanges))
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO address_balances
+		(address_id, balance, transaction_count, first_seen_blue_score, last_seen_blue_score)
+		SELECT changes.address_id, changes.balance_change, changes.transaction_count_change, ?3, ?3
+		FROM unnest(?0::BIGINT[], ?1::BIGINT[], ?2::BIGINT[])
+			AS changes (address_id, balance_change, transaction_count_change)
+		ON CONFLICT (address_id) DO UPDATE SET
+			balance = address_balances.balance + EXCLUDED.balance,
+			transaction_count = address_balances.transaction_count + EXCLUDED.transaction_count,
+			first_seen_blue_score = LEAST(address_balances.first_seen_blue_score, EXCLUDED.first_seen_blue_score),
+			last_seen_blue_score = GREATEST(address_balances.last_seen_blue_score, EXCLUDED.last_seen_blue_score)`,
+		pg.Array(addressIDs), pg.Array(balanceChanges), pg.Array(transactionCountChanges), *seenAtBlueScore)
+	return err
+}
+
+// TopAddressBalances retrieves up to `limit` address balances, ranked by the given
+// field from highest to lowest, skipping the first `skip` addresses
+// If preloadedFields was provided - preloads the requested fields
+func TopAddressBalances(ctx database.Context, ranking AddressRanking, skip uint64, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.AddressBalance, error) {
+
+	if limit == 0 {
+		return []*dbmodels.AddressBalance{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var addressBalances []*dbmodels.AddressBalance
+	query := db.Model(&addressBalances).
+		Order(ranking.column()+" DESC", "address_balance.address_id").
+		Limit(int(limit)).
+		Offset(int(skip))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return addressBalances, nil
+}
@@ -53,7 +53,33 @@ func BlocksByHashes(ctx database.Context, hashes []string, preloadedFields ...db
 	return blocks, nil
 }
 
-// Blocks retrieves from the database up to `limit` blocks in the requested `order`, skipping the first `skip` blocks
+// BlocksByHashPrefix retrieves up to `limit` blocks whose hashes start with
+// `hashPrefix`, ordered by their hashes. `hashPrefix` must be lowercase hex.
+// If preloadedFields was provided - preloads the requested fields
+func BlocksByHashPrefix(ctx database.Context, hashPrefix string, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := []*dbmodels.Block{}
+	query := db.Model(&blocks).
+		Where("block.block_hash LIKE ?", hashPrefix+"%").
+		Order("block.block_hash").
+		Limit(int(limit))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// Blocks retrieves from the database up to `limit` blocks ordered by blue score and ID in
+// the requested `order`, skipping the first `skip` blocks
 // If preloadedFields was provided - preloads the requested fields
 func Blocks(ctx database.Context, order Order, skip uint64, limit uint64,
 	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
@@ -73,7 +99,7 @@ func Blocks(ctx database.Context, order Order, skip uint64, limit uint64,
 		Limit(int(limit))
 
 	if order != OrderUnknown {
-		query = query.Order(fmt.Sprintf("id %s", order))
+		query = query.Order(fmt.Sprintf("blue_score %s", order), fmt.Sprintf("id %s", order))
 	}
 
 	query = preloadFields(query, preloadedFields)
@@ -86,6 +112,55 @@ func Blocks(ctx database.Context, order Order, skip uint64, limit uint64,
 	return blocks, nil
 }
 
+// BlockCursor is a position in the list of blocks ordered by blue score and ID.
+type BlockCursor struct {
+	BlueScore uint64
+	ID        uint64
+}
+
+// BlocksByCursor retrieves from the database up to `limit` blocks ordered by blue score and ID in
+// the requested `order`, starting right after the block at `cursor`.
+// If cursor is nil - starts from the first block in the requested order.
+// If preloadedFields was provided - preloads the requested fields
+func BlocksByCursor(ctx database.Context, order Order, cursor *BlockCursor, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+
+	if limit == 0 {
+		return []*dbmodels.Block{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if order == OrderUnknown {
+		order = OrderAscending
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).
+		Order(fmt.Sprintf("blue_score %s", order), fmt.Sprintf("id %s", order)).
+		Limit(int(limit))
+
+	if cursor != nil {
+		comparisonOperator := ">"
+		if order == OrderDescending {
+			comparisonOperator = "<"
+		}
+		query = query.Where(fmt.Sprintf("(block.blue_score, block.id) %s (?, ?)", comparisonOperator),
+			cursor.BlueScore, cursor.ID)
+	}
+
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
 // SelectedTip fetches the selected tip from the database
 func SelectedTip(ctx database.Context) (*dbmodels.Block, error) {
 	db, err := ctx.DB()
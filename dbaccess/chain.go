@@ -0,0 +1,79 @@
+package dbaccess
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// ChainBlocksByBlueScore retrieves up to `limit` selected parent chain blocks whose blue
+// score is between `fromBlueScore` and `toBlueScore` (inclusive), ordered by blue score in
+// the requested `order`. A nil bound is ignored.
+// If preloadedFields was provided - preloads the requested fields
+func ChainBlocksByBlueScore(ctx database.Context, order Order, fromBlueScore *uint64, toBlueScore *uint64,
+	limit uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+
+	if limit == 0 {
+		return []*dbmodels.Block{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if order == OrderUnknown {
+		order = OrderAscending
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).
+		Where("block.is_chain_block = ?", true).
+		Order(fmt.Sprintf("block.blue_score %s", order)).
+		Limit(int(limit))
+	if fromBlueScore != nil {
+		query = query.Where("block.blue_score >= ?", *fromBlueScore)
+	}
+	if toBlueScore != nil {
+		query = query.Where("block.blue_score <= ?", *toBlueScore)
+	}
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ChainBlockAtBlueScore retrieves the selected parent chain block with the highest
+// blue score that is not greater than `blueScore`. Since chain blocks don't exist
+// at every blue score, this is the chain block that was the selected tip when the
+// DAG reached `blueScore`. Returns nil if there's no such block.
+// If preloadedFields was provided - preloads the requested fields
+func ChainBlockAtBlueScore(ctx database.Context, blueScore uint64, preloadedFields ...dbmodels.FieldName) (
+	*dbmodels.Block, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	block := &dbmodels.Block{}
+	query := db.Model(block).
+		Where("block.is_chain_block = ?", true).
+		Where("block.blue_score <= ?", blueScore).
+		Order("block.blue_score DESC")
+	query = preloadFields(query, preloadedFields)
+	err = query.First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
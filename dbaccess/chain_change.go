@@ -0,0 +1,166 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// ChainChanges retrieves up to `limit` changes of the selected parent chain in which at least
+// `minDepth` chain blocks were removed, newest first, skipping the first `skip` changes
+// If preloadedFields was provided - preloads the requested fields
+func ChainChanges(ctx database.Context, minDepth uint64, skip uint64, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.ChainChange, error) {
+
+	if limit == 0 {
+		return []*dbmodels.ChainChange{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var chainChanges []*dbmodels.ChainChange
+	query := db.Model(&chainChanges).
+		Where("chain_change.depth >= ?", minDepth).
+		Order("chain_change.id DESC").
+		Limit(int(limit)).
+		Offset(int(skip))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return chainChanges, nil
+}
+
+// ChainChangeTransactionsByTransactionID retrieves all the times the transactions with
+// the given transaction ID were unaccepted, oldest first. The transactions are
+// always preloaded.
+// If preloadedFields was provided - preloads the requested fields
+func ChainChangeTransactionsByTransactionID(ctx database.Context, transactionID string,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.ChainChangeTransaction, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var chainChangeTransactions []*dbmodels.ChainChangeTransaction
+	query := db.Model(&chainChangeTransactions).
+		Relation("Transaction").
+		Where("transaction.transaction_id = ?", transactionID).
+		Order("chain_change_transaction.chain_change_id ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return chainChangeTransactions, nil
+}
+
+// AddedChainChangeBlocksByBlockIDs retrieves all the times the blocks with
+// the given IDs were added to the selected parent chain, oldest first.
+// If preloadedFields was provided - preloads the requested fields
+func AddedChainChangeBlocksByBlockIDs(ctx database.Context, blockIDs []uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.ChainChangeBlock, error) {
+
+	if len(blockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var chainChangeBlocks []*dbmodels.ChainChangeBlock
+	query := db.Model(&chainChangeBlocks).
+		Where("chain_change_block.block_id IN (?)", pg.In(blockIDs)).
+		Where("chain_change_block.is_added").
+		Order("chain_change_block.chain_change_id ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return chainChangeBlocks, nil
+}
+
+// AcceptedTransactionIDsByBlockIDs returns the transaction IDs of the transactions
+// that are accepted by the chain blocks with the given IDs, mapped by the block IDs.
+// Since the transactions accepted by a chain block are determined by its past,
+// they're the transactions that the block currently accepts, along with the ones
+// that were unaccepted when it was removed from the selected parent chain.
+func AcceptedTransactionIDsByBlockIDs(ctx database.Context, blockIDs []uint64) (map[uint64][]string, error) {
+	if len(blockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var acceptedTransactions []struct {
+		BlockID       uint64
+		TransactionID string
+	}
+	_, err = db.Query(&acceptedTransactions, `SELECT transactions.accepting_block_id AS block_id, transactions.transaction_id
+		FROM transactions
+		WHERE transactions.accepting_block_id IN (?0)
+		UNION
+		SELECT chain_change_transactions.block_id, transactions.transaction_id
+		FROM chain_change_transactions
+		INNER JOIN transactions ON transactions.id = chain_change_transactions.transaction_id
+		WHERE chain_change_transactions.block_id IN (?0)
+		ORDER BY block_id, transaction_id`,
+		pg.In(blockIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	transactionIDsByBlockID := make(map[uint64][]string)
+	for _, acceptedTransaction := range acceptedTransactions {
+		transactionIDsByBlockID[acceptedTransaction.BlockID] = append(
+			transactionIDsByBlockID[acceptedTransaction.BlockID], acceptedTransaction.TransactionID)
+	}
+	return transactionIDsByBlockID, nil
+}
+
+// InsertChainChange inserts a chain change along with its blocks and unaccepted
+// transactions into the database.
+func InsertChainChange(ctx database.Context, chainChange *dbmodels.ChainChange) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	err = db.Insert(chainChange)
+	if err != nil {
+		return err
+	}
+
+	blocks := make([]interface{}, len(chainChange.ChainChangeBlocks))
+	for i := range chainChange.ChainChangeBlocks {
+		block := &chainChange.ChainChangeBlocks[i]
+		block.ChainChangeID = chainChange.ID
+		blocks[i] = block
+	}
+	err = BulkInsert(ctx, blocks)
+	if err != nil {
+		return err
+	}
+
+	transactions := make([]interface{}, len(chainChange.ChainChangeTransactions))
+	for i := range chainChange.ChainChangeTransactions {
+		transaction := &chainChange.ChainChangeTransactions[i]
+		transaction.ChainChangeID = chainChange.ID
+		transactions[i] = transaction
+	}
+	return BulkInsert(ctx, transactions)
+}
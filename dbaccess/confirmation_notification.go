@@ -0,0 +1,39 @@
+package dbaccess
+
+import (
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// ConfirmationNotificationThresholds retrieves the notification
+// progress of all the confirmation thresholds
+func ConfirmationNotificationThresholds(ctx database.Context) ([]*dbmodels.ConfirmationNotificationThreshold, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var thresholds []*dbmodels.ConfirmationNotificationThreshold
+	err = db.Model(&thresholds).Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return thresholds, nil
+}
+
+// UpdateConfirmationNotificationThreshold inserts the notification progress of the
+// given confirmation threshold, or replaces the existing one if it was already inserted.
+func UpdateConfirmationNotificationThreshold(ctx database.Context,
+	threshold *dbmodels.ConfirmationNotificationThreshold) error {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(threshold).
+		OnConflict("(threshold) DO UPDATE").
+		Insert()
+	return err
+}
@@ -0,0 +1,168 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// BlocksByIDs retrieves all the blocks with the given database IDs
+// If preloadedFields was provided - preloads the requested fields
+func BlocksByIDs(ctx database.Context, blockIDs []uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+	if len(blockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).
+		Where("block.id IN (?)", pg.In(blockIDs)).
+		Order("block.blue_score ASC", "block.id ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ChildBlocks retrieves all the blocks that have the block with `blockID` as a parent
+// If preloadedFields was provided - preloads the requested fields
+func ChildBlocks(ctx database.Context, blockID uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+	childIDs, err := ChildBlockIDs(ctx, []uint64{blockID})
+	if err != nil {
+		return nil, err
+	}
+	return BlocksByIDs(ctx, childIDs, preloadedFields...)
+}
+
+// ChildBlocksByParentBlockIDs retrieves the children of all the blocks with the given IDs,
+// mapped by the IDs of their parents. Blocks without children aren't included in the map.
+// If preloadedFields was provided - preloads the requested fields
+func ChildBlocksByParentBlockIDs(ctx database.Context, parentBlockIDs []uint64,
+	preloadedFields ...dbmodels.FieldName) (map[uint64][]*dbmodels.Block, error) {
+
+	if len(parentBlockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentBlocks []*dbmodels.ParentBlock
+	err = db.Model(&parentBlocks).
+		Column("block_id", "parent_block_id").
+		Where("parent_block_id IN (?)", pg.In(parentBlockIDs)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	parentBlockIDsByChildID := make(map[uint64][]uint64)
+	childIDs := make([]uint64, 0, len(parentBlocks))
+	for _, parentBlock := range parentBlocks {
+		if _, ok := parentBlockIDsByChildID[parentBlock.BlockID]; !ok {
+			childIDs = append(childIDs, parentBlock.BlockID)
+		}
+		parentBlockIDsByChildID[parentBlock.BlockID] = append(
+			parentBlockIDsByChildID[parentBlock.BlockID], parentBlock.ParentBlockID)
+	}
+	children, err := BlocksByIDs(ctx, childIDs, preloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Children are appended in the order of BlocksByIDs, so that
+	// every list of children is ordered like the result of ChildBlocks
+	childrenByParentBlockID := make(map[uint64][]*dbmodels.Block)
+	for _, child := range children {
+		for _, parentBlockID := range parentBlockIDsByChildID[child.ID] {
+			childrenByParentBlockID[parentBlockID] = append(childrenByParentBlockID[parentBlockID], child)
+		}
+	}
+	return childrenByParentBlockID, nil
+}
+
+// SelectedParent retrieves the selected parent of the block with `blockID`.
+// Like in kaspad, the selected parent is the parent with the highest blue
+// score, where ties are broken in favor of the parent with the greater hash.
+// Returns nil if the block has no parents.
+// If preloadedFields was provided - preloads the requested fields
+func SelectedParent(ctx database.Context, blockID uint64, preloadedFields ...dbmodels.FieldName) (*dbmodels.Block, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	parentIDsQuery := db.Model(&dbmodels.ParentBlock{}).
+		Column("parent_block_id").
+		Where("block_id = ?", blockID)
+
+	block := &dbmodels.Block{}
+	query := db.Model(block).
+		Where("block.id IN (?)", parentIDsQuery).
+		Order("block.blue_score DESC", "block.block_hash DESC")
+	query = preloadFields(query, preloadedFields)
+	err = query.First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// ParentBlockIDs returns the IDs of all the parents of the blocks with the given IDs
+func ParentBlockIDs(ctx database.Context, blockIDs []uint64) ([]uint64, error) {
+	if len(blockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentIDs []uint64
+	err = db.Model(&dbmodels.ParentBlock{}).
+		ColumnExpr("DISTINCT parent_block_id").
+		Where("block_id IN (?)", pg.In(blockIDs)).
+		Select(&parentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return parentIDs, nil
+}
+
+// ChildBlockIDs returns the IDs of all the children of the blocks with the given IDs
+func ChildBlockIDs(ctx database.Context, blockIDs []uint64) ([]uint64, error) {
+	if len(blockIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var childIDs []uint64
+	err = db.Model(&dbmodels.ParentBlock{}).
+		ColumnExpr("DISTINCT block_id").
+		Where("parent_block_id IN (?)", pg.In(blockIDs)).
+		Select(&childIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return childIDs, nil
+}
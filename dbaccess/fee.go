@@ -0,0 +1,57 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+)
+
+// FeeRatePercentiles returns the number of non-coinbase transactions that were accepted
+// by chain blocks with a blue score of at least `minBlueScore`, and their fee rates (in
+// sompi per gram of mass) at each of the requested `percentiles`. Transactions whose fee
+// can't be computed, since some of their previous outputs are missing, aren't sampled.
+// If no such transactions exist, the returned fee rates are nil.
+func FeeRatePercentiles(ctx database.Context, minBlueScore uint64, percentiles []float64) (
+	sampleCount uint64, feeRates []float64, err error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var result struct {
+		SampleCount uint64
+		FeeRates    []float64 `pg:",array"`
+	}
+	_, err = db.QueryOne(&result, `
+SELECT
+	count(*) AS sample_count,
+	percentile_cont(?::DOUBLE PRECISION[]) WITHIN GROUP (ORDER BY fee_rate) AS fee_rates
+FROM (
+	SELECT
+		(input_values.value - output_values.value)::DOUBLE PRECISION / transactions.mass AS fee_rate
+	FROM
+		transactions
+	INNER JOIN blocks ON blocks.id = transactions.accepting_block_id
+	INNER JOIN LATERAL (
+		SELECT SUM(previous_outputs.value) AS value, COUNT(*) = COUNT(previous_outputs.id) AS are_all_found
+		FROM transaction_inputs
+		LEFT JOIN transaction_outputs AS previous_outputs ON previous_outputs.id = transaction_inputs.previous_transaction_output_id
+		WHERE transaction_inputs.transaction_id = transactions.id
+	) AS input_values ON input_values.value IS NOT NULL AND input_values.are_all_found
+	INNER JOIN LATERAL (
+		SELECT COALESCE(SUM(transaction_outputs.value), 0) AS value FROM transaction_outputs
+		WHERE transaction_outputs.transaction_id = transactions.id
+	) AS output_values ON TRUE
+	WHERE
+		blocks.blue_score >= ?
+		AND transactions.mass > 0
+		AND input_values.value >= output_values.value
+) AS fee_rates
+`, pg.Array(percentiles), minBlueScore)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return result.SampleCount, result.FeeRates, nil
+}
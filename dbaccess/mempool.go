@@ -0,0 +1,237 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// MempoolTransactionIDs retrieves the transaction IDs of all the transactions
+// that are currently stored as pending in the mempool tables
+func MempoolTransactionIDs(ctx database.Context) ([]string, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionIDs []string
+	err = db.Model(&dbmodels.MempoolTransaction{}).
+		Column("transaction_id").
+		Select(&transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionIDs, nil
+}
+
+// MempoolTransactionByID retrieves a mempool transaction from the database that has the provided ID
+// If preloadedFields was provided - preloads the requested fields
+func MempoolTransactionByID(ctx database.Context, transactionID string, preloadedFields ...dbmodels.FieldName) (
+	*dbmodels.MempoolTransaction, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &dbmodels.MempoolTransaction{}
+	query := db.Model(tx).Where("mempool_transaction.transaction_id = ?", transactionID)
+	query = preloadFields(query, preloadedFields)
+	err = query.First()
+
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// MempoolTransactions retrieves up to `limit` mempool transactions, newest first,
+// skipping the first `skip` transactions
+// If preloadedFields was provided - preloads the requested fields
+func MempoolTransactions(ctx database.Context, skip uint64, limit uint64, preloadedFields ...dbmodels.FieldName) (
+	[]*dbmodels.MempoolTransaction, error) {
+
+	if limit == 0 {
+		return []*dbmodels.MempoolTransaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*dbmodels.MempoolTransaction
+	query := db.Model(&txs).
+		Order("mempool_transaction.id DESC").
+		Limit(int(limit)).
+		Offset(int(skip))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// MempoolTransactionsCount returns the number of transactions in the mempool tables
+func MempoolTransactionsCount(ctx database.Context) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := db.Model(&dbmodels.MempoolTransaction{}).Count()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// MempoolTransactionsByAddress retrieves up to `limit` mempool transactions sent to or
+// from `address`, newest first, skipping the first `skip` transactions
+// If preloadedFields was provided - preloads the requested fields
+func MempoolTransactionsByAddress(ctx database.Context, address string, skip uint64, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.MempoolTransaction, error) {
+
+	if limit == 0 {
+		return []*dbmodels.MempoolTransaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*dbmodels.MempoolTransaction
+	query := db.Model(&txs)
+	query = whereMempoolTransactionHasAddress(db, query, address).
+		Order("mempool_transaction.id DESC").
+		Limit(int(limit)).
+		Offset(int(skip))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// MempoolTransactionsByAddressCount returns the number of mempool
+// transactions sent to or from `address`
+func MempoolTransactionsByAddressCount(ctx database.Context, address string) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Model(&dbmodels.MempoolTransaction{})
+	count, err := whereMempoolTransactionHasAddress(db, query, address).Count()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+func whereMempoolTransactionHasAddress(db database.DB, query *orm.Query, address string) *orm.Query {
+	outputsQuery := db.Model(&dbmodels.MempoolTransactionOutput{}).
+		Column("mempool_transaction_id").
+		Where("address = ?", address)
+	inputsQuery := db.Model(&dbmodels.MempoolTransactionInput{}).
+		Column("mempool_transaction_id").
+		Where("address = ?", address)
+	return query.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+		return q.Where("mempool_transaction.id IN (?)", outputsQuery).
+			WhereOr("mempool_transaction.id IN (?)", inputsQuery), nil
+	})
+}
+
+// MempoolTransactionOutputsByOutpoints retrieves all the mempool transaction outputs
+// referenced by `outpoints`
+func MempoolTransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint) (
+	[]*dbmodels.MempoolTransactionOutput, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+	outpointTuples := outpointsToSQLTuples(outpoints)
+
+	var outputs []*dbmodels.MempoolTransactionOutput
+	for offset := 0; offset < len(outpointTuples); {
+		var chunk [][]interface{}
+		chunk, offset = outpointsChunk(outpointTuples, offset)
+		var outputsChunk []*dbmodels.MempoolTransactionOutput
+		err = db.Model(&outputsChunk).
+			Relation("MempoolTransaction").
+			Where("(mempool_transaction.transaction_id, mempool_transaction_output.index) in (?)", pg.In(chunk)).
+			Select()
+		if err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, outputsChunk...)
+	}
+
+	return outputs, nil
+}
+
+// InsertMempoolTransaction inserts a mempool transaction along with
+// its inputs and outputs into the database
+func InsertMempoolTransaction(ctx database.Context, tx *dbmodels.MempoolTransaction) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	err = db.Insert(tx)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]interface{}, len(tx.MempoolTransactionOutputs))
+	for i := range tx.MempoolTransactionOutputs {
+		output := &tx.MempoolTransactionOutputs[i]
+		output.MempoolTransactionID = tx.ID
+		outputs[i] = output
+	}
+	err = BulkInsert(ctx, outputs)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]interface{}, len(tx.MempoolTransactionInputs))
+	for i := range tx.MempoolTransactionInputs {
+		input := &tx.MempoolTransactionInputs[i]
+		input.MempoolTransactionID = tx.ID
+		inputs[i] = input
+	}
+	return BulkInsert(ctx, inputs)
+}
+
+// DeleteMempoolTransactions deletes the mempool transactions with the
+// given transaction IDs, along with their inputs and outputs
+func DeleteMempoolTransactions(ctx database.Context, transactionIDs []string) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.MempoolTransaction{}).
+		Where("transaction_id IN (?)", pg.In(transactionIDs)).
+		Delete()
+	return err
+}
@@ -0,0 +1,112 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// InsertOutboxNotification adds a notification with the given topic and payload to
+// the outbox, numbered with the next sequence number of the topic. Notifications
+// inserted within a database transaction are only relayed once it commits.
+func InsertOutboxNotification(ctx database.Context, topic string, payload []byte) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`WITH topic_sequence AS (
+			INSERT INTO notification_topic_sequences (topic, last_sequence)
+			VALUES (?0, 1)
+			ON CONFLICT (topic) DO UPDATE SET last_sequence = notification_topic_sequences.last_sequence + 1
+			RETURNING last_sequence
+		)
+		INSERT INTO notification_outbox (topic, sequence, payload, created_at, attempts)
+		SELECT ?0, topic_sequence.last_sequence, ?1, ?2, 0
+		FROM topic_sequence`,
+		topic, payload, time.Now())
+	return err
+}
+
+// UnsentOutboxNotifications retrieves up to `limit` notifications
+// that were not sent yet, in the order they were inserted
+func UnsentOutboxNotifications(ctx database.Context, limit uint64) ([]*dbmodels.OutboxNotification, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var outboxNotifications []*dbmodels.OutboxNotification
+	err = db.Model(&outboxNotifications).
+		Where("sent_at IS NULL").
+		Order("id ASC").
+		Limit(int(limit)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxNotifications, nil
+}
+
+// UpdateOutboxNotificationSent records that notification `outboxNotificationID` was sent at `sentAt`
+func UpdateOutboxNotificationSent(ctx database.Context, outboxNotificationID uint64, sentAt time.Time) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.OutboxNotification{}).
+		Set("sent_at = ?", sentAt).
+		Set("attempts = attempts + 1").
+		Where("id = ?", outboxNotificationID).
+		Update()
+	return err
+}
+
+// UpdateOutboxNotificationFailed records that an attempt
+// to send notification `outboxNotificationID` failed
+func UpdateOutboxNotificationFailed(ctx database.Context, outboxNotificationID uint64, lastError string) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.OutboxNotification{}).
+		Set("last_error = ?", lastError).
+		Set("attempts = attempts + 1").
+		Where("id = ?", outboxNotificationID).
+		Update()
+	return err
+}
+
+// DeleteOutboxNotificationsSentBefore deletes the notifications that were sent before `before`
+func DeleteOutboxNotificationsSentBefore(ctx database.Context, before time.Time) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.OutboxNotification{}).
+		Where("sent_at < ?", before).
+		Delete()
+	return err
+}
+
+// UnsentOutboxNotificationCount returns the number of notifications that were not sent yet
+func UnsentOutboxNotificationCount(ctx database.Context) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := db.Model(&dbmodels.OutboxNotification{}).
+		Where("sent_at IS NULL").
+		Count()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
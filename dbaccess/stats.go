@@ -0,0 +1,241 @@
+package dbaccess
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/pkg/errors"
+)
+
+// StatsInterval is the length of the buckets that network statistics are grouped into
+type StatsInterval string
+
+// StatsInterval constants
+const (
+	StatsIntervalHour StatsInterval = "hour"
+	StatsIntervalDay  StatsInterval = "day"
+	StatsIntervalWeek StatsInterval = "week"
+)
+
+// Duration returns the length of the interval
+func (interval StatsInterval) Duration() time.Duration {
+	switch interval {
+	case StatsIntervalDay:
+		return 24 * time.Hour
+	case StatsIntervalWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
+// StringToStatsInterval converts an interval string into a StatsInterval type.
+// Returns an error if passed string is not hour, day or week
+func StringToStatsInterval(intervalString string) (StatsInterval, error) {
+	interval := StatsInterval(strings.ToLower(intervalString))
+	if interval != StatsIntervalHour && interval != StatsIntervalDay && interval != StatsIntervalWeek {
+		return "", errors.Errorf("'%s' is not a valid interval", intervalString)
+	}
+	return interval, nil
+}
+
+// StatsBucket holds the rolled up network statistics of a period of time.
+// TotalWork is the sum of the expected number of hashes required to
+// mine each of the blocks. TransactionCount is the number of transactions
+// that were accepted by the chain blocks of the period, and CoinbaseValue
+// is the sum of the values of the outputs of the coinbase transactions
+// among them.
+type StatsBucket struct {
+	Bucket             time.Time
+	BlockCount         uint64
+	TransactionCount   uint64
+	TotalMass          uint64
+	TotalWork          float64
+	CoinbaseValue      uint64
+	ActiveAddressCount uint64
+}
+
+// UpdateHourlyStats adds the blocks with the given IDs to the hourly statistics rollups.
+// Each block must be added exactly once, after its transactions were inserted. The
+// transactions are only counted once they're accepted - see UpdateHourlyAcceptanceStats.
+func UpdateHourlyStats(ctx database.Context, blockIDs []uint64) error {
+	if len(blockIDs) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	// The work of a block is 2^256 divided by its target, which is decoded out
+	// of the compact representation in bits as mantissa * 256^(exponent - 3).
+	_, err = db.Exec(`INSERT INTO hourly_stats (hour, block_count, transaction_count, total_mass, total_work, coinbase_value)
+		SELECT block_stats.hour,
+			COUNT(*),
+			0,
+			SUM(block_stats.mass),
+			COALESCE(SUM(block_stats.work), 0),
+			0
+		FROM (
+			SELECT date_trunc('hour', blocks.timestamp) AS hour,
+				blocks.mass,
+				2::DOUBLE PRECISION ^ 256 / NULLIF((blocks.bits & 8388607)::DOUBLE PRECISION *
+					256::DOUBLE PRECISION ^ ((blocks.bits >> 24) - 3), 0) AS work
+			FROM blocks
+			WHERE blocks.id IN (?)
+		) AS block_stats
+		GROUP BY block_stats.hour
+		ON CONFLICT (hour) DO UPDATE SET
+			block_count = hourly_stats.block_count + EXCLUDED.block_count,
+			total_mass = hourly_stats.total_mass + EXCLUDED.total_mass,
+			total_work = hourly_stats.total_work + EXCLUDED.total_work`,
+		pg.In(blockIDs))
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO hourly_active_addresses (hour, address_id)
+		SELECT date_trunc('hour', blocks.timestamp), transaction_outputs.address_id
+		FROM blocks
+		INNER JOIN transactions_to_blocks ON transactions_to_blocks.block_id = blocks.id
+		INNER JOIN transaction_outputs ON transaction_outputs.transaction_id = transactions_to_blocks.transaction_id
+		WHERE blocks.id IN (?0) AND transaction_outputs.address_id IS NOT NULL
+		UNION
+		SELECT date_trunc('hour', blocks.timestamp), transaction_outputs.address_id
+		FROM blocks
+		INNER JOIN transactions_to_blocks ON transactions_to_blocks.block_id = blocks.id
+		INNER JOIN transaction_inputs ON transaction_inputs.transaction_id = transactions_to_blocks.transaction_id
+		INNER JOIN transaction_outputs ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+		WHERE blocks.id IN (?0) AND transaction_outputs.address_id IS NOT NULL
+		ON CONFLICT DO NOTHING`,
+		pg.In(blockIDs))
+	return err
+}
+
+// UpdateHourlyAcceptanceStats adds the transactions that are accepted by the chain block
+// with `chainBlockID` to the hourly statistics rollups of the hour of the chain block. If
+// `isAdded` is false, they're subtracted instead. It must be called after the block is
+// added to the selected parent chain, or before it's removed from it.
+func UpdateHourlyAcceptanceStats(ctx database.Context, chainBlockID uint64, isAdded bool) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	sign := 1
+	if !isAdded {
+		sign = -1
+	}
+
+	// Every transaction has a single accepting block, so every
+	// accepted transaction is counted exactly once
+	_, err = db.Exec(`INSERT INTO hourly_stats (hour, block_count, transaction_count, total_mass, total_work, coinbase_value)
+		SELECT accepted_transactions.hour,
+			0,
+			?1 * COUNT(*),
+			0,
+			0,
+			?1 * SUM(accepted_transactions.coinbase_value)
+		FROM (
+			SELECT date_trunc('hour', blocks.timestamp) AS hour,
+				(SELECT COALESCE(SUM(transaction_outputs.value), 0)
+					FROM transactions_to_blocks
+					INNER JOIN transaction_outputs ON transaction_outputs.transaction_id = transactions_to_blocks.transaction_id
+					WHERE transactions_to_blocks.transaction_id = transactions.id AND transactions_to_blocks.index = 0) AS coinbase_value
+			FROM transactions
+			INNER JOIN blocks ON blocks.id = transactions.accepting_block_id
+			WHERE transactions.accepting_block_id = ?0
+		) AS accepted_transactions
+		GROUP BY accepted_transactions.hour
+		ON CONFLICT (hour) DO UPDATE SET
+			transaction_count = hourly_stats.transaction_count + EXCLUDED.transaction_count,
+			coinbase_value = hourly_stats.coinbase_value + EXCLUDED.coinbase_value`,
+		chainBlockID, sign)
+	return err
+}
+
+// StatsBuckets retrieves the network statistics between `from` (inclusive)
+// and `to` (exclusive), grouped into buckets of the given interval. Buckets
+// without any blocks are omitted.
+func StatsBuckets(ctx database.Context, interval StatsInterval, from time.Time, to time.Time) ([]*StatsBucket, error) {
+	return statsBuckets(ctx, fmt.Sprintf("date_trunc('%s', hour)", interval), from, to)
+}
+
+// StatsTotal retrieves the network statistics between `from` (inclusive)
+// and `to` (exclusive) as a single bucket starting at `from`
+func StatsTotal(ctx database.Context, from time.Time, to time.Time) (*StatsBucket, error) {
+	buckets, err := statsBuckets(ctx, "?0::TIMESTAMP", from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 {
+		return &StatsBucket{Bucket: from}, nil
+	}
+	return buckets[0], nil
+}
+
+// statsBuckets groups the hourly rollups between `from` and `to` by
+// `bucketExpression`, which is an SQL expression of the hour column.
+func statsBuckets(ctx database.Context, bucketExpression string, from time.Time, to time.Time) ([]*StatsBucket, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []*StatsBucket
+	_, err = db.Query(&buckets, fmt.Sprintf(`SELECT stats.bucket,
+			stats.block_count,
+			stats.transaction_count,
+			stats.total_mass,
+			stats.total_work,
+			stats.coinbase_value,
+			COALESCE(active_addresses.active_address_count, 0) AS active_address_count
+		FROM (
+			SELECT %[1]s AS bucket,
+				SUM(block_count)::BIGINT AS block_count,
+				SUM(transaction_count)::BIGINT AS transaction_count,
+				SUM(total_mass)::BIGINT AS total_mass,
+				SUM(total_work) AS total_work,
+				SUM(coinbase_value)::BIGINT AS coinbase_value
+			FROM hourly_stats
+			WHERE hour >= ?0 AND hour < ?1
+			GROUP BY 1
+		) AS stats
+		LEFT JOIN (
+			SELECT %[1]s AS bucket,
+				COUNT(DISTINCT address_id) AS active_address_count
+			FROM hourly_active_addresses
+			WHERE hour >= ?0 AND hour < ?1
+			GROUP BY 1
+		) AS active_addresses ON active_addresses.bucket = stats.bucket
+		ORDER BY stats.bucket`, bucketExpression),
+		from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// CoinbaseValueBefore returns the sum of the values of the outputs of all the accepted
+// coinbase transactions whose accepting block's timestamp is in an hour that starts
+// before `before`
+func CoinbaseValueBefore(ctx database.Context, before time.Time) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var coinbaseValue uint64
+	_, err = db.QueryOne(pg.Scan(&coinbaseValue),
+		"SELECT COALESCE(SUM(coinbase_value), 0)::BIGINT FROM hourly_stats WHERE hour < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return coinbaseValue, nil
+}
@@ -0,0 +1,95 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// SubmittedTransactionByID retrieves the submitted transaction with the provided transaction ID
+func SubmittedTransactionByID(ctx database.Context, transactionID string) (*dbmodels.SubmittedTransaction, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	submittedTx := &dbmodels.SubmittedTransaction{}
+	err = db.Model(submittedTx).
+		Where("transaction_id = ?", transactionID).
+		First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return submittedTx, nil
+}
+
+// UpsertSubmittedTransaction inserts the given submitted transaction. If it was
+// already submitted, its rejection error and broadcast time are replaced, and
+// its broadcast count is incremented.
+func UpsertSubmittedTransaction(ctx database.Context, submittedTx *dbmodels.SubmittedTransaction) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(submittedTx).
+		OnConflict("(transaction_id) DO UPDATE").
+		Set("rejection_error = EXCLUDED.rejection_error").
+		Set("last_broadcast_at = EXCLUDED.last_broadcast_at").
+		Set("broadcast_count = submitted_transaction.broadcast_count + 1").
+		Insert()
+	return err
+}
+
+// SubmittedTransactionsToRebroadcast retrieves up to `limit` submitted transactions
+// that were not rejected, were not included in any block yet, were submitted after
+// `submittedAfter` and were last broadcast before `lastBroadcastBefore`
+func SubmittedTransactionsToRebroadcast(ctx database.Context, submittedAfter time.Time,
+	lastBroadcastBefore time.Time, limit uint64) ([]*dbmodels.SubmittedTransaction, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var submittedTxs []*dbmodels.SubmittedTransaction
+	err = db.Model(&submittedTxs).
+		Where("rejection_error IS NULL").
+		Where("submitted_at > ?", submittedAfter).
+		Where("last_broadcast_at < ?", lastBroadcastBefore).
+		Where("NOT EXISTS (SELECT 1 FROM transactions WHERE transactions.transaction_id = submitted_transaction.transaction_id)").
+		Order("last_broadcast_at ASC").
+		Limit(int(limit)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return submittedTxs, nil
+}
+
+// UpdateSubmittedTransactionBroadcast records that submitted transaction
+// `submittedTxID` was broadcast again at `broadcastAt`, and replaces its
+// rejection error with `rejectionError`
+func UpdateSubmittedTransactionBroadcast(ctx database.Context, submittedTxID uint64, broadcastAt time.Time,
+	rejectionError *string) error {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.SubmittedTransaction{}).
+		Set("last_broadcast_at = ?", broadcastAt).
+		Set("broadcast_count = broadcast_count + 1").
+		Set("rejection_error = ?", rejectionError).
+		Where("id = ?", submittedTxID).
+		Update()
+	return err
+}
@@ -0,0 +1,44 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// SyncState retrieves the sync state of kasparovsyncd.
+// Returns nil if kasparovsyncd had never started syncing.
+func SyncState(ctx database.Context) (*dbmodels.SyncState, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	syncState := &dbmodels.SyncState{}
+	err = db.Model(syncState).
+		Where("id = ?", dbmodels.SyncStateID).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return syncState, nil
+}
+
+// UpdateSyncState inserts the given sync state, or replaces
+// the existing one if it was already inserted.
+func UpdateSyncState(ctx database.Context, syncState *dbmodels.SyncState) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	syncState.ID = dbmodels.SyncStateID
+	_, err = db.Model(syncState).
+		OnConflict("(id) DO UPDATE").
+		Insert()
+	return err
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/go-pg/pg/v9"
-	"github.com/go-pg/pg/v9/orm"
 	"github.com/kaspanet/kasparov/database"
 	"github.com/kaspanet/kasparov/dbmodels"
 )
@@ -56,11 +55,12 @@ func TransactionByHash(ctx database.Context, transactionHash string, preloadedFi
 	return tx, nil
 }
 
-// TransactionsByAddress retrieves up to `limit` transactions sent to or from `address`,
-// in the requested `order`, skipping the first `skip` blocks
+// TransactionsByAddress retrieves up to `limit` transactions sent to or from `address`
+// that pass `filter`, in the requested `order`, skipping the first `skip` transactions.
+// `filter` may be nil.
 // If preloadedFields was provided - preloads the requested fields
-func TransactionsByAddress(ctx database.Context, address string, order Order, skip uint64, limit uint64, preloadedFields ...dbmodels.FieldName) (
-	[]*dbmodels.Transaction, error) {
+func TransactionsByAddress(ctx database.Context, address string, filter *AddressTransactionsFilter, order Order,
+	skip uint64, limit uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
 
 	if limit == 0 {
 		return []*dbmodels.Transaction{}, nil
@@ -71,12 +71,17 @@ func TransactionsByAddress(ctx database.Context, address string, order Order, sk
 		return nil, err
 	}
 
+	addressID, err := addressIDByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if addressID == nil {
+		return []*dbmodels.Transaction{}, nil
+	}
+
 	var txs []*dbmodels.Transaction
 	query := db.Model(&txs)
-	query = joinTxInputsTxOutputsAndAddresses(query).
-		DistinctOn("transaction.id").
-		Where("out_addresses.address = ?", address).
-		WhereOr("in_addresses.address = ?", address).
+	query = whereTransactionMatchesAddressFilter(query, *addressID, filter).
 		Limit(int(limit)).
 		Offset(int(skip))
 
@@ -93,45 +98,82 @@ func TransactionsByAddress(ctx database.Context, address string, order Order, sk
 	return txs, nil
 }
 
-// TransactionsByAddressCount returns the total number of transactions sent to or from `address`
-func TransactionsByAddressCount(ctx database.Context, address string) (uint64, error) {
+// TransactionsByAddressAfterID retrieves up to `limit` transactions sent to or from `address`
+// that pass `filter`, ordered by their database ID in the requested `order`, starting right
+// after the transaction with the database ID `afterID`.
+// If afterID is nil - starts from the first transaction in the requested order.
+// `filter` may be nil.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByAddressAfterID(ctx database.Context, address string, filter *AddressTransactionsFilter, order Order,
+	afterID *uint64, limit uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	if limit == 0 {
+		return []*dbmodels.Transaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if order == OrderUnknown {
+		order = OrderAscending
+	}
+
+	addressID, err := addressIDByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if addressID == nil {
+		return []*dbmodels.Transaction{}, nil
+	}
+
+	var txs []*dbmodels.Transaction
+	query := db.Model(&txs)
+	query = whereTransactionMatchesAddressFilter(query, *addressID, filter).
+		Order(fmt.Sprintf("transaction.id %s", order)).
+		Limit(int(limit))
+
+	if afterID != nil {
+		comparisonOperator := ">"
+		if order == OrderDescending {
+			comparisonOperator = "<"
+		}
+		query = query.Where(fmt.Sprintf("transaction.id %s ?", comparisonOperator), *afterID)
+	}
+
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// TransactionsByAddressCount returns the total number of transactions sent to or
+// from `address` that pass `filter`. `filter` may be nil.
+func TransactionsByAddressCount(ctx database.Context, address string, filter *AddressTransactionsFilter) (uint64, error) {
 	db, err := ctx.DB()
 	if err != nil {
 		return 0, err
 	}
 
-	var result struct {
-		TransactionCount uint64
-	}
-	_, err = db.QueryOne(&result, `
-SELECT count(*) as transaction_count FROM (
-	SELECT
-		DISTINCT transactions.id
-	FROM
-		transactions
-	where
-	EXISTS
-	(
-		SELECT transaction_id FROM transaction_outputs
-		INNER JOIN addresses ON transaction_outputs.address_id = addresses.id
-		WHERE addresses.address = ?
-	)
-	OR
-	EXISTS
-	(
-		SELECT transaction_inputs.transaction_id FROM transaction_inputs
-		INNER JOIN transaction_outputs ON transaction_inputs.previous_transaction_output_id = transaction_outputs.id
-		INNER JOIN addresses ON transaction_outputs.address_id = addresses.id
-		WHERE addresses.address = ?
-	)
-) as transaction_ids
-`, address, address)
+	addressID, err := addressIDByAddress(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	if addressID == nil {
+		return 0, nil
+	}
 
+	query := db.Model(&dbmodels.Transaction{})
+	count, err := whereTransactionMatchesAddressFilter(query, *addressID, filter).Count()
 	if err != nil {
 		return 0, err
 	}
 
-	return result.TransactionCount, nil
+	return uint64(count), nil
 }
 
 // AcceptedTransactionsByBlockHashes retrieves a list of transactions that were accepted
@@ -182,6 +224,35 @@ func AcceptedTransactionsByBlockID(ctx database.Context, blockID uint64, preload
 	return transactions, nil
 }
 
+// AcceptedTransactionsByChainBlueScoreRange retrieves a list of transactions that were
+// accepted by chain blocks with blue scores higher than `fromBlueScore` and lower than
+// or equal to `toBlueScore`
+// If preloadedFields was provided - preloads the requested fields
+func AcceptedTransactionsByChainBlueScoreRange(ctx database.Context, fromBlueScore uint64, toBlueScore uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*dbmodels.Transaction
+	query := db.Model(&transactions).
+		Where(`transaction.accepting_block_id IN (
+			SELECT blocks.id
+			FROM blocks
+			WHERE blocks.is_chain_block AND blocks.blue_score > ? AND blocks.blue_score <= ?)`,
+			fromBlueScore, toBlueScore).
+		Order("transaction.id ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
 // TransactionsByHashes retrieves all transactions by their `transactionHashes`.
 // If preloadedFields was provided - preloads the requested fields
 func TransactionsByHashes(ctx database.Context, transactionHashes []string,
@@ -295,6 +366,70 @@ func TransactionsByIDs(ctx database.Context, transactionIDs []string, preloadedF
 	return transactions, nil
 }
 
+// TransactionsByHashPrefix retrieves up to `limit` transactions whose hashes start
+// with `hashPrefix`, ordered by their hashes. `hashPrefix` must be lowercase hex.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByHashPrefix(ctx database.Context, hashPrefix string, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	return transactionsByColumnPrefix(ctx, "transaction.transaction_hash", hashPrefix, limit, preloadedFields)
+}
+
+// TransactionsByIDPrefix retrieves up to `limit` transactions whose IDs start
+// with `idPrefix`, ordered by their IDs. `idPrefix` must be lowercase hex.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByIDPrefix(ctx database.Context, idPrefix string, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	return transactionsByColumnPrefix(ctx, "transaction.transaction_id", idPrefix, limit, preloadedFields)
+}
+
+func transactionsByColumnPrefix(ctx database.Context, column string, prefix string, limit uint64,
+	preloadedFields []dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*dbmodels.Transaction
+	query := db.Model(&transactions).
+		Where("? LIKE ?", pg.Ident(column), prefix+"%").
+		Order(column, "transaction.id").
+		Limit(int(limit))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// TransactionsByDBIDs retrieves all transactions by their database IDs.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByDBIDs(ctx database.Context, ids []uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*dbmodels.Transaction
+	query := db.Model(&transactions).
+		Where("transaction.id IN (?)", pg.In(ids))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
 // TransactionsByBlockHash retrieves a list of transactions included by the block
 // with the given blockHash
 func TransactionsByBlockHash(ctx database.Context, blockHash string, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
@@ -336,17 +471,3 @@ func UpdateTransactionAcceptingBlockID(ctx database.Context, transactionID uint6
 
 	return nil
 }
-
-func joinTxInputsTxOutputsAndAddresses(query *orm.Query) *orm.Query {
-	return query.
-		Join("LEFT JOIN transaction_outputs").
-		JoinOn("transaction_outputs.transaction_id = transaction.id").
-		Join("LEFT JOIN addresses AS out_addresses").
-		JoinOn("out_addresses.id = transaction_outputs.address_id").
-		Join("LEFT JOIN transaction_inputs").
-		JoinOn("transaction_inputs.transaction_id = transaction.id").
-		Join("LEFT JOIN transaction_outputs AS inputs_outs").
-		JoinOn("inputs_outs.id = transaction_inputs.previous_transaction_output_id").
-		Join("LEFT JOIN addresses AS in_addresses").
-		JoinOn("in_addresses.id = inputs_outs.address_id")
-}
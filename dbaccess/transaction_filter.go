@@ -0,0 +1,100 @@
+package dbaccess
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/pkg/errors"
+)
+
+// TransactionDirection signifies whether an address
+// receives funds in a transaction or spends funds in it
+type TransactionDirection string
+
+// TransactionDirection constants
+const (
+	TransactionDirectionAny      TransactionDirection = ""
+	TransactionDirectionIncoming TransactionDirection = "incoming"
+	TransactionDirectionOutgoing TransactionDirection = "outgoing"
+)
+
+// StringToTransactionDirection converts a direction string into a TransactionDirection type.
+// Returns an error if passed string is not incoming or outgoing
+func StringToTransactionDirection(directionString string) (TransactionDirection, error) {
+	direction := TransactionDirection(strings.ToLower(directionString))
+	if direction != TransactionDirectionIncoming && direction != TransactionDirectionOutgoing {
+		return TransactionDirectionAny, errors.Errorf("'%s' is not a valid direction", directionString)
+	}
+	return direction, nil
+}
+
+// AddressTransactionsFilter filters the transactions of an address.
+// The blue score and time ranges are inclusive and refer to the
+// accepting block of the transaction, so setting any of them
+// matches only accepted transactions. Nil fields are ignored.
+type AddressTransactionsFilter struct {
+	FromBlueScore *uint64
+	ToBlueScore   *uint64
+	FromTime      *time.Time
+	ToTime        *time.Time
+	Direction     TransactionDirection
+	AcceptedOnly  bool
+}
+
+// whereTransactionMatchesAddressFilter adds conditions to the given transaction query,
+// so that it matches only transactions sent to or from the address with the database
+// ID `addressID` that pass `filter`. `filter` may be nil.
+// The address is matched by ID, rather than by joining the addresses table, so that
+// the transactions of the address are looked up through the address_id index of
+// transaction_outputs instead of checking every transaction in the query's order.
+func whereTransactionMatchesAddressFilter(query *orm.Query, addressID uint64, filter *AddressTransactionsFilter) *orm.Query {
+	const incomingTransactionIDsQuery = `SELECT transaction_outputs.transaction_id FROM transaction_outputs
+		WHERE transaction_outputs.address_id = ?`
+	const outgoingTransactionIDsQuery = `SELECT transaction_inputs.transaction_id FROM transaction_inputs
+		INNER JOIN transaction_outputs ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+		WHERE transaction_outputs.address_id = ?`
+
+	if filter == nil {
+		filter = &AddressTransactionsFilter{}
+	}
+
+	switch filter.Direction {
+	case TransactionDirectionIncoming:
+		query = query.Where("transaction.id IN ("+incomingTransactionIDsQuery+")", addressID)
+	case TransactionDirectionOutgoing:
+		query = query.Where("transaction.id IN ("+outgoingTransactionIDsQuery+")", addressID)
+	default:
+		query = query.Where("transaction.id IN ("+incomingTransactionIDsQuery+" UNION "+outgoingTransactionIDsQuery+")",
+			addressID, addressID)
+	}
+
+	if filter.AcceptedOnly {
+		query = query.Where("transaction.accepting_block_id IS NOT NULL")
+	}
+
+	acceptingBlockConditions := make([]string, 0)
+	acceptingBlockParams := make([]interface{}, 0)
+	if filter.FromBlueScore != nil {
+		acceptingBlockConditions = append(acceptingBlockConditions, "blocks.blue_score >= ?")
+		acceptingBlockParams = append(acceptingBlockParams, *filter.FromBlueScore)
+	}
+	if filter.ToBlueScore != nil {
+		acceptingBlockConditions = append(acceptingBlockConditions, "blocks.blue_score <= ?")
+		acceptingBlockParams = append(acceptingBlockParams, *filter.ToBlueScore)
+	}
+	if filter.FromTime != nil {
+		acceptingBlockConditions = append(acceptingBlockConditions, "blocks.timestamp >= ?")
+		acceptingBlockParams = append(acceptingBlockParams, *filter.FromTime)
+	}
+	if filter.ToTime != nil {
+		acceptingBlockConditions = append(acceptingBlockConditions, "blocks.timestamp <= ?")
+		acceptingBlockParams = append(acceptingBlockParams, *filter.ToTime)
+	}
+	if len(acceptingBlockConditions) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM blocks WHERE blocks.id = transaction.accepting_block_id AND "+
+			strings.Join(acceptingBlockConditions, " AND ")+")", acceptingBlockParams...)
+	}
+
+	return query
+}
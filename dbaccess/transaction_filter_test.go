@@ -0,0 +1,126 @@
+package dbaccess
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+func TestStringToTransactionDirection(t *testing.T) {
+	tests := []struct {
+		directionString string
+		expectedResult  TransactionDirection
+		expectedError   string
+	}{
+		{"incoming", TransactionDirectionIncoming, ""},
+		{"outgoing", TransactionDirectionOutgoing, ""},
+		{"InCoMiNg", TransactionDirectionIncoming, ""},
+		{"OUTGOING", TransactionDirectionOutgoing, ""},
+		{"", TransactionDirectionAny, "'' is not a valid direction"},
+		{"in", TransactionDirectionAny, "'in' is not a valid direction"},
+	}
+
+	for _, test := range tests {
+		result, err := StringToTransactionDirection(test.directionString)
+
+		if result != test.expectedResult {
+			t.Errorf("%s: Expected result '%s' but got '%s'", test.directionString, test.expectedResult, result)
+		}
+
+		errString := ""
+		if err != nil {
+			errString = err.Error()
+		}
+		if test.expectedError != errString {
+			t.Errorf("%s: Expected error '%s' but got '%s'", test.directionString, test.expectedError, errString)
+		}
+	}
+}
+
+func TestWhereTransactionMatchesAddressFilter(t *testing.T) {
+	const (
+		incomingCondition = "SELECT transaction_outputs.transaction_id FROM transaction_outputs"
+		outgoingCondition = "SELECT transaction_inputs.transaction_id FROM transaction_inputs"
+		acceptedCondition = "transaction.accepting_block_id IS NOT NULL"
+		blocksCondition   = "SELECT 1 FROM blocks"
+	)
+
+	fromBlueScore := uint64(10)
+	toBlueScore := uint64(20)
+	fromTime := time.Unix(1000, 0).UTC()
+	toTime := time.Unix(2000, 0).UTC()
+
+	tests := []struct {
+		name               string
+		filter             *AddressTransactionsFilter
+		expectedContains   []string
+		expectedNotContain []string
+	}{
+		{
+			name:               "nil filter",
+			filter:             nil,
+			expectedContains:   []string{incomingCondition, "UNION", outgoingCondition, "address_id = 7"},
+			expectedNotContain: []string{acceptedCondition, blocksCondition},
+		},
+		{
+			name:               "incoming",
+			filter:             &AddressTransactionsFilter{Direction: TransactionDirectionIncoming},
+			expectedContains:   []string{incomingCondition, "address_id = 7"},
+			expectedNotContain: []string{outgoingCondition, "UNION"},
+		},
+		{
+			name:               "outgoing",
+			filter:             &AddressTransactionsFilter{Direction: TransactionDirectionOutgoing},
+			expectedContains:   []string{outgoingCondition, "address_id = 7"},
+			expectedNotContain: []string{incomingCondition, "UNION"},
+		},
+		{
+			name:               "accepted only",
+			filter:             &AddressTransactionsFilter{AcceptedOnly: true},
+			expectedContains:   []string{acceptedCondition},
+			expectedNotContain: []string{blocksCondition},
+		},
+		{
+			name:               "blue score range",
+			filter:             &AddressTransactionsFilter{FromBlueScore: &fromBlueScore, ToBlueScore: &toBlueScore},
+			expectedContains:   []string{blocksCondition, "blocks.blue_score >= 10", "blocks.blue_score <= 20"},
+			expectedNotContain: []string{acceptedCondition, "blocks.timestamp"},
+		},
+		{
+			name:   "time range",
+			filter: &AddressTransactionsFilter{FromTime: &fromTime, ToTime: &toTime},
+			expectedContains: []string{blocksCondition, "blocks.timestamp >= '1970-01-01 00:16:40",
+				"blocks.timestamp <= '1970-01-01 00:33:20"},
+			expectedNotContain: []string{"blocks.blue_score"},
+		},
+		{
+			name:               "open-ended range",
+			filter:             &AddressTransactionsFilter{FromBlueScore: &fromBlueScore},
+			expectedContains:   []string{"blocks.blue_score >= 10"},
+			expectedNotContain: []string{"blocks.blue_score <="},
+		},
+	}
+
+	for _, test := range tests {
+		query := whereTransactionMatchesAddressFilter(orm.NewQuery(nil, &dbmodels.Transaction{}), 7, test.filter)
+		queryBytes, err := query.AppendQuery(orm.NewFormatter(), nil)
+		if err != nil {
+			t.Fatalf("%s: AppendQuery: %s", test.name, err)
+		}
+		queryString := string(queryBytes)
+
+		for _, expected := range test.expectedContains {
+			if !strings.Contains(queryString, expected) {
+				t.Errorf("%s: Expected the query to contain '%s', but got: %s", test.name, expected, queryString)
+			}
+		}
+		for _, unexpected := range test.expectedNotContain {
+			if strings.Contains(queryString, unexpected) {
+				t.Errorf("%s: Expected the query not to contain '%s', but got: %s", test.name, unexpected, queryString)
+			}
+		}
+	}
+}
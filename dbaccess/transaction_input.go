@@ -0,0 +1,34 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// TransactionInputsByPreviousTransactionOutputIDs retrieves all the transaction
+// inputs that spend the transaction outputs with the given database IDs.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionInputsByPreviousTransactionOutputIDs(ctx database.Context, previousTransactionOutputIDs []uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionInput, error) {
+
+	if len(previousTransactionOutputIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var txIns []*dbmodels.TransactionInput
+	query := db.Model(&txIns).
+		Where("transaction_input.previous_transaction_output_id IN (?)", pg.In(previousTransactionOutputIDs))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return txIns, nil
+}
@@ -2,6 +2,7 @@ package dbaccess
 
 import (
 	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kaspad/util/subnetworkid"
 	"github.com/kaspanet/kasparov/database"
 	"github.com/kaspanet/kasparov/dbmodels"
 )
@@ -37,9 +38,105 @@ func UTXOsByAddress(ctx database.Context, address string, preloadedFields ...dbm
 	return transactionOutputs, nil
 }
 
+// AddressBalance is a breakdown of the unspent value held by an address
+type AddressBalance struct {
+	Spendable           uint64
+	PendingConfirmation uint64
+	ImmatureCoinbase    uint64
+}
+
+// AddressBalanceByAddress sums the values of all unspent transaction outputs incoming to `address`.
+// Outputs of transactions that were not accepted yet are counted as pending confirmation, and outputs
+// of coinbase transactions with less than `coinbaseMaturity` confirmations are counted as immature.
+// Outputs that are spent by transactions that were not accepted yet are not counted at all, since
+// is_spent is only set once the spending transaction is accepted, and the change that such a transaction
+// sends back to `address` is already counted as pending confirmation.
+func AddressBalanceByAddress(ctx database.Context, address string, selectedTipBlueScore uint64,
+	coinbaseMaturity uint64) (*AddressBalance, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// A coinbase output is immature if its confirmations (selectedTipBlueScore - acceptingBlueScore + 1)
+	// are less than coinbaseMaturity. This is rearranged below to avoid negative numbers.
+	balance := &AddressBalance{}
+	_, err = db.QueryOne(balance, `
+SELECT
+	COALESCE(SUM(value) FILTER (WHERE NOT is_pending AND NOT is_immature), 0)::BIGINT AS spendable,
+	COALESCE(SUM(value) FILTER (WHERE is_pending), 0)::BIGINT AS pending_confirmation,
+	COALESCE(SUM(value) FILTER (WHERE is_immature), 0)::BIGINT AS immature_coinbase
+FROM (
+	SELECT
+		transaction_outputs.value,
+		transactions.accepting_block_id IS NULL AS is_pending,
+		transactions.accepting_block_id IS NOT NULL
+			AND subnetworks.subnetwork_id = ?
+			AND accepting_blocks.blue_score + ? > ? AS is_immature
+	FROM
+		transaction_outputs
+	INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+	INNER JOIN transactions ON transactions.id = transaction_outputs.transaction_id
+	INNER JOIN subnetworks ON subnetworks.id = transactions.subnetwork_id
+	LEFT JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+	WHERE
+		addresses.address = ?
+		AND transaction_outputs.is_spent = FALSE
+		AND NOT EXISTS (
+			SELECT 1
+			FROM transaction_inputs
+			INNER JOIN transactions AS spending_transactions
+				ON spending_transactions.id = transaction_inputs.transaction_id
+			WHERE
+				transaction_inputs.previous_transaction_output_id = transaction_outputs.id
+				AND spending_transactions.accepting_block_id IS NULL
+		)
+) AS unspent_outputs
+`, subnetworkid.SubnetworkIDCoinbase.String(), coinbaseMaturity, selectedTipBlueScore+1, address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// UTXOsByAddresses retrieves all transaction outputs incoming to any of the given `addresses`.
+// The Address field of every returned output is always preloaded.
+// If preloadedFields was provided - preloads the requested fields
+func UTXOsByAddresses(ctx database.Context, addresses []string, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionOutput, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+	var transactionOutputs []*dbmodels.TransactionOutput
+	query := db.Model(&transactionOutputs).
+		Join("INNER JOIN transactions").
+		JoinOn("transaction_output.transaction_id = transactions.id").
+		Relation(string(dbmodels.TransactionOutputFieldNames.Address)).
+		Where("address.address IN (?)", pg.In(addresses)).
+		Where("transaction_output.is_spent = ?", false).
+		Where("transactions.accepting_block_id IS NOT NULL")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionOutputs, nil
+}
+
 // TransactionOutputsByOutpoints retrieves all transaction outputs referenced by `outpoints`.
+// The Transaction field of every returned output is always preloaded.
 // If preloadedFields was provided - preloads the requested fields
-func TransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint) ([]*dbmodels.TransactionOutput, error) {
+func TransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionOutput, error) {
+
 	db, err := ctx.DB()
 	if err != nil {
 		return nil, err
@@ -52,12 +149,13 @@ func TransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint)
 		var chunk [][]interface{}
 		chunk, offset = outpointsChunk(outpointTuples, offset)
 		var dbPreviousTransactionsOutputsChunk []*dbmodels.TransactionOutput
-		err = db.Model(&dbPreviousTransactionsOutputsChunk).
+		query := db.Model(&dbPreviousTransactionsOutputsChunk).
 			Join("LEFT JOIN transactions").
 			JoinOn("transactions.id = transaction_output.transaction_id").
 			Where("(transactions.transaction_id, transaction_output.index) in (?)", pg.In(chunk)).
-			Relation(string(dbmodels.TransactionOutputFieldNames.Transaction)).
-			Select()
+			Relation(string(dbmodels.TransactionOutputFieldNames.Transaction))
+		query = preloadFields(query, preloadedFields)
+		err = query.Select()
 
 		if err != nil {
 			return nil, err
@@ -0,0 +1,327 @@
+package dbaccess
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// WebhookMatch is a transaction that pays to or spends from
+// an address that a webhook subscription watches
+type WebhookMatch struct {
+	SubscriptionID string
+	Address        string
+	TransactionID  uint64
+}
+
+// InsertWebhookSubscription inserts the given webhook subscription, along with the addresses it watches
+func InsertWebhookSubscription(ctx database.Context, subscription *dbmodels.WebhookSubscription, addresses []string) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	err = db.Insert(subscription)
+	if err != nil {
+		return err
+	}
+
+	subscriptionAddresses := make([]interface{}, len(addresses))
+	for i, address := range addresses {
+		subscriptionAddresses[i] = &dbmodels.WebhookSubscriptionAddress{
+			SubscriptionID: subscription.ID,
+			Address:        address,
+		}
+	}
+	return BulkInsert(ctx, subscriptionAddresses)
+}
+
+// WebhookSubscriptionByID retrieves the webhook subscription with the given ID
+func WebhookSubscriptionByID(ctx database.Context, subscriptionID string) (*dbmodels.WebhookSubscription, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &dbmodels.WebhookSubscription{}
+	err = db.Model(subscription).
+		Where("webhook_subscription.id = ?", subscriptionID).
+		First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// WebhookSubscriptionAddresses retrieves the addresses
+// that the webhook subscription with the given ID watches
+func WebhookSubscriptionAddresses(ctx database.Context, subscriptionID string) ([]string, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	err = db.Model(&dbmodels.WebhookSubscriptionAddress{}).
+		Column("address").
+		Where("subscription_id = ?", subscriptionID).
+		Order("address").
+		Select(&addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// DeleteWebhookSubscription deletes the webhook subscription with the given ID,
+// along with its addresses and deliveries. Returns false if it doesn't exist.
+func DeleteWebhookSubscription(ctx database.Context, subscriptionID string) (bool, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.Model(&dbmodels.WebhookSubscription{}).
+		Where("id = ?", subscriptionID).
+		Delete()
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// WebhookDeliveriesBySubscriptionID retrieves up to `limit` deliveries of the webhook
+// subscription with the given ID, newest first, skipping the first `skip` deliveries
+// If preloadedFields was provided - preloads the requested fields
+func WebhookDeliveriesBySubscriptionID(ctx database.Context, subscriptionID string, skip uint64, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.WebhookDelivery, error) {
+
+	if limit == 0 {
+		return []*dbmodels.WebhookDelivery{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*dbmodels.WebhookDelivery
+	query := db.Model(&deliveries).
+		Where("webhook_delivery.subscription_id = ?", subscriptionID).
+		Order("webhook_delivery.id DESC").
+		Limit(int(limit)).
+		Offset(int(skip))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// watchedTransactionsQuery selects the transactions that pay to or spend from
+// watched addresses, as (subscription_id, address, transaction_id) rows.
+// %[1]s is a condition on transaction_id, which is used to filter the
+// transactions before they're matched against the watched addresses.
+const watchedTransactionsQuery = `SELECT webhook_subscription_addresses.subscription_id,
+		addresses.address,
+		transaction_outputs.transaction_id
+	FROM transaction_outputs
+	INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+	INNER JOIN webhook_subscription_addresses ON webhook_subscription_addresses.address = addresses.address
+	WHERE transaction_outputs.transaction_id %[1]s
+	UNION
+	SELECT webhook_subscription_addresses.subscription_id,
+		addresses.address,
+		transaction_inputs.transaction_id
+	FROM transaction_inputs
+	INNER JOIN transaction_outputs ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+	INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+	INNER JOIN webhook_subscription_addresses ON webhook_subscription_addresses.address = addresses.address
+	WHERE transaction_inputs.transaction_id %[1]s`
+
+// WebhookMatchesByBlockHashes retrieves the transactions of the blocks with the
+// given hashes that pay to or spend from addresses watched by webhook subscriptions
+func WebhookMatchesByBlockHashes(ctx database.Context, blockHashes []string) ([]*WebhookMatch, error) {
+	if len(blockHashes) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*WebhookMatch
+	_, err = db.Query(&matches, fmt.Sprintf(watchedTransactionsQuery, `IN (
+			SELECT transactions_to_blocks.transaction_id
+			FROM transactions_to_blocks
+			INNER JOIN blocks ON blocks.id = transactions_to_blocks.block_id
+			WHERE blocks.block_hash IN (?0))`),
+		pg.In(blockHashes))
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// WebhookMatchesByTransactionIDs retrieves the transactions with the given database
+// IDs that pay to or spend from addresses watched by webhook subscriptions
+func WebhookMatchesByTransactionIDs(ctx database.Context, transactionIDs []uint64) ([]*WebhookMatch, error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*WebhookMatch
+	_, err = db.Query(&matches, fmt.Sprintf(watchedTransactionsQuery, "IN (?0)"), pg.In(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// WebhookMatchesReachingConfirmationThreshold retrieves the accepted transactions
+// that pay to or spend from addresses watched by webhook subscriptions, and reached
+// the confirmation threshold of their subscription when the selected tip blue score
+// rose from `fromBlueScore` to `toBlueScore`, that is, whose accepting chain blocks
+// have blue scores in (fromBlueScore+1-threshold, toBlueScore+1-threshold].
+// Transactions that were already marked as confirmed for their subscription, and
+// transactions accepted before their subscription was created, are skipped.
+func WebhookMatchesReachingConfirmationThreshold(ctx database.Context, fromBlueScore uint64,
+	toBlueScore uint64) ([]*WebhookMatch, error) {
+
+	if fromBlueScore >= toBlueScore {
+		return nil, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions are first filtered by the widest window of all
+	// the subscriptions, so that only the transactions accepted within
+	// it are matched against the watched addresses.
+	var matches []*WebhookMatch
+	_, err = db.Query(&matches, fmt.Sprintf(`SELECT watched.subscription_id, watched.address, watched.transaction_id
+		FROM (%s) AS watched
+		INNER JOIN webhook_subscriptions ON webhook_subscriptions.id = watched.subscription_id
+		INNER JOIN transactions ON transactions.id = watched.transaction_id
+		INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+		WHERE accepting_blocks.blue_score + webhook_subscriptions.confirmation_threshold > ?0 + 1
+			AND accepting_blocks.blue_score + webhook_subscriptions.confirmation_threshold <= ?1 + 1
+			AND accepting_blocks.timestamp >= webhook_subscriptions.created_at
+			AND NOT EXISTS (
+				SELECT 1
+				FROM webhook_confirmations
+				WHERE webhook_confirmations.subscription_id = watched.subscription_id
+					AND webhook_confirmations.transaction_id = watched.transaction_id)
+		ORDER BY watched.transaction_id`, fmt.Sprintf(watchedTransactionsQuery, `IN (
+			SELECT transactions.id
+			FROM transactions
+			INNER JOIN blocks ON blocks.id = transactions.accepting_block_id
+			WHERE blocks.blue_score > ?0 + 1 - (SELECT MAX(confirmation_threshold) FROM webhook_subscriptions)
+				AND blocks.blue_score <= ?1 + 1 - (SELECT MIN(confirmation_threshold) FROM webhook_subscriptions))`)),
+		fromBlueScore, toBlueScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// InsertWebhookConfirmations marks the given matches as confirmed for their subscriptions
+func InsertWebhookConfirmations(ctx database.Context, matches []*WebhookMatch) error {
+	confirmations := make([]interface{}, len(matches))
+	for i, match := range matches {
+		confirmations[i] = &dbmodels.WebhookConfirmation{
+			SubscriptionID: match.SubscriptionID,
+			TransactionID:  match.TransactionID,
+		}
+	}
+	return BulkInsert(ctx, confirmations)
+}
+
+// DeleteWebhookConfirmationsByTransactionIDs removes the confirmed
+// marks of the transactions with the given database IDs
+func DeleteWebhookConfirmationsByTransactionIDs(ctx database.Context, transactionIDs []uint64) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.WebhookConfirmation{}).
+		Where("transaction_id IN (?)", pg.In(transactionIDs)).
+		Delete()
+	return err
+}
+
+// InsertWebhookDeliveries inserts the given webhook deliveries
+func InsertWebhookDeliveries(ctx database.Context, deliveries []*dbmodels.WebhookDelivery) error {
+	objects := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		objects[i] = delivery
+	}
+	return BulkInsert(ctx, objects)
+}
+
+// DueWebhookDeliveries retrieves up to `limit` webhook deliveries
+// whose next attempt is due at `now`, oldest first
+// If preloadedFields was provided - preloads the requested fields
+func DueWebhookDeliveries(ctx database.Context, now time.Time, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.WebhookDelivery, error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*dbmodels.WebhookDelivery
+	query := db.Model(&deliveries).
+		Where("webhook_delivery.next_attempt_at <= ?", now).
+		Order("webhook_delivery.next_attempt_at ASC").
+		Limit(int(limit))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDeliveryAttempt updates the attempt columns
+// of the given webhook delivery in the database
+func UpdateWebhookDeliveryAttempt(ctx database.Context, delivery *dbmodels.WebhookDelivery) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(delivery).
+		Column("next_attempt_at", "attempts", "delivered_at", "last_status_code", "last_error").
+		WherePK().
+		Update()
+	return err
+}
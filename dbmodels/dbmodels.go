@@ -228,9 +228,11 @@ type TransactionInput struct {
 var TransactionInputFieldNames = struct {
 	Transaction               FieldName
 	PreviousTransactionOutput FieldName
+	TransactionAcceptingBlock FieldName
 }{
 	Transaction:               "Transaction",
 	PreviousTransactionOutput: "PreviousTransactionOutput",
+	TransactionAcceptingBlock: "Transaction.AcceptingBlock",
 }
 
 // Address is the database model for the 'addresses' table
@@ -239,6 +241,26 @@ type Address struct {
 	Address string `pg:",use_zero"`
 }
 
+// AddressBalance is the database model for the 'address_balances' table.
+// Balance is the total value of the unspent outputs of accepted transactions
+// that pay to the address, and TransactionCount is the number of accepted
+// transactions that either pay to it or spend its outputs.
+type AddressBalance struct {
+	AddressID          uint64 `pg:",pk"`
+	Address            Address
+	Balance            uint64 `pg:",use_zero"`
+	TransactionCount   uint64 `pg:",use_zero"`
+	FirstSeenBlueScore uint64 `pg:",use_zero"`
+	LastSeenBlueScore  uint64 `pg:",use_zero"`
+}
+
+// AddressBalanceFieldNames is a list of FieldNames for the 'AddressBalance' object
+var AddressBalanceFieldNames = struct {
+	Address FieldName
+}{
+	Address: "Address",
+}
+
 // RawTransaction is the database model for the 'raw_transactions' table
 type RawTransaction struct {
 	TransactionID   uint64 `pg:",use_zero"`
@@ -253,6 +275,250 @@ var RawTransactionFieldNames = struct {
 	Transaction: "Transaction",
 }
 
+// Sync phases of kasparovsyncd, as stored in SyncState.Phase
+const (
+	SyncPhaseBlocks = "blocks"
+	SyncPhaseChain  = "chain"
+	SyncPhaseSynced = "synced"
+)
+
+// SyncStateID is the ID of the only row in the 'sync_state' table
+const SyncStateID = 1
+
+// SyncState is the database model for the 'sync_state' table
+type SyncState struct {
+	tableName             struct{} `pg:"sync_state"`
+	ID                    uint64   `pg:",pk"`
+	Phase                 string   `pg:",use_zero"`
+	LastBlockHash         *string
+	LastChainBlockHash    *string
+	SyncedBlockCount      uint64    `pg:",use_zero"`
+	SyncedChainBlockCount uint64    `pg:",use_zero"`
+	StartedAt             time.Time `pg:",use_zero"`
+	UpdatedAt             time.Time `pg:",use_zero"`
+}
+
+// MempoolTransaction is the database model for the 'mempool_transactions' table
+type MempoolTransaction struct {
+	ID                        uint64    `pg:",pk"`
+	TransactionHash           string    `pg:",use_zero"`
+	TransactionID             string    `pg:",use_zero"`
+	LockTime                  []byte    `pg:",use_zero"`
+	SubnetworkID              string    `pg:",use_zero"`
+	Gas                       uint64    `pg:",use_zero"`
+	PayloadHash               string    `pg:",use_zero"`
+	Payload                   []byte    `pg:",use_zero"`
+	Version                   int32     `pg:",use_zero"`
+	RawTransaction            []byte    `pg:",use_zero"`
+	FirstSeenAt               time.Time `pg:",use_zero"`
+	MempoolTransactionOutputs []MempoolTransactionOutput
+	MempoolTransactionInputs  []MempoolTransactionInput
+}
+
+// MempoolTransactionFieldNames is a list of FieldNames for the 'MempoolTransaction' object
+var MempoolTransactionFieldNames = struct {
+	MempoolTransactionOutputs FieldName
+	MempoolTransactionInputs  FieldName
+}{
+	MempoolTransactionOutputs: "MempoolTransactionOutputs",
+	MempoolTransactionInputs:  "MempoolTransactionInputs",
+}
+
+// MempoolTransactionRecommendedPreloadedFields is a list of fields recommended
+// to preload when getting mempool transactions
+var MempoolTransactionRecommendedPreloadedFields = []FieldName{
+	MempoolTransactionFieldNames.MempoolTransactionOutputs,
+	MempoolTransactionFieldNames.MempoolTransactionInputs,
+}
+
+// MempoolTransactionOutput is the database model for the 'mempool_transaction_outputs' table
+type MempoolTransactionOutput struct {
+	ID                   uint64 `pg:",pk"`
+	MempoolTransactionID uint64 `pg:",use_zero"`
+	MempoolTransaction   MempoolTransaction
+	Index                uint32 `pg:",use_zero"`
+	Value                uint64 `pg:",use_zero"`
+	ScriptPubKey         []byte `pg:",use_zero"`
+	Address              *string
+}
+
+// MempoolTransactionInput is the database model for the 'mempool_transaction_inputs' table.
+// Address and Value are those of the previous transaction output, and are only set
+// if the previous transaction output is known.
+type MempoolTransactionInput struct {
+	ID                             uint64 `pg:",pk"`
+	MempoolTransactionID           uint64 `pg:",use_zero"`
+	Index                          uint32 `pg:",use_zero"`
+	PreviousTransactionID          string `pg:",use_zero"`
+	PreviousTransactionOutputIndex uint32 `pg:",use_zero"`
+	SignatureScript                []byte `pg:",use_zero"`
+	Sequence                       []byte `pg:",use_zero"`
+	Address                        *string
+	Value                          *uint64
+}
+
+// SubmittedTransaction is the database model for the 'submitted_transactions' table
+type SubmittedTransaction struct {
+	ID              uint64 `pg:",pk"`
+	TransactionID   string `pg:",use_zero"`
+	TransactionHash string `pg:",use_zero"`
+	RawTransaction  []byte `pg:",use_zero"`
+	RejectionError  *string
+	BroadcastCount  uint64    `pg:",use_zero"`
+	SubmittedAt     time.Time `pg:",use_zero"`
+	LastBroadcastAt time.Time `pg:",use_zero"`
+}
+
+// OutboxNotification is the database model for the 'notification_outbox' table.
+// Sequence is the number of notifications that were published to Topic up to
+// and including this one.
+type OutboxNotification struct {
+	tableName struct{}  `pg:"notification_outbox"`
+	ID        uint64    `pg:",pk"`
+	Topic     string    `pg:",use_zero"`
+	Sequence  uint64    `pg:",use_zero"`
+	Payload   []byte    `pg:",use_zero"`
+	CreatedAt time.Time `pg:",use_zero"`
+	SentAt    *time.Time
+	Attempts  uint64 `pg:",use_zero"`
+	LastError *string
+}
+
+// WebhookSubscription is the database model for the 'webhook_subscriptions' table
+type WebhookSubscription struct {
+	ID                    string    `pg:",pk"`
+	URL                   string    `pg:",use_zero"`
+	Secret                string    `pg:",use_zero"`
+	APIKey                string    `pg:",use_zero"`
+	ConfirmationThreshold uint64    `pg:",use_zero"`
+	CreatedAt             time.Time `pg:",use_zero"`
+}
+
+// WebhookSubscriptionAddress is the database model for the 'webhook_subscription_addresses' table
+type WebhookSubscriptionAddress struct {
+	SubscriptionID string `pg:",use_zero"`
+	Address        string `pg:",use_zero"`
+}
+
+// WebhookConfirmation is the database model for the 'webhook_confirmations' table
+type WebhookConfirmation struct {
+	SubscriptionID string `pg:",use_zero"`
+	TransactionID  uint64 `pg:",use_zero"`
+}
+
+// WebhookDelivery is the database model for the 'webhook_deliveries' table.
+// NextAttemptAt is nil once the delivery succeeded or was given up on.
+type WebhookDelivery struct {
+	ID             uint64 `pg:",pk"`
+	SubscriptionID string `pg:",use_zero"`
+	Subscription   *WebhookSubscription
+	Event          string `pg:",use_zero"`
+	Address        string `pg:",use_zero"`
+	TransactionID  uint64 `pg:",use_zero"`
+	Transaction    *Transaction
+	Payload        []byte    `pg:",use_zero"`
+	CreatedAt      time.Time `pg:",use_zero"`
+	NextAttemptAt  *time.Time
+	Attempts       uint64 `pg:",use_zero"`
+	DeliveredAt    *time.Time
+	LastStatusCode *int
+	LastError      *string
+}
+
+// WebhookDeliveryFieldNames is a list of FieldNames for the 'WebhookDelivery' object
+var WebhookDeliveryFieldNames = struct {
+	Subscription,
+	Transaction FieldName
+}{
+	Subscription: "Subscription",
+	Transaction:  "Transaction",
+}
+
+// ConfirmationNotificationThreshold is the database model for the
+// 'confirmation_notification_thresholds' table. NotifiedBlueScore is the highest
+// blue score of a chain block whose accepted transactions were notified as having
+// reached Threshold confirmations.
+type ConfirmationNotificationThreshold struct {
+	Threshold         uint64 `pg:",pk"`
+	NotifiedBlueScore uint64 `pg:",use_zero"`
+}
+
+// ChainChange is the database model for the 'chain_changes' table.
+// Depth is the number of chain blocks that were removed in the change.
+// ChainChangeTransactions only holds the transactions that were
+// unaccepted in the change. The accepted transactions aren't
+// stored, since they're derived from the added chain blocks.
+type ChainChange struct {
+	ID                      uint64    `pg:",pk"`
+	ChangedAt               time.Time `pg:",use_zero"`
+	Depth                   uint64    `pg:",use_zero"`
+	ChainChangeBlocks       []ChainChangeBlock
+	ChainChangeTransactions []ChainChangeTransaction
+}
+
+// ChainChangeFieldNames is a list of FieldNames for the 'ChainChange' object
+var ChainChangeFieldNames = struct {
+	ChainChangeBlocks,
+	BlocksBlock,
+	ChainChangeTransactions,
+	TransactionsTransaction FieldName
+}{
+	ChainChangeBlocks:       "ChainChangeBlocks",
+	BlocksBlock:             "ChainChangeBlocks.Block",
+	ChainChangeTransactions: "ChainChangeTransactions",
+	TransactionsTransaction: "ChainChangeTransactions.Transaction",
+}
+
+// ChainChangeRecommendedPreloadedFields is a list of fields recommended to preload when getting chain changes
+var ChainChangeRecommendedPreloadedFields = []FieldName{
+	ChainChangeFieldNames.BlocksBlock,
+	ChainChangeFieldNames.TransactionsTransaction,
+}
+
+// ChainChangeBlock is the database model for the 'chain_change_blocks' table.
+// Each row is a block that was either added to or removed from
+// the selected parent chain in a chain change.
+type ChainChangeBlock struct {
+	ChainChangeID uint64 `pg:",use_zero"`
+	ChainChange   ChainChange
+	BlockID       uint64 `pg:",use_zero"`
+	Block         Block
+	IsAdded       bool   `pg:",use_zero"`
+	Index         uint32 `pg:",use_zero"`
+}
+
+// ChainChangeTransaction is the database model for the 'chain_change_transactions' table.
+// Each row is a transaction that was unaccepted in a chain change, along with the chain
+// block with BlockID that had accepted it.
+type ChainChangeTransaction struct {
+	ChainChangeID uint64 `pg:",use_zero"`
+	ChainChange   ChainChange
+	TransactionID uint64 `pg:",use_zero"`
+	Transaction   Transaction
+	BlockID       uint64 `pg:",use_zero"`
+	Block         Block
+}
+
+// ChainChangeBlockFieldNames is a list of FieldNames for the 'ChainChangeBlock' object
+var ChainChangeBlockFieldNames = struct {
+	ChainChange,
+	Block FieldName
+}{
+	ChainChange: "ChainChange",
+	Block:       "Block",
+}
+
+// ChainChangeTransactionFieldNames is a list of FieldNames for the 'ChainChangeTransaction' object
+var ChainChangeTransactionFieldNames = struct {
+	ChainChange,
+	Transaction,
+	Block FieldName
+}{
+	ChainChange: "ChainChange",
+	Transaction: "Transaction",
+	Block:       "Block",
+}
+
 // PrefixFieldNames returns the given fields prefixed
 // with the given prefix and a dot.
 func PrefixFieldNames(prefix FieldName, fields []FieldName) []FieldName {
@@ -38,10 +38,34 @@ func TestFieldNames(t *testing.T) {
 			fieldNames: &TransactionInputFieldNames,
 			model:      &TransactionInput{},
 		},
+		{
+			fieldNames: &AddressBalanceFieldNames,
+			model:      &AddressBalance{},
+		},
 		{
 			fieldNames: &RawTransactionFieldNames,
 			model:      &RawTransaction{},
 		},
+		{
+			fieldNames: &MempoolTransactionFieldNames,
+			model:      &MempoolTransaction{},
+		},
+		{
+			fieldNames: &WebhookDeliveryFieldNames,
+			model:      &WebhookDelivery{},
+		},
+		{
+			fieldNames: &ChainChangeFieldNames,
+			model:      &ChainChange{},
+		},
+		{
+			fieldNames: &ChainChangeBlockFieldNames,
+			model:      &ChainChangeBlock{},
+		},
+		{
+			fieldNames: &ChainChangeTransactionFieldNames,
+			model:      &ChainChangeTransaction{},
+		},
 	}
 	for _, test := range tests {
 		values := structFieldNamesToStringsSlice(test.fieldNames)
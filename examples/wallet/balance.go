@@ -7,19 +7,13 @@ import (
 )
 
 func balance(conf *balanceConfig) error {
-	utxos, err := getUTXOs(conf.KasparovAddress, conf.Address)
+	balance, err := getBalance(conf.KasparovAddress, conf.Address)
 	if err != nil {
 		return err
 	}
 
-	var availableBalance, pendingBalance uint64
-	for _, utxo := range utxos {
-		if utxo.IsSpendable != nil && *utxo.IsSpendable {
-			availableBalance += utxo.Value
-		} else {
-			pendingBalance += utxo.Value
-		}
-	}
+	availableBalance := balance.Spendable
+	pendingBalance := balance.Total - balance.Spendable
 
 	fmt.Printf("Balance:\t\tKAS %f\n", float64(availableBalance)/util.SompiPerKaspa)
 	if pendingBalance > 0 {
@@ -15,6 +15,7 @@ import (
 
 const (
 	getUTXOsEndpoint        = "utxos/address"
+	getBalanceEndpoint      = "balance/address"
 	sendTransactionEndpoint = "transaction"
 )
 
@@ -54,6 +55,30 @@ func getUTXOs(kasparovAddress string, address string) ([]*apimodels.TransactionO
 	return utxos, nil
 }
 
+func getBalance(kasparovAddress string, address string) (*apimodels.AddressBalanceResponse, error) {
+	requestURL, err := resourceURL(kasparovAddress, getBalanceEndpoint, address)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.Get(requestURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting balance from Kasparov server")
+	}
+	body, err := readResponse(response)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading balance from Kasparov server response")
+	}
+
+	balance := &apimodels.AddressBalanceResponse{}
+
+	err = json.Unmarshal(body, balance)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling balance")
+	}
+
+	return balance, nil
+}
+
 func readResponse(response *http.Response) (body []byte, err error) {
 	defer response.Body.Close()
 
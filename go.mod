@@ -8,10 +8,15 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.7.1
 	github.com/gorilla/handlers v1.4.2
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/websocket v1.4.2
+	github.com/graphql-go/graphql v0.8.1
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/kaspanet/go-secp256k1 v0.0.2
 	github.com/kaspanet/kaspad v0.6.2
+	github.com/nats-io/nats.go v1.10.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.7.0
+	github.com/segmentio/kafka-go v0.3.5
 )
 
 replace github.com/kaspanet/kaspad => ../kaspad
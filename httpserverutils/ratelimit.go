@@ -0,0 +1,217 @@
+package httpserverutils
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// APIKeyHeader is the header in which clients send their API key
+	APIKeyHeader = "X-API-Key"
+
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	retryAfterHeader         = "Retry-After"
+
+	forwardedForHeader = "X-Forwarded-For"
+
+	apiKeyBucketPrefix = "key:"
+	ipBucketPrefix     = "ip:"
+
+	// bucketCleanupInterval is the interval in which
+	// buckets that are full again are removed
+	bucketCleanupInterval = time.Minute
+)
+
+// RateLimitTier defines the rate in which requests are allowed,
+// and how many requests may be sent in a burst
+type RateLimitTier struct {
+	// RequestsPerSecond is the rate in which the bucket is
+	// refilled. A zero rate means that requests are not limited.
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func (tier RateLimitTier) isUnlimited() bool {
+	return tier.RequestsPerSecond == 0
+}
+
+// tokenBucket is a token bucket that is refilled lazily
+// every time a token is taken from it
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func (bucket *tokenBucket) refill(tier RateLimitTier, now time.Time) {
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens = math.Min(float64(tier.Burst), bucket.tokens+elapsed*tier.RequestsPerSecond)
+	bucket.lastRefill = now
+}
+
+// take takes a token from the bucket if there's one available, and
+// returns whether it succeeded, along with the number of remaining tokens
+// and the time until the bucket is full again.
+func (bucket *tokenBucket) take(tier RateLimitTier, now time.Time) (ok bool, remaining int, reset time.Duration) {
+	bucket.refill(tier, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		ok = true
+	}
+	reset = secondsToDuration((float64(tier.Burst) - bucket.tokens) / tier.RequestsPerSecond)
+	return ok, int(bucket.tokens), reset
+}
+
+// retryAfter returns the time until the next token is available
+func (bucket *tokenBucket) retryAfter(tier RateLimitTier) time.Duration {
+	return secondsToDuration((1 - bucket.tokens) / tier.RequestsPerSecond)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// RateLimiter limits the rate of requests with token buckets. Requests
+// that carry a known API key are limited by the tier of that key, and
+// all other requests are limited per client IP.
+type RateLimiter struct {
+	ipTier          RateLimitTier
+	apiKeyTiers     map[string]RateLimitTier
+	trustForwarding bool
+
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	mtx         sync.Mutex
+
+	now func() time.Time
+}
+
+// NewRateLimiter returns a new RateLimiter. ipTier is the tier of requests
+// without an API key, and apiKeyTiers maps every known API key to its tier.
+// If trustForwarding is set, the client IP is taken from the X-Forwarded-For
+// header, which should only be done behind a trusted reverse proxy.
+func NewRateLimiter(ipTier RateLimitTier, apiKeyTiers map[string]RateLimitTier, trustForwarding bool) *RateLimiter {
+	return &RateLimiter{
+		ipTier:          ipTier,
+		apiKeyTiers:     apiKeyTiers,
+		trustForwarding: trustForwarding,
+		buckets:         make(map[string]*tokenBucket),
+		now:             time.Now,
+	}
+}
+
+// Middleware is a middleware that rejects requests that exceed
+// their rate limit with http.StatusTooManyRequests, and reports
+// the state of the rate limit in X-RateLimit-* headers.
+func (limiter *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ToServerContext(r.Context())
+
+		bucketKey, tier, err := limiter.resolveBucket(r)
+		if err != nil {
+			SendErr(ctx, w, err)
+			return
+		}
+		if tier.isUnlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ok, remaining, reset, retryAfter := limiter.take(bucketKey, tier)
+		w.Header().Set(rateLimitLimitHeader, strconv.Itoa(tier.Burst))
+		w.Header().Set(rateLimitRemainingHeader, strconv.Itoa(remaining))
+		w.Header().Set(rateLimitResetHeader, strconv.Itoa(durationToCeilSeconds(reset)))
+		if !ok {
+			w.Header().Set(retryAfterHeader, strconv.Itoa(durationToCeilSeconds(retryAfter)))
+			SendErr(ctx, w, NewHandlerError(http.StatusTooManyRequests,
+				errors.New("rate limit exceeded")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func durationToCeilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+// resolveBucket returns the key of the bucket of the given request and its tier
+func (limiter *RateLimiter) resolveBucket(r *http.Request) (string, RateLimitTier, error) {
+	apiKey := r.Header.Get(APIKeyHeader)
+	if apiKey != "" {
+		tier, ok := limiter.apiKeyTiers[apiKey]
+		if !ok {
+			return "", RateLimitTier{}, NewHandlerError(http.StatusUnauthorized,
+				errors.New("invalid API key"))
+		}
+		return apiKeyBucketPrefix + apiKey, tier, nil
+	}
+	return ipBucketPrefix + limiter.clientIP(r), limiter.ipTier, nil
+}
+
+func (limiter *RateLimiter) clientIP(r *http.Request) string {
+	if limiter.trustForwarding {
+		forwardedFor := r.Header.Get(forwardedForHeader)
+		if forwardedFor != "" {
+			// The first address is the one of the client
+			return strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (limiter *RateLimiter) take(bucketKey string, tier RateLimitTier) (
+	ok bool, remaining int, reset time.Duration, retryAfter time.Duration) {
+
+	limiter.mtx.Lock()
+	defer limiter.mtx.Unlock()
+
+	now := limiter.now()
+	limiter.cleanupBuckets(now)
+
+	bucket, exists := limiter.buckets[bucketKey]
+	if !exists {
+		bucket = &tokenBucket{
+			tokens:     float64(tier.Burst),
+			lastRefill: now,
+		}
+		limiter.buckets[bucketKey] = bucket
+	}
+	ok, remaining, reset = bucket.take(tier, now)
+	if !ok {
+		retryAfter = bucket.retryAfter(tier)
+	}
+	return ok, remaining, reset, retryAfter
+}
+
+// cleanupBuckets removes all the buckets that are full again, since
+// they are equivalent to new buckets. This function is not safe for
+// concurrent access.
+func (limiter *RateLimiter) cleanupBuckets(now time.Time) {
+	if now.Sub(limiter.lastCleanup) < bucketCleanupInterval {
+		return
+	}
+	limiter.lastCleanup = now
+	for bucketKey, bucket := range limiter.buckets {
+		tier := limiter.ipTier
+		if strings.HasPrefix(bucketKey, apiKeyBucketPrefix) {
+			tier = limiter.apiKeyTiers[strings.TrimPrefix(bucketKey, apiKeyBucketPrefix)]
+		}
+		bucket.refill(tier, now)
+		if bucket.tokens >= float64(tier.Burst) {
+			delete(limiter.buckets, bucketKey)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package httpserverutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(apiKeyTiers map[string]RateLimitTier) (http.Handler, *time.Time) {
+	limiter := NewRateLimiter(RateLimitTier{RequestsPerSecond: 1, Burst: 2}, apiKeyTiers, false)
+	now := time.Unix(1600000000, 0)
+	limiter.now = func() time.Time { return now }
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return AddRequestMetadataMiddleware(limiter.Middleware(okHandler)), &now
+}
+
+func sendTestRequest(handler http.Handler, remoteAddr string, apiKey string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", "/blocks", nil)
+	request.RemoteAddr = remoteAddr
+	if apiKey != "" {
+		request.Header.Set(APIKeyHeader, apiKey)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimiterPerIP(t *testing.T) {
+	handler, now := newTestRateLimiter(nil)
+
+	tests := []struct {
+		remoteAddr        string
+		advance           time.Duration
+		expectedCode      int
+		expectedRemaining string
+	}{
+		{"10.0.0.1:1000", 0, http.StatusOK, "1"},
+		{"10.0.0.1:1001", 0, http.StatusOK, "0"},
+		{"10.0.0.1:1002", 0, http.StatusTooManyRequests, "0"},
+		{"10.0.0.2:1000", 0, http.StatusOK, "1"},
+		{"10.0.0.1:1003", time.Second, http.StatusOK, "0"},
+		{"10.0.0.1:1004", 0, http.StatusTooManyRequests, "0"},
+	}
+
+	for i, test := range tests {
+		*now = now.Add(test.advance)
+		recorder := sendTestRequest(handler, test.remoteAddr, "")
+		if recorder.Code != test.expectedCode {
+			t.Errorf("request %d: Expected code %d but got %d", i, test.expectedCode, recorder.Code)
+		}
+		if limit := recorder.Header().Get(rateLimitLimitHeader); limit != "2" {
+			t.Errorf("request %d: Expected limit 2 but got '%s'", i, limit)
+		}
+		if remaining := recorder.Header().Get(rateLimitRemainingHeader); remaining != test.expectedRemaining {
+			t.Errorf("request %d: Expected remaining %s but got '%s'", i, test.expectedRemaining, remaining)
+		}
+		if test.expectedCode == http.StatusTooManyRequests {
+			if retryAfter := recorder.Header().Get(retryAfterHeader); retryAfter != "1" {
+				t.Errorf("request %d: Expected Retry-After 1 but got '%s'", i, retryAfter)
+			}
+		}
+	}
+}
+
+func TestRateLimiterAPIKeys(t *testing.T) {
+	handler, _ := newTestRateLimiter(map[string]RateLimitTier{
+		"premium":   {RequestsPerSecond: 10, Burst: 3},
+		"unlimited": {},
+	})
+
+	for i := 0; i < 3; i++ {
+		recorder := sendTestRequest(handler, "10.0.0.1:1000", "premium")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("premium request %d: Expected code %d but got %d", i, http.StatusOK, recorder.Code)
+		}
+	}
+	recorder := sendTestRequest(handler, "10.0.0.1:1000", "premium")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected code %d after exhausting the premium tier but got %d", http.StatusTooManyRequests, recorder.Code)
+	}
+
+	// The IP bucket should not be affected by requests with API keys
+	recorder = sendTestRequest(handler, "10.0.0.1:1000", "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected code %d for a request without an API key but got %d", http.StatusOK, recorder.Code)
+	}
+
+	for i := 0; i < 10; i++ {
+		recorder = sendTestRequest(handler, "10.0.0.1:1000", "unlimited")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("unlimited request %d: Expected code %d but got %d", i, http.StatusOK, recorder.Code)
+		}
+		if limit := recorder.Header().Get(rateLimitLimitHeader); limit != "" {
+			t.Fatalf("unlimited request %d: Expected no limit header but got '%s'", i, limit)
+		}
+	}
+
+	recorder = sendTestRequest(handler, "10.0.0.1:1000", "unknown")
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected code %d for an unknown API key but got %d", http.StatusUnauthorized, recorder.Code)
+	}
+}
@@ -14,6 +14,11 @@ import (
 	"github.com/kaspanet/kaspad/util"
 )
 
+// AlreadyHaveTransactionMessage is part of the error message that the node
+// returns for transactions that are already in its mempool, which isn't a
+// rejection of the transaction
+const AlreadyHaveTransactionMessage = "already have transaction"
+
 // Client represents a connection to the JSON-RPC API of a full node
 type Client struct {
 	*rpcclient.Client
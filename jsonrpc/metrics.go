@@ -0,0 +1,86 @@
+package jsonrpc
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/domainmessage"
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/metrics"
+)
+
+// The methods below shadow the RPC calls of the embedded
+// rpcclient.Client that Kasparov makes, so that all of
+// them are recorded in the RPC metrics.
+
+// observeRPCCall records the RPC call `method` that started at `start`
+// and failed if *err isn't nil. It's meant to be deferred.
+func observeRPCCall(method string, start time.Time, err *error) {
+	metrics.ObserveRPCCall(method, start, *err)
+}
+
+// GetSelectedTipHash calls getSelectedTipHash
+func (c *Client) GetSelectedTipHash() (hash *daghash.Hash, err error) {
+	defer observeRPCCall("getSelectedTipHash", time.Now(), &err)
+	return c.Client.GetSelectedTipHash()
+}
+
+// GetBlock calls getBlock
+func (c *Client) GetBlock(blockHash *daghash.Hash, subnetworkID *string) (block *domainmessage.MsgBlock, err error) {
+	defer observeRPCCall("getBlock", time.Now(), &err)
+	return c.Client.GetBlock(blockHash, subnetworkID)
+}
+
+// GetBlocks calls getBlocks
+func (c *Client) GetBlocks(includeRawBlockData bool, includeVerboseBlockData bool, lowHash *string) (
+	result *rpcmodel.GetBlocksResult, err error) {
+
+	defer observeRPCCall("getBlocks", time.Now(), &err)
+	return c.Client.GetBlocks(includeRawBlockData, includeVerboseBlockData, lowHash)
+}
+
+// GetBlockVerboseTx calls getBlock with verbose transactions
+func (c *Client) GetBlockVerboseTx(blockHash *daghash.Hash, subnetworkID *string) (
+	result *rpcmodel.GetBlockVerboseResult, err error) {
+
+	defer observeRPCCall("getBlockVerboseTx", time.Now(), &err)
+	return c.Client.GetBlockVerboseTx(blockHash, subnetworkID)
+}
+
+// GetBlockCount calls getBlockCount
+func (c *Client) GetBlockCount() (count int64, err error) {
+	defer observeRPCCall("getBlockCount", time.Now(), &err)
+	return c.Client.GetBlockCount()
+}
+
+// GetChainFromBlock calls getChainFromBlock
+func (c *Client) GetChainFromBlock(includeBlocks bool, startHash *string) (
+	result *rpcmodel.GetChainFromBlockResult, err error) {
+
+	defer observeRPCCall("getChainFromBlock", time.Now(), &err)
+	return c.Client.GetChainFromBlock(includeBlocks, startHash)
+}
+
+// GetMempoolEntry calls getMempoolEntry
+func (c *Client) GetMempoolEntry(txHash string) (result *rpcmodel.GetMempoolEntryResult, err error) {
+	defer observeRPCCall("getMempoolEntry", time.Now(), &err)
+	return c.Client.GetMempoolEntry(txHash)
+}
+
+// GetRawMempool calls getRawMempool
+func (c *Client) GetRawMempool() (txHashes []*daghash.Hash, err error) {
+	defer observeRPCCall("getRawMempool", time.Now(), &err)
+	return c.Client.GetRawMempool()
+}
+
+// GetSubnetwork calls getSubnetwork
+func (c *Client) GetSubnetwork(subnetworkID string) (result *rpcmodel.GetSubnetworkResult, err error) {
+	defer observeRPCCall("getSubnetwork", time.Now(), &err)
+	return c.Client.GetSubnetwork(subnetworkID)
+}
+
+// SendRawTransaction calls sendRawTransaction
+func (c *Client) SendRawTransaction(tx *domainmessage.MsgTx, allowHighFees bool) (txID *daghash.TxID, err error) {
+	defer observeRPCCall("sendRawTransaction", time.Now(), &err)
+	return c.Client.SendRawTransaction(tx, allowHighFees)
+}
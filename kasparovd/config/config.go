@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/kaspanet/kaspad/util"
 	"github.com/kaspanet/kasparov/config"
+	"github.com/kaspanet/kasparov/httpserverutils"
 	"github.com/kaspanet/kasparov/version"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -19,9 +23,17 @@ const (
 
 var (
 	// Default configuration options
-	defaultLogDir     = util.AppDataDir("kasparovd", false)
-	defaultHTTPListen = "0.0.0.0:8080"
-	activeConfig      *Config
+	defaultLogDir                = util.AppDataDir("kasparovd", false)
+	defaultHTTPListen            = "0.0.0.0:8080"
+	defaultFeeEstimateWindow     = uint64(1000)
+	defaultFeeEstimateMinSamples = uint64(50)
+	defaultReadyMaxBlueScoreLag  = uint64(100)
+	defaultReadyMaxTimeLag       = 10 * time.Minute
+	defaultRateLimit             = float64(10)
+	defaultRateLimitBurst        = 20
+	defaultRebroadcastMaxAge     = 24 * time.Hour
+	defaultGraphQLMaxComplexity  = 1000
+	activeConfig                 *Config
 )
 
 // ActiveConfig returns the active configuration struct
@@ -31,14 +43,42 @@ func ActiveConfig() *Config {
 
 // Config defines the configuration options for the API server.
 type Config struct {
-	HTTPListen string `long:"listen" description:"HTTP address to listen on (default: 0.0.0.0:8080)"`
+	HTTPListen            string        `long:"listen" description:"HTTP address to listen on (default: 0.0.0.0:8080)"`
+	FeeEstimateWindow     uint64        `long:"feeestimatewindow" description:"Number of blue score units to look back on when estimating fees (default: 1000)"`
+	FeeEstimateMinSamples uint64        `long:"feeestimateminsamples" description:"Minimum number of transactions required to estimate fees instead of returning fallback values (default: 50)"`
+	ReadyMaxBlueScoreLag  uint64        `long:"readymaxbluescorelag" description:"Maximum difference between the blue scores of the selected tips of the node and of the database for the server to be reported as ready (default: 100)"`
+	ReadyMaxTimeLag       time.Duration `long:"readymaxtimelag" description:"Maximum difference between the timestamps of the selected tips of the node and of the database for the server to be reported as ready (default: 10m)"`
+	RateLimit             float64       `long:"ratelimit" description:"Number of requests per second allowed from every client IP. 0 disables rate limiting by IP (default: 10)"`
+	RateLimitBurst        int           `long:"ratelimitburst" description:"Number of requests every client IP is allowed to send in a burst (default: 20)"`
+	RateLimitTrustProxy   bool          `long:"ratelimittrustproxy" description:"Identify client IPs by the X-Forwarded-For header. Only use this behind a trusted reverse proxy"`
+	RateLimitTiers        []string      `long:"ratelimittier" description:"Rate limit tier for API keys in the form <name>:<requests per second>:<burst>. A rate of 0 means unlimited. May be specified multiple times"`
+	APIKeys               []string      `long:"apikey" description:"API key and the name of its rate limit tier in the form <key>:<tier>. Clients send it in the X-API-Key header. May be specified multiple times"`
+	RebroadcastInterval   time.Duration `long:"rebroadcastinterval" description:"Interval in which transactions that were submitted through Kasparov and were not included in a block are rebroadcast. Transactions are not rebroadcast if not set"`
+	RebroadcastMaxAge     time.Duration `long:"rebroadcastmaxage" description:"Stop rebroadcasting transactions this long after they were submitted (default: 24h)"`
+	MetricsListen         string        `long:"metricslisten" description:"HTTP address to serve Prometheus metrics on. Metrics are not served if not set" required:"false"`
+	GraphQLMaxComplexity  int           `long:"graphqlmaxcomplexity" description:"Maximum estimated cost of a GraphQL query. Every selected field costs 1, and the cost of list fields is multiplied by their limit (default: 1000)"`
 	config.KasparovFlags
+
+	apiKeyTiers map[string]httpserverutils.RateLimitTier
+}
+
+// APIKeyTiers returns the rate limit tier of every configured API key
+func (cfg *Config) APIKeyTiers() map[string]httpserverutils.RateLimitTier {
+	return cfg.apiKeyTiers
 }
 
 // Parse parses the CLI arguments and returns a config struct.
 func Parse() error {
 	activeConfig = &Config{
-		HTTPListen: defaultHTTPListen,
+		HTTPListen:            defaultHTTPListen,
+		FeeEstimateWindow:     defaultFeeEstimateWindow,
+		FeeEstimateMinSamples: defaultFeeEstimateMinSamples,
+		ReadyMaxBlueScoreLag:  defaultReadyMaxBlueScoreLag,
+		ReadyMaxTimeLag:       defaultReadyMaxTimeLag,
+		RateLimit:             defaultRateLimit,
+		RateLimitBurst:        defaultRateLimitBurst,
+		RebroadcastMaxAge:     defaultRebroadcastMaxAge,
+		GraphQLMaxComplexity:  defaultGraphQLMaxComplexity,
 	}
 	parser := flags.NewParser(activeConfig, flags.HelpFlag)
 
@@ -61,5 +101,74 @@ func Parse() error {
 		return err
 	}
 
+	if activeConfig.FeeEstimateWindow == 0 {
+		return errors.New("--feeestimatewindow must be greater than 0")
+	}
+
+	if activeConfig.ReadyMaxTimeLag <= 0 {
+		return errors.New("--readymaxtimelag must be greater than 0")
+	}
+
+	if activeConfig.RateLimit < 0 {
+		return errors.New("--ratelimit must not be negative")
+	}
+	if activeConfig.RateLimit > 0 && activeConfig.RateLimitBurst < 1 {
+		return errors.New("--ratelimitburst must be at least 1")
+	}
+
+	if activeConfig.RebroadcastInterval < 0 {
+		return errors.New("--rebroadcastinterval must not be negative")
+	}
+
+	if activeConfig.RebroadcastMaxAge < 0 {
+		return errors.New("--rebroadcastmaxage must not be negative")
+	}
+
+	if activeConfig.GraphQLMaxComplexity < 1 {
+		return errors.New("--graphqlmaxcomplexity must be at least 1")
+	}
+
+	activeConfig.apiKeyTiers, err = parseAPIKeyTiers(activeConfig.RateLimitTiers, activeConfig.APIKeys)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+func parseAPIKeyTiers(tierStrings []string, apiKeyStrings []string) (map[string]httpserverutils.RateLimitTier, error) {
+	tiers := make(map[string]httpserverutils.RateLimitTier, len(tierStrings))
+	for _, tierString := range tierStrings {
+		parts := strings.Split(tierString, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, errors.Errorf("--ratelimittier '%s' is not in the form <name>:<requests per second>:<burst>", tierString)
+		}
+		requestsPerSecond, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || requestsPerSecond < 0 {
+			return nil, errors.Errorf("--ratelimittier '%s' has an invalid rate", tierString)
+		}
+		burst, err := strconv.Atoi(parts[2])
+		if err != nil || (requestsPerSecond > 0 && burst < 1) {
+			return nil, errors.Errorf("--ratelimittier '%s' has an invalid burst", tierString)
+		}
+		tiers[parts[0]] = httpserverutils.RateLimitTier{
+			RequestsPerSecond: requestsPerSecond,
+			Burst:             burst,
+		}
+	}
+
+	apiKeyTiers := make(map[string]httpserverutils.RateLimitTier, len(apiKeyStrings))
+	for _, apiKeyString := range apiKeyStrings {
+		separatorIndex := strings.LastIndex(apiKeyString, ":")
+		if separatorIndex <= 0 {
+			return nil, errors.New("--apikey must be in the form <key>:<tier>")
+		}
+		apiKey, tierName := apiKeyString[:separatorIndex], apiKeyString[separatorIndex+1:]
+		tier, ok := tiers[tierName]
+		if !ok {
+			return nil, errors.Errorf("--apikey refers to an unknown rate limit tier '%s'", tierName)
+		}
+		apiKeyTiers[apiKey] = tier
+	}
+	return apiKeyTiers, nil
+}
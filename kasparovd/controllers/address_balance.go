@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+const maxGetTopAddressesLimit = 100
+
+// GetTopAddressesHandler returns the addresses with the highest balances
+// or transaction counts, depending on `by`, highest first.
+func GetTopAddressesHandler(by string, skip, limit int64) (interface{}, error) {
+	if limit > maxGetTopAddressesLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTopAddressesLimit))
+	}
+
+	if skip < 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.New("skip lower than 0 was requested"))
+	}
+
+	ranking, err := dbaccess.StringToAddressRanking(by)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	addressBalances, err := dbaccess.TopAddressBalances(database.NoTx(), ranking, uint64(skip), uint64(limit),
+		dbmodels.AddressBalanceFieldNames.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	topAddressResponses := make([]*apimodels.TopAddressResponse, len(addressBalances))
+	for i, addressBalance := range addressBalances {
+		topAddressResponses[i] = &apimodels.TopAddressResponse{
+			Address:            addressBalance.Address.Address,
+			Balance:            addressBalance.Balance,
+			TransactionCount:   addressBalance.TransactionCount,
+			FirstSeenBlueScore: addressBalance.FirstSeenBlueScore,
+			LastSeenBlueScore:  addressBalance.LastSeenBlueScore,
+		}
+	}
+	return topAddressResponses, nil
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+)
+
+// GetAddressBalanceHandler returns the balance of a certain address, broken down
+// into spendable, pending-confirmation and immature-coinbase amounts.
+func GetAddressBalanceHandler(address string) (interface{}, error) {
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	activeNetParams := config.ActiveConfig().NetParams()
+
+	balance, err := dbaccess.AddressBalanceByAddress(database.NoTx(), address, selectedTipBlueScore,
+		activeNetParams.BlockCoinbaseMaturity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apimodels.AddressBalanceResponse{
+		Total:               balance.Spendable + balance.PendingConfirmation + balance.ImmatureCoinbase,
+		Spendable:           balance.Spendable,
+		PendingConfirmation: balance.PendingConfirmation,
+		ImmatureCoinbase:    balance.ImmatureCoinbase,
+	}, nil
+}
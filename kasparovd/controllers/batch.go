@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+// maxBatchSize is the maximum number of items that
+// may be looked up in a single batch request.
+const maxBatchSize = 100
+
+// Per-item errors that are reported in batch lookup responses
+const (
+	invalidTxIDItemError      = "the given txid is not a hex-encoded transaction ID"
+	invalidBlockHashItemError = "the given block hash is not a hex-encoded block hash"
+	invalidAddressItemError   = "the given address is not a well-formatted P2PKH or P2SH address"
+)
+
+func unmarshalBatchRequest(requestBody []byte, request interface{}) error {
+	err := json.Unmarshal(requestBody, request)
+	if err != nil {
+		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"the request body is not json-formatted")
+	}
+	return nil
+}
+
+func validateBatchSize(size int) error {
+	if size > maxBatchSize || size < 1 {
+		return httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("a batch of more than %d or less than 1 items was requested", maxBatchSize))
+	}
+	return nil
+}
+
+// lowercaseHexHashes lowercases the given hex-encoded hashes in place,
+// since hashes are stored lowercase in the database
+func lowercaseHexHashes(hashes []string) {
+	for i, hash := range hashes {
+		hashes[i] = strings.ToLower(hash)
+	}
+}
+
+func isValidHexHash(hash string, size int) bool {
+	bytes, err := hex.DecodeString(hash)
+	return err == nil && len(bytes) == size
+}
+
+// PostTransactionsByIDsHandler looks up all the transactions with the given IDs.
+// The results are returned in the same order as the requested IDs.
+func PostTransactionsByIDsHandler(requestBody []byte) (interface{}, error) {
+	request := &apimodels.TransactionIDsRequest{}
+	err := unmarshalBatchRequest(requestBody, request)
+	if err != nil {
+		return nil, err
+	}
+	err = validateBatchSize(len(request.TransactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	lowercaseHexHashes(request.TransactionIDs)
+
+	validTxIDs := make([]string, 0, len(request.TransactionIDs))
+	for _, txID := range request.TransactionIDs {
+		if isValidHexHash(txID, daghash.TxIDSize) {
+			validTxIDs = append(validTxIDs, txID)
+		}
+	}
+
+	txs, err := dbaccess.TransactionsByIDs(database.NoTx(), validTxIDs, dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same transaction may be included in several blocks. Prefer
+	// the accepted instance of a transaction if there's one.
+	txsByID := make(map[string]*dbmodels.Transaction, len(txs))
+	for _, tx := range txs {
+		if existingTx, ok := txsByID[tx.TransactionID]; !ok || existingTx.AcceptingBlock == nil {
+			txsByID[tx.TransactionID] = tx
+		}
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	foundTxs := make([]*dbmodels.Transaction, 0, len(txsByID))
+	foundTxResponses := make([]*apimodels.TransactionResponse, 0, len(txsByID))
+	responses := make([]*apimodels.TransactionLookupResponse, len(request.TransactionIDs))
+	for i, txID := range request.TransactionIDs {
+		responses[i] = &apimodels.TransactionLookupResponse{TransactionID: txID}
+		if !isValidHexHash(txID, daghash.TxIDSize) {
+			responses[i].Error = invalidTxIDItemError
+			continue
+		}
+		if tx, ok := txsByID[txID]; ok {
+			responses[i].Found = true
+			responses[i].Transaction = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+			foundTxs = append(foundTxs, tx)
+			foundTxResponses = append(foundTxResponses, responses[i].Transaction)
+		}
+	}
+	err = addSpendersToTxResponses(foundTxs, foundTxResponses)
+	if err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// PostBlocksByHashesHandler looks up all the blocks with the given hashes.
+// The results are returned in the same order as the requested hashes.
+func PostBlocksByHashesHandler(requestBody []byte) (interface{}, error) {
+	request := &apimodels.BlockHashesRequest{}
+	err := unmarshalBatchRequest(requestBody, request)
+	if err != nil {
+		return nil, err
+	}
+	err = validateBatchSize(len(request.BlockHashes))
+	if err != nil {
+		return nil, err
+	}
+	lowercaseHexHashes(request.BlockHashes)
+
+	validBlockHashes := make([]string, 0, len(request.BlockHashes))
+	for _, blockHash := range request.BlockHashes {
+		if isValidHexHash(blockHash, daghash.HashSize) {
+			validBlockHashes = append(validBlockHashes, blockHash)
+		}
+	}
+
+	blocks, err := dbaccess.BlocksByHashes(database.NoTx(), validBlockHashes, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	blocksByHash := make(map[string]*dbmodels.Block, len(blocks))
+	for _, block := range blocks {
+		blocksByHash[block.BlockHash] = block
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*apimodels.BlockLookupResponse, len(request.BlockHashes))
+	for i, blockHash := range request.BlockHashes {
+		responses[i] = &apimodels.BlockLookupResponse{BlockHash: blockHash}
+		if !isValidHexHash(blockHash, daghash.HashSize) {
+			responses[i].Error = invalidBlockHashItemError
+			continue
+		}
+		if block, ok := blocksByHash[blockHash]; ok {
+			responses[i].Found = true
+			responses[i].Block = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+		}
+	}
+	return responses, nil
+}
+
+// PostUTXOsByAddressesHandler looks up the UTXOs of all the given addresses.
+// The results are returned in the same order as the requested addresses. An
+// address is reported as not found if it has no UTXOs.
+func PostUTXOsByAddressesHandler(requestBody []byte) (interface{}, error) {
+	request := &apimodels.AddressesRequest{}
+	err := unmarshalBatchRequest(requestBody, request)
+	if err != nil {
+		return nil, err
+	}
+	err = validateBatchSize(len(request.Addresses))
+	if err != nil {
+		return nil, err
+	}
+
+	validAddresses := make([]string, 0, len(request.Addresses))
+	for _, address := range request.Addresses {
+		if validateAddress(address) == nil {
+			validAddresses = append(validAddresses, address)
+		}
+	}
+
+	transactionOutputs, err := dbaccess.UTXOsByAddresses(database.NoTx(), validAddresses,
+		dbmodels.TransactionOutputFieldNames.TransactionAcceptingBlock,
+		dbmodels.TransactionOutputFieldNames.TransactionSubnetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	activeNetParams := config.ActiveConfig().NetParams()
+
+	utxoResponses := make([]*apimodels.TransactionOutputResponse, len(transactionOutputs))
+	utxosByAddress := make(map[string][]*apimodels.TransactionOutputResponse)
+	for i, transactionOutput := range transactionOutputs {
+		utxoResponses[i], err = apimodels.ConvertTransactionOutputModelToTransactionOutputResponse(transactionOutput,
+			selectedTipBlueScore, activeNetParams, false)
+		if err != nil {
+			return nil, err
+		}
+		address := transactionOutput.Address.Address
+		utxosByAddress[address] = append(utxosByAddress[address], utxoResponses[i])
+	}
+	err = addSpendersToOutputResponses(transactionOutputs, utxoResponses)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*apimodels.UTXOsLookupResponse, len(request.Addresses))
+	for i, address := range request.Addresses {
+		responses[i] = &apimodels.UTXOsLookupResponse{Address: address}
+		if validateAddress(address) != nil {
+			responses[i].Error = invalidAddressItemError
+			continue
+		}
+		if utxos, ok := utxosByAddress[address]; ok {
+			responses[i].Found = true
+			responses[i].UTXOs = utxos
+		}
+	}
+	return responses, nil
+}
+
+// PostTransactionOutputsByOutpointsHandler looks up all the transaction outputs
+// referenced by the given outpoints, whether they are spent or not. The results
+// are returned in the same order as the requested outpoints.
+func PostTransactionOutputsByOutpointsHandler(requestBody []byte) (interface{}, error) {
+	request := &apimodels.OutpointsRequest{}
+	err := unmarshalBatchRequest(requestBody, request)
+	if err != nil {
+		return nil, err
+	}
+	err = validateBatchSize(len(request.Outpoints))
+	if err != nil {
+		return nil, err
+	}
+	for _, outpoint := range request.Outpoints {
+		if outpoint != nil {
+			outpoint.TransactionID = strings.ToLower(outpoint.TransactionID)
+		}
+	}
+
+	validOutpoints := make([]*dbaccess.Outpoint, 0, len(request.Outpoints))
+	for _, outpoint := range request.Outpoints {
+		if outpoint != nil && isValidHexHash(outpoint.TransactionID, daghash.TxIDSize) {
+			validOutpoints = append(validOutpoints, &dbaccess.Outpoint{
+				TransactionID: outpoint.TransactionID,
+				Index:         outpoint.Index,
+			})
+		}
+	}
+
+	transactionOutputs, err := dbaccess.TransactionOutputsByOutpoints(database.NoTx(), validOutpoints,
+		dbmodels.TransactionOutputFieldNames.TransactionAcceptingBlock,
+		dbmodels.TransactionOutputFieldNames.TransactionSubnetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same transaction may be included in several blocks. Prefer
+	// the output of the accepted instance of a transaction if there's one.
+	outputsByOutpoint := make(map[dbaccess.Outpoint]*dbmodels.TransactionOutput, len(transactionOutputs))
+	for _, transactionOutput := range transactionOutputs {
+		outpoint := dbaccess.Outpoint{
+			TransactionID: transactionOutput.Transaction.TransactionID,
+			Index:         transactionOutput.Index,
+		}
+		if existingOutput, ok := outputsByOutpoint[outpoint]; !ok || existingOutput.Transaction.AcceptingBlock == nil {
+			outputsByOutpoint[outpoint] = transactionOutput
+		}
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	activeNetParams := config.ActiveConfig().NetParams()
+
+	foundOutputs := make([]*dbmodels.TransactionOutput, 0, len(outputsByOutpoint))
+	foundOutputResponses := make([]*apimodels.TransactionOutputResponse, 0, len(outputsByOutpoint))
+	responses := make([]*apimodels.TransactionOutputLookupResponse, len(request.Outpoints))
+	for i, outpoint := range request.Outpoints {
+		if outpoint == nil {
+			responses[i] = &apimodels.TransactionOutputLookupResponse{Error: invalidTxIDItemError}
+			continue
+		}
+		responses[i] = &apimodels.TransactionOutputLookupResponse{
+			TransactionID: outpoint.TransactionID,
+			Index:         outpoint.Index,
+		}
+		if !isValidHexHash(outpoint.TransactionID, daghash.TxIDSize) {
+			responses[i].Error = invalidTxIDItemError
+			continue
+		}
+		transactionOutput, ok := outputsByOutpoint[dbaccess.Outpoint{
+			TransactionID: outpoint.TransactionID,
+			Index:         outpoint.Index,
+		}]
+		if !ok {
+			continue
+		}
+		responses[i].Found = true
+		responses[i].Output, err = apimodels.ConvertTransactionOutputModelToTransactionOutputResponse(transactionOutput,
+			selectedTipBlueScore, activeNetParams, transactionOutput.IsSpent)
+		if err != nil {
+			return nil, err
+		}
+		foundOutputs = append(foundOutputs, transactionOutput)
+		foundOutputResponses = append(foundOutputResponses, responses[i].Output)
+	}
+	err = addSpendersToOutputResponses(foundOutputs, foundOutputResponses)
+	if err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
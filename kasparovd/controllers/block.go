@@ -41,8 +41,10 @@ func GetBlockByHashHandler(blockHash string) (interface{}, error) {
 	return blockRes, nil
 }
 
-// GetBlocksHandler searches for all blocks
-func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error) {
+// GetBlocksHandler searches for up to `limit` blocks ordered by blue score and ID.
+// If cursorString isn't empty, the page starts right after the given cursor.
+// Otherwise, the first `skip` blocks are skipped.
+func GetBlocksHandler(orderString string, skip int64, cursorString string, limit int64) (interface{}, error) {
 	if limit > maxGetBlocksLimit || limit < 1 {
 		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
 			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetBlocksLimit))
@@ -58,7 +60,19 @@ func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error
 		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
 	}
 
-	blocks, err := dbaccess.Blocks(database.NoTx(), order, uint64(skip), uint64(limit), dbmodels.BlockRecommendedPreloadedFields...)
+	cursor, err := decodeBlockCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*dbmodels.Block
+	if cursor != nil {
+		blocks, err = dbaccess.BlocksByCursor(database.NoTx(), order, cursor, uint64(limit),
+			dbmodels.BlockRecommendedPreloadedFields...)
+	} else {
+		blocks, err = dbaccess.Blocks(database.NoTx(), order, uint64(skip), uint64(limit),
+			dbmodels.BlockRecommendedPreloadedFields...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -68,12 +82,21 @@ func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error
 		return nil, err
 	}
 
-	blockResponses := make([]*apimodels.BlockResponse, len(blocks))
+	blocksResponse := &apimodels.BlocksResponse{
+		Blocks: make([]*apimodels.BlockResponse, len(blocks)),
+	}
 	for i, block := range blocks {
-		blockResponses[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+		blocksResponse.Blocks[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+	}
+	if int64(len(blocks)) == limit {
+		lastBlock := blocks[len(blocks)-1]
+		blocksResponse.NextCursor = encodeBlockCursor(&dbaccess.BlockCursor{
+			BlueScore: lastBlock.BlueScore,
+			ID:        lastBlock.ID,
+		})
 	}
 
-	return blockResponses, nil
+	return blocksResponse, nil
 }
 
 // GetBlockCountHandler returns the total number of blocks.
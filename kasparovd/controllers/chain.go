@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxChainBlocksBetweenHashes is the maximum number of chain
+	// blocks that may be returned for the chain between two blocks
+	maxChainBlocksBetweenHashes = 1000
+
+	// maxChainChangesRemovedBlocks is the maximum number of chain blocks that may
+	// have been removed from the selected parent chain since the block requested
+	// in chain changes. Deeper reorganizations are rejected.
+	maxChainChangesRemovedBlocks = 1000
+)
+
+// GetChainBlocksHandler returns up to `limit` selected parent chain blocks, ordered by blue
+// score, whose blue score is between `fromBlueScore` and `toBlueScore` (inclusive). Nil bounds
+// are ignored.
+func GetChainBlocksHandler(orderString string, fromBlueScore *uint64, toBlueScore *uint64, limit int64) (interface{}, error) {
+	if limit > maxGetBlocksLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetBlocksLimit))
+	}
+
+	order, err := dbaccess.StringToOrder(orderString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	blocks, err := dbaccess.ChainBlocksByBlueScore(database.NoTx(), order, fromBlueScore, toBlueScore, uint64(limit),
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	return convertBlockModelsToBlockResponses(blocks)
+}
+
+// GetChainBlockAtBlueScoreHandler returns the selected parent chain block with the
+// highest blue score that is not greater than the given blue score.
+func GetChainBlockAtBlueScoreHandler(blueScoreString string) (interface{}, error) {
+	blueScore, err := strconv.ParseUint(blueScoreString, 10, 64)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error parsing blue score"),
+			"The given blue score is not a valid uint64")
+	}
+
+	block, err := dbaccess.ChainBlockAtBlueScore(database.NoTx(), blueScore, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("no chain block was found at the given blue score"))
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	return apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore), nil
+}
+
+// GetChainBetweenHashesHandler returns the selected parent chain blocks from the block
+// with hash `fromBlockHash` to the block with hash `toBlockHash`, inclusive, ordered by
+// blue score. Both blocks must be in the selected parent chain.
+func GetChainBetweenHashesHandler(fromBlockHash string, toBlockHash string) (interface{}, error) {
+	fromBlock, err := chainBlockByHash(fromBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := chainBlockByHash(toBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock.BlueScore > toBlock.BlueScore {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the 'from' block has a higher blue score than the 'to' block"))
+	}
+
+	blocks, err := dbaccess.ChainBlocksByBlueScore(database.NoTx(), dbaccess.OrderAscending,
+		&fromBlock.BlueScore, &toBlock.BlueScore, maxChainBlocksBetweenHashes+1,
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) > maxChainBlocksBetweenHashes {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("there are more than %d chain blocks between the given blocks", maxChainBlocksBetweenHashes))
+	}
+	return convertBlockModelsToBlockResponses(blocks)
+}
+
+// GetChainChangesHandler returns the changes in the selected parent chain since the
+// block with the given hash was the selected tip: the chain blocks that were removed
+// since, ordered from that block downwards, and up to `limit` chain blocks that were
+// added since, ordered by blue score.
+func GetChainChangesHandler(blockHash string, limit int64) (interface{}, error) {
+	if limit > maxGetBlocksLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetBlocksLimit))
+	}
+
+	block, err := blockByHashOrNotFound(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// The chain that ended at the given block is its selected parent
+	// chain, so the removed blocks are the blocks on the way from it
+	// to the first block that is still in the selected parent chain.
+	removedBlockHashes := make([]string, 0)
+	for !block.IsChainBlock {
+		if len(removedBlockHashes) == maxChainChangesRemovedBlocks {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("more than %d chain blocks were removed since the given block", maxChainChangesRemovedBlocks))
+		}
+		removedBlockHashes = append(removedBlockHashes, block.BlockHash)
+		selectedParent, err := dbaccess.SelectedParent(database.NoTx(), block.ID)
+		if err != nil {
+			return nil, err
+		}
+		if selectedParent == nil {
+			return nil, errors.Errorf("block %s is not in the selected parent chain and has no parents", block.BlockHash)
+		}
+		block = selectedParent
+	}
+
+	fromBlueScore := block.BlueScore + 1
+	addedBlocks, err := dbaccess.ChainBlocksByBlueScore(database.NoTx(), dbaccess.OrderAscending,
+		&fromBlueScore, nil, uint64(limit)+1, dbmodels.BlockFieldNames.AcceptedBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &apimodels.ChainChangesResponse{
+		SelectedParentChainNotification: apimodels.SelectedParentChainNotification{
+			AddedChainBlocks:   make([]*apimodels.AddedChainBlock, 0, len(addedBlocks)),
+			RemovedBlockHashes: removedBlockHashes,
+		},
+	}
+	if int64(len(addedBlocks)) > limit {
+		addedBlocks = addedBlocks[:limit]
+		response.HasMore = true
+	}
+	for _, addedBlock := range addedBlocks {
+		acceptedBlockHashes := make([]string, len(addedBlock.AcceptedBlocks))
+		for i, acceptedBlock := range addedBlock.AcceptedBlocks {
+			acceptedBlockHashes[i] = acceptedBlock.BlockHash
+		}
+		response.AddedChainBlocks = append(response.AddedChainBlocks, &apimodels.AddedChainBlock{
+			Hash:                addedBlock.BlockHash,
+			AcceptedBlockHashes: acceptedBlockHashes,
+		})
+	}
+	return response, nil
+}
+
+func chainBlockByHash(blockHash string) (*dbmodels.Block, error) {
+	block, err := blockByHashOrNotFound(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !block.IsChainBlock {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("block %s is not in the selected parent chain", blockHash))
+	}
+	return block, nil
+}
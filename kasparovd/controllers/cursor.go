@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+// Cursors are opaque to clients. Internally, they are the base64 encoding
+// of a cursor type prefix followed by the values the cursor is keyed on.
+const (
+	blockCursorPrefix       = "block"
+	transactionCursorPrefix = "transaction"
+	cursorSeparator         = ":"
+)
+
+func encodeCursor(prefix string, values ...uint64) string {
+	parts := make([]string, len(values)+1)
+	parts[0] = prefix
+	for i, value := range values {
+		parts[i+1] = strconv.FormatUint(value, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, cursorSeparator)))
+}
+
+func decodeCursor(cursor string, prefix string, valueCount int) ([]uint64, error) {
+	invalidCursorError := httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+		errors.New("the given cursor is invalid"))
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalidCursorError
+	}
+
+	parts := strings.Split(string(decoded), cursorSeparator)
+	if len(parts) != valueCount+1 || parts[0] != prefix {
+		return nil, invalidCursorError
+	}
+
+	values := make([]uint64, valueCount)
+	for i, part := range parts[1:] {
+		values[i], err = strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, invalidCursorError
+		}
+	}
+	return values, nil
+}
+
+func encodeBlockCursor(cursor *dbaccess.BlockCursor) string {
+	return encodeCursor(blockCursorPrefix, cursor.BlueScore, cursor.ID)
+}
+
+// decodeBlockCursor decodes a block cursor. An empty cursor
+// is decoded to nil, which signifies the first page.
+func decodeBlockCursor(cursor string) (*dbaccess.BlockCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	values, err := decodeCursor(cursor, blockCursorPrefix, 2)
+	if err != nil {
+		return nil, err
+	}
+	return &dbaccess.BlockCursor{
+		BlueScore: values[0],
+		ID:        values[1],
+	}, nil
+}
+
+func encodeTransactionCursor(transactionID uint64) string {
+	return encodeCursor(transactionCursorPrefix, transactionID)
+}
+
+// decodeTransactionCursor decodes a transaction cursor into a transaction
+// database ID. An empty cursor is decoded to nil, which signifies the first page.
+func decodeTransactionCursor(cursor string) (*uint64, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	values, err := decodeCursor(cursor, transactionCursorPrefix, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &values[0], nil
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/kaspanet/kasparov/dbaccess"
+)
+
+func TestBlockCursor(t *testing.T) {
+	cursor := &dbaccess.BlockCursor{BlueScore: 12345, ID: 678}
+	decoded, err := decodeBlockCursor(encodeBlockCursor(cursor))
+	if err != nil {
+		t.Fatalf("decodeBlockCursor: %s", err)
+	}
+	if *decoded != *cursor {
+		t.Errorf("Expected cursor %+v but got %+v", cursor, decoded)
+	}
+
+	decoded, err = decodeBlockCursor("")
+	if err != nil {
+		t.Fatalf("decodeBlockCursor: %s", err)
+	}
+	if decoded != nil {
+		t.Errorf("Expected an empty cursor to be decoded to nil but got %+v", decoded)
+	}
+}
+
+func TestTransactionCursor(t *testing.T) {
+	decoded, err := decodeTransactionCursor(encodeTransactionCursor(98765))
+	if err != nil {
+		t.Fatalf("decodeTransactionCursor: %s", err)
+	}
+	if *decoded != 98765 {
+		t.Errorf("Expected transaction ID 98765 but got %d", *decoded)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	tests := []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("block:1")),
+		base64.RawURLEncoding.EncodeToString([]byte("block:1:2:3")),
+		base64.RawURLEncoding.EncodeToString([]byte("block:a:2")),
+		base64.RawURLEncoding.EncodeToString([]byte("block:-1:2")),
+		encodeTransactionCursor(1),
+	}
+
+	for _, cursor := range tests {
+		_, err := decodeBlockCursor(cursor)
+		if err == nil {
+			t.Errorf("%s: Expected an error but got none", cursor)
+		}
+	}
+
+	_, err := decodeTransactionCursor(encodeBlockCursor(&dbaccess.BlockCursor{BlueScore: 1, ID: 2}))
+	if err == nil {
+		t.Errorf("Expected an error when decoding a block cursor as a transaction cursor")
+	}
+}
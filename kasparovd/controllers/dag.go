@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxDAGTraversalDepth is the maximum distance from the
+	// requested block that ancestors and descendants are fetched to
+	maxDAGTraversalDepth = 50
+
+	// maxDAGTraversalBlocks is the maximum number of blocks returned
+	// by a single ancestors or descendants request
+	maxDAGTraversalBlocks = 1000
+)
+
+const (
+	dagDirectionAncestors   = "ancestors"
+	dagDirectionDescendants = "descendants"
+)
+
+// GetBlockChildrenHandler returns all the blocks that have the block with the given hash as a parent.
+func GetBlockChildrenHandler(blockHash string) (interface{}, error) {
+	block, err := blockByHashOrNotFound(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := dbaccess.ChildBlocks(database.NoTx(), block.ID, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	return convertBlockModelsToBlockResponses(children)
+}
+
+// GetBlockSelectedParentHandler returns the selected parent of the block with the given hash.
+func GetBlockSelectedParentHandler(blockHash string) (interface{}, error) {
+	block, err := blockByHashOrNotFound(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedParent, err := dbaccess.SelectedParent(database.NoTx(), block.ID, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if selectedParent == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("the block with the given block hash has no parents"))
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	return apimodels.ConvertBlockModelToBlockResponse(selectedParent, selectedTipBlueScore), nil
+}
+
+// GetBlockAcceptedBlocksHandler returns all the blocks that were accepted by the block with the given hash.
+// Only selected parent chain blocks accept blocks.
+func GetBlockAcceptedBlocksHandler(blockHash string) (interface{}, error) {
+	block, err := blockByHashOrNotFound(blockHash, dbmodels.BlockFieldNames.AcceptedBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedBlockHashes := make([]string, len(block.AcceptedBlocks))
+	for i, acceptedBlock := range block.AcceptedBlocks {
+		acceptedBlockHashes[i] = acceptedBlock.BlockHash
+	}
+	acceptedBlocks, err := dbaccess.BlocksByHashes(database.NoTx(), acceptedBlockHashes,
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	return convertBlockModelsToBlockResponses(acceptedBlocks)
+}
+
+// GetBlockAcceptingBlockHandler returns the block that accepted the block with the given hash.
+func GetBlockAcceptingBlockHandler(blockHash string) (interface{}, error) {
+	block, err := blockByHashOrNotFound(blockHash, dbmodels.BlockFieldNames.AcceptingBlock)
+	if err != nil {
+		return nil, err
+	}
+	if block.AcceptingBlock == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("the block with the given block hash was not accepted"))
+	}
+
+	acceptingBlock, err := dbaccess.BlockByHash(database.NoTx(), block.AcceptingBlock.BlockHash,
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if acceptingBlock == nil {
+		return nil, errors.Errorf("accepting block %s was not found", block.AcceptingBlock.BlockHash)
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	return apimodels.ConvertBlockModelToBlockResponse(acceptingBlock, selectedTipBlueScore), nil
+}
+
+// GetBlockAncestorsHandler returns the ancestors of the block with the given hash,
+// grouped by their distance from it, up to the given depth.
+func GetBlockAncestorsHandler(blockHash string, depth int64) (interface{}, error) {
+	return getBlockNeighborhood(blockHash, depth, dagDirectionAncestors, dbaccess.ParentBlockIDs)
+}
+
+// GetBlockDescendantsHandler returns the descendants of the block with the given hash,
+// grouped by their distance from it, up to the given depth.
+func GetBlockDescendantsHandler(blockHash string, depth int64) (interface{}, error) {
+	return getBlockNeighborhood(blockHash, depth, dagDirectionDescendants, dbaccess.ChildBlockIDs)
+}
+
+// getBlockNeighborhood runs a breadth-first search from the block with the given hash,
+// using `neighborIDs` to find the next level of blocks. The search stops after
+// `depth` levels, or once maxDAGTraversalBlocks blocks were found.
+func getBlockNeighborhood(blockHash string, depth int64, direction string,
+	neighborIDs func(ctx database.Context, blockIDs []uint64) ([]uint64, error)) (interface{}, error) {
+
+	if depth > maxDAGTraversalDepth || depth < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("depth higher than %d or lower than 1 was requested", maxDAGTraversalDepth))
+	}
+
+	block, err := blockByHashOrNotFound(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[uint64]struct{}{block.ID: {}}
+	levelIDs := make([][]uint64, 0, depth)
+	frontier := []uint64{block.ID}
+	blockCount := 0
+	truncated := false
+	for len(levelIDs) < int(depth) && len(frontier) > 0 && !truncated {
+		candidateIDs, err := neighborIDs(database.NoTx(), frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = make([]uint64, 0, len(candidateIDs))
+		for _, candidateID := range candidateIDs {
+			if _, ok := visited[candidateID]; ok {
+				continue
+			}
+			if blockCount == maxDAGTraversalBlocks {
+				truncated = true
+				break
+			}
+			visited[candidateID] = struct{}{}
+			frontier = append(frontier, candidateID)
+			blockCount++
+		}
+		if len(frontier) > 0 {
+			levelIDs = append(levelIDs, frontier)
+		}
+	}
+
+	allIDs := make([]uint64, 0, blockCount)
+	for _, ids := range levelIDs {
+		allIDs = append(allIDs, ids...)
+	}
+	blocks, err := dbaccess.BlocksByIDs(database.NoTx(), allIDs, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	blockResponsesByID := make(map[uint64]*apimodels.BlockResponse, len(blocks))
+	for _, neighbor := range blocks {
+		blockResponsesByID[neighbor.ID] = apimodels.ConvertBlockModelToBlockResponse(neighbor, selectedTipBlueScore)
+	}
+
+	response := &apimodels.BlockNeighborhoodResponse{
+		BlockHash: blockHash,
+		Direction: direction,
+		Levels:    make([][]*apimodels.BlockResponse, len(levelIDs)),
+		Truncated: truncated,
+	}
+	for i, ids := range levelIDs {
+		response.Levels[i] = make([]*apimodels.BlockResponse, 0, len(ids))
+		for _, id := range ids {
+			if blockResponse, ok := blockResponsesByID[id]; ok {
+				response.Levels[i] = append(response.Levels[i], blockResponse)
+			}
+		}
+	}
+	return response, nil
+}
+
+// blockByHashOrNotFound validates the given block hash and fetches its block, returning
+// a handler error if the hash is invalid or if there's no block with that hash.
+func blockByHashOrNotFound(blockHash string, preloadedFields ...dbmodels.FieldName) (*dbmodels.Block, error) {
+	if bytes, err := hex.DecodeString(blockHash); err != nil || len(bytes) != daghash.HashSize {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("the given block hash is not a hex-encoded %d-byte hash", daghash.HashSize))
+	}
+
+	block, err := dbaccess.BlockByHash(database.NoTx(), blockHash, preloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no block with the given block hash was found"))
+	}
+	return block, nil
+}
+
+func convertBlockModelsToBlockResponses(blocks []*dbmodels.Block) ([]*apimodels.BlockResponse, error) {
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	blockResponses := make([]*apimodels.BlockResponse, len(blocks))
+	for i, block := range blocks {
+		blockResponses[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+	}
+	return blockResponses, nil
+}
@@ -1,13 +1,154 @@
 package controllers
 
-import "github.com/kaspanet/kasparov/apimodels"
+import (
+	"math"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/jsonrpc"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+)
+
+// Fee rates, in sompi per gram of mass, that are returned
+// when there isn't enough data to estimate fees.
+const (
+	fallbackHighPriorityFeeRate   = 3
+	fallbackNormalPriorityFeeRate = 2
+	fallbackLowPriorityFeeRate    = 1
+)
+
+// minFeeRate is the lowest fee rate that is ever suggested. It
+// matches kaspad's default minimum relay fee of 1000 sompi per
+// kilogram of mass.
+const minFeeRate = 1
+
+// congestionThresholdBlocks is the number of blocks worth of transactions
+// that have to be waiting in the mempool for it to be considered congested.
+const congestionThresholdBlocks = 10
+
+// Fee estimate confidence levels
+const (
+	feeEstimateConfidenceNone   = "none"
+	feeEstimateConfidenceLow    = "low"
+	feeEstimateConfidenceMedium = "medium"
+	feeEstimateConfidenceHigh   = "high"
+)
+
+// feeRatePercentiles are the fee rate percentiles that are queried
+// from the database. Their indexes are referred to by the
+// feeRatePercentileIndexes below.
+var feeRatePercentiles = []float64{0.1, 0.5, 0.75, 0.9, 0.95}
+
+type feeRatePercentileIndexes struct {
+	high, normal, low int
+}
+
+var (
+	regularFeeRatePercentileIndexes   = feeRatePercentileIndexes{high: 3, normal: 1, low: 0}
+	congestedFeeRatePercentileIndexes = feeRatePercentileIndexes{high: 4, normal: 2, low: 1}
+)
 
 // GetFeeEstimatesHandler returns the fee estimates for different priorities
 // for accepting a transaction in the DAG.
 func GetFeeEstimatesHandler() (interface{}, error) {
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	window := config.ActiveConfig().FeeEstimateWindow
+	fromBlueScore := uint64(0)
+	if selectedTipBlueScore > window {
+		fromBlueScore = selectedTipBlueScore - window
+	}
+
+	sampleCount, feeRates, err := dbaccess.FeeRatePercentiles(database.NoTx(), fromBlueScore, feeRatePercentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mempool size only refines the estimate, so the estimate
+	// is still returned if the node can't be reached
+	var mempoolSize *uint64
+	mempoolTxCount, err := fetchMempoolSize()
+	if err != nil {
+		log.Warnf("Error fetching the mempool size for the fee estimate: %s", err)
+	} else {
+		mempoolSize = &mempoolTxCount
+	}
+
+	return buildFeeEstimate(sampleCount, feeRates, fromBlueScore, selectedTipBlueScore,
+		mempoolSize, config.ActiveConfig().FeeEstimateMinSamples), nil
+}
+
+func fetchMempoolSize() (uint64, error) {
+	client, err := jsonrpc.GetClient()
+	if err != nil {
+		return 0, err
+	}
+	mempoolTxIDs, err := client.GetRawMempool()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(mempoolTxIDs)), nil
+}
+
+// buildFeeEstimate builds a fee estimate out of the fee rate percentiles of the
+// transactions accepted between fromBlueScore and toBlueScore, taking the current
+// mempool size into account if it's known. If there are less than minSamples
+// transactions in that range, it falls back to fixed fee rates.
+func buildFeeEstimate(sampleCount uint64, feeRates []float64, fromBlueScore, toBlueScore uint64,
+	mempoolSize *uint64, minSamples uint64) *apimodels.FeeEstimateResponse {
+
+	confidence := &apimodels.FeeEstimateConfidence{
+		Level:         feeEstimateConfidenceLevel(sampleCount, minSamples),
+		SampleCount:   sampleCount,
+		FromBlueScore: fromBlueScore,
+		ToBlueScore:   toBlueScore,
+		MempoolSize:   mempoolSize,
+	}
+
+	if sampleCount == 0 || sampleCount < minSamples || len(feeRates) != len(feeRatePercentiles) {
+		confidence.Level = feeEstimateConfidenceNone
+		confidence.IsFallback = true
+		return &apimodels.FeeEstimateResponse{
+			HighPriority:   fallbackHighPriorityFeeRate,
+			NormalPriority: fallbackNormalPriorityFeeRate,
+			LowPriority:    fallbackLowPriorityFeeRate,
+			Confidence:     confidence,
+		}
+	}
+
+	// The mempool is considered congested if it holds more transactions than
+	// are usually accepted in congestionThresholdBlocks blocks.
+	blueScoreSpan := toBlueScore - fromBlueScore + 1
+	averageTransactionsPerBlock := float64(sampleCount) / float64(blueScoreSpan)
+	confidence.IsCongested = mempoolSize != nil &&
+		float64(*mempoolSize) > averageTransactionsPerBlock*congestionThresholdBlocks
+
+	percentileIndexes := regularFeeRatePercentileIndexes
+	if confidence.IsCongested {
+		percentileIndexes = congestedFeeRatePercentileIndexes
+	}
+
 	return &apimodels.FeeEstimateResponse{
-		HighPriority:   3,
-		NormalPriority: 2,
-		LowPriority:    1,
-	}, nil
+		HighPriority:   math.Max(feeRates[percentileIndexes.high], minFeeRate),
+		NormalPriority: math.Max(feeRates[percentileIndexes.normal], minFeeRate),
+		LowPriority:    math.Max(feeRates[percentileIndexes.low], minFeeRate),
+		Confidence:     confidence,
+	}
+}
+
+func feeEstimateConfidenceLevel(sampleCount uint64, minSamples uint64) string {
+	switch {
+	case sampleCount == 0 || sampleCount < minSamples:
+		return feeEstimateConfidenceNone
+	case sampleCount >= 10*minSamples:
+		return feeEstimateConfidenceHigh
+	case sampleCount >= 4*minSamples:
+		return feeEstimateConfidenceMedium
+	default:
+		return feeEstimateConfidenceLow
+	}
 }
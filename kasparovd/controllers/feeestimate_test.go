@@ -0,0 +1,100 @@
+package controllers
+
+import "testing"
+
+func uint64Pointer(value uint64) *uint64 {
+	return &value
+}
+
+func TestBuildFeeEstimate(t *testing.T) {
+	feeRates := []float64{0.5, 2, 4, 8, 16}
+
+	tests := []struct {
+		name                   string
+		sampleCount            uint64
+		feeRates               []float64
+		mempoolSize            *uint64
+		expectedHighPriority   float64
+		expectedNormalPriority float64
+		expectedLowPriority    float64
+		expectedLevel          string
+		expectedIsFallback     bool
+		expectedIsCongested    bool
+	}{
+		{
+			name:                   "no samples",
+			sampleCount:            0,
+			feeRates:               nil,
+			expectedHighPriority:   fallbackHighPriorityFeeRate,
+			expectedNormalPriority: fallbackNormalPriorityFeeRate,
+			expectedLowPriority:    fallbackLowPriorityFeeRate,
+			expectedLevel:          feeEstimateConfidenceNone,
+			expectedIsFallback:     true,
+		},
+		{
+			name:                   "not enough samples",
+			sampleCount:            49,
+			feeRates:               feeRates,
+			expectedHighPriority:   fallbackHighPriorityFeeRate,
+			expectedNormalPriority: fallbackNormalPriorityFeeRate,
+			expectedLowPriority:    fallbackLowPriorityFeeRate,
+			expectedLevel:          feeEstimateConfidenceNone,
+			expectedIsFallback:     true,
+		},
+		{
+			name:                   "regular",
+			sampleCount:            250,
+			feeRates:               feeRates,
+			mempoolSize:            uint64Pointer(1),
+			expectedHighPriority:   8,
+			expectedNormalPriority: 2,
+			expectedLowPriority:    minFeeRate,
+			expectedLevel:          feeEstimateConfidenceMedium,
+		},
+		{
+			name:                   "congested",
+			sampleCount:            1000,
+			feeRates:               feeRates,
+			mempoolSize:            uint64Pointer(1000),
+			expectedHighPriority:   16,
+			expectedNormalPriority: 4,
+			expectedLowPriority:    2,
+			expectedLevel:          feeEstimateConfidenceHigh,
+			expectedIsCongested:    true,
+		},
+		{
+			name:                   "unknown mempool size",
+			sampleCount:            1000,
+			feeRates:               feeRates,
+			mempoolSize:            nil,
+			expectedHighPriority:   8,
+			expectedNormalPriority: 2,
+			expectedLowPriority:    minFeeRate,
+			expectedLevel:          feeEstimateConfidenceHigh,
+		},
+	}
+
+	for _, test := range tests {
+		estimate := buildFeeEstimate(test.sampleCount, test.feeRates, 0, 99, test.mempoolSize, 50)
+
+		if estimate.HighPriority != test.expectedHighPriority ||
+			estimate.NormalPriority != test.expectedNormalPriority ||
+			estimate.LowPriority != test.expectedLowPriority {
+			t.Errorf("%s: Expected fee rates %f/%f/%f but got %f/%f/%f", test.name,
+				test.expectedHighPriority, test.expectedNormalPriority, test.expectedLowPriority,
+				estimate.HighPriority, estimate.NormalPriority, estimate.LowPriority)
+		}
+		if estimate.Confidence.Level != test.expectedLevel {
+			t.Errorf("%s: Expected confidence level '%s' but got '%s'", test.name,
+				test.expectedLevel, estimate.Confidence.Level)
+		}
+		if estimate.Confidence.IsFallback != test.expectedIsFallback {
+			t.Errorf("%s: Expected IsFallback %t but got %t", test.name,
+				test.expectedIsFallback, estimate.Confidence.IsFallback)
+		}
+		if estimate.Confidence.IsCongested != test.expectedIsCongested {
+			t.Errorf("%s: Expected IsCongested %t but got %t", test.name,
+				test.expectedIsCongested, estimate.Confidence.IsCongested)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/jsonrpc"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+const healthStatusOK = "ok"
+
+// nodeSelectedTipCacheDuration is the duration for which the selected
+// tip of the node is cached. Fetching it requires fetching the whole
+// block, so it isn't fetched again for every readiness probe.
+const nodeSelectedTipCacheDuration = 5 * time.Second
+
+var nodeSelectedTipCache struct {
+	sync.Mutex
+	blueScore uint64
+	timestamp time.Time
+	fetchedAt time.Time
+}
+
+// GetLivenessHandler reports that the server is up and able to handle requests
+func GetLivenessHandler() (interface{}, error) {
+	return &apimodels.HealthResponse{
+		Status: healthStatusOK,
+	}, nil
+}
+
+// GetReadinessHandler reports whether the server is connected to the
+// database and to the node, and whether the database does not trail
+// the node by more than the configured thresholds
+func GetReadinessHandler() (interface{}, error) {
+	db, err := database.DBInstance()
+	if err != nil {
+		return nil, notReadyError(err, "the database is not connected")
+	}
+	_, err = db.Exec("SELECT 1")
+	if err != nil {
+		return nil, notReadyError(err, "the database is unreachable")
+	}
+
+	nodeSelectedTipBlueScore, nodeSelectedTipTimestamp, err := fetchNodeSelectedTip()
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTip, err := dbaccess.SelectedTip(database.NoTx())
+	if err != nil {
+		return nil, notReadyError(err, "the database is unreachable")
+	}
+	if selectedTip == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusServiceUnavailable,
+			errors.New("the database does not contain any blocks yet"))
+	}
+
+	var blueScoreLag uint64
+	if nodeSelectedTipBlueScore > selectedTip.BlueScore {
+		blueScoreLag = nodeSelectedTipBlueScore - selectedTip.BlueScore
+	}
+	var timeLag time.Duration
+	if nodeSelectedTipTimestamp.After(selectedTip.Timestamp) {
+		timeLag = nodeSelectedTipTimestamp.Sub(selectedTip.Timestamp)
+	}
+
+	maxBlueScoreLag := config.ActiveConfig().ReadyMaxBlueScoreLag
+	if blueScoreLag > maxBlueScoreLag {
+		return nil, httpserverutils.NewHandlerError(http.StatusServiceUnavailable,
+			errors.Errorf("the selected tip of the database trails the node by %d blue score units, "+
+				"which is more than the allowed %d", blueScoreLag, maxBlueScoreLag))
+	}
+	maxTimeLag := config.ActiveConfig().ReadyMaxTimeLag
+	if timeLag > maxTimeLag {
+		return nil, httpserverutils.NewHandlerError(http.StatusServiceUnavailable,
+			errors.Errorf("the selected tip of the database trails the node by %s, "+
+				"which is more than the allowed %s", timeLag, maxTimeLag))
+	}
+
+	return &apimodels.ReadinessResponse{
+		Status:                   healthStatusOK,
+		SelectedTipBlueScore:     selectedTip.BlueScore,
+		NodeSelectedTipBlueScore: nodeSelectedTipBlueScore,
+		BlueScoreLag:             blueScoreLag,
+		TimeLagSeconds:           uint64(timeLag.Seconds()),
+	}, nil
+}
+
+// fetchNodeSelectedTip returns the blue score and the timestamp of the selected
+// tip of the node, which are cached for nodeSelectedTipCacheDuration
+func fetchNodeSelectedTip() (blueScore uint64, timestamp time.Time, err error) {
+	nodeSelectedTipCache.Lock()
+	defer nodeSelectedTipCache.Unlock()
+	if time.Since(nodeSelectedTipCache.fetchedAt) < nodeSelectedTipCacheDuration {
+		return nodeSelectedTipCache.blueScore, nodeSelectedTipCache.timestamp, nil
+	}
+
+	client, err := jsonrpc.GetClient()
+	if err != nil {
+		return 0, time.Time{}, notReadyError(err, "the node is not connected")
+	}
+	selectedTipHash, err := client.GetSelectedTipHash()
+	if err != nil {
+		return 0, time.Time{}, notReadyError(err, "the node is unreachable")
+	}
+	selectedTip, err := client.GetBlockVerboseTx(selectedTipHash, nil)
+	if err != nil {
+		return 0, time.Time{}, notReadyError(err, "the node is unreachable")
+	}
+
+	nodeSelectedTipCache.blueScore = selectedTip.BlueScore
+	nodeSelectedTipCache.timestamp = time.Unix(selectedTip.Time, 0)
+	nodeSelectedTipCache.fetchedAt = time.Now()
+	return nodeSelectedTipCache.blueScore, nodeSelectedTipCache.timestamp, nil
+}
+
+// notReadyError returns a HandlerError with the http.StatusServiceUnavailable
+// status, without exposing the details of the underlying error to the client
+func notReadyError(err error, clientMessage string) error {
+	return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusServiceUnavailable, err, clientMessage)
+}
@@ -0,0 +1,7 @@
+package controllers
+
+import "github.com/kaspanet/kasparov/logger"
+
+var (
+	log = logger.Logger("CTRL")
+)
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+// GetMempoolHandler returns the pending transactions in the mempool, newest first
+func GetMempoolHandler(skip, limit int64) (interface{}, error) {
+	if err := validateMempoolSkipAndLimit(skip, limit); err != nil {
+		return nil, err
+	}
+
+	txs, err := dbaccess.MempoolTransactions(database.NoTx(), uint64(skip), uint64(limit),
+		dbmodels.MempoolTransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := dbaccess.MempoolTransactionsCount(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	return convertMempoolTxModelsToMempoolResponse(txs, size), nil
+}
+
+// GetMempoolByAddressHandler returns the pending transactions in the mempool
+// where the given address is either an input or an output, newest first
+func GetMempoolByAddressHandler(address string, skip, limit int64) (interface{}, error) {
+	if err := validateMempoolSkipAndLimit(skip, limit); err != nil {
+		return nil, err
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	txs, err := dbaccess.MempoolTransactionsByAddress(database.NoTx(), address, uint64(skip), uint64(limit),
+		dbmodels.MempoolTransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := dbaccess.MempoolTransactionsByAddressCount(database.NoTx(), address)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertMempoolTxModelsToMempoolResponse(txs, size), nil
+}
+
+func validateMempoolSkipAndLimit(skip, limit int64) error {
+	if limit > maxGetTransactionsLimit || limit < 1 {
+		return httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTransactionsLimit))
+	}
+
+	if skip < 0 {
+		return httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.New("skip lower than 0 was requested"))
+	}
+	return nil
+}
+
+func convertMempoolTxModelsToMempoolResponse(txs []*dbmodels.MempoolTransaction, size uint64) *apimodels.MempoolResponse {
+	mempoolResponse := &apimodels.MempoolResponse{
+		Transactions: make([]*apimodels.TransactionResponse, len(txs)),
+		Size:         size,
+	}
+	for i, tx := range txs {
+		mempoolResponse.Transactions[i] = apimodels.ConvertMempoolTxModelToTxResponse(tx)
+	}
+	return mempoolResponse
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"sort"
+
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+const maxGetReorgsLimit = 100
+
+// GetReorgsHandler returns the changes of the selected parent chain in which
+// at least `minDepth` chain blocks were removed, newest first.
+func GetReorgsHandler(minDepth uint64, skip, limit int64) (interface{}, error) {
+	if limit > maxGetReorgsLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetReorgsLimit))
+	}
+
+	if skip < 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.New("skip lower than 0 was requested"))
+	}
+
+	chainChanges, err := dbaccess.ChainChanges(database.NoTx(), minDepth, uint64(skip), uint64(limit),
+		dbmodels.ChainChangeRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	var addedBlockIDs []uint64
+	for _, chainChange := range chainChanges {
+		for _, chainChangeBlock := range chainChange.ChainChangeBlocks {
+			if chainChangeBlock.IsAdded {
+				addedBlockIDs = append(addedBlockIDs, chainChangeBlock.BlockID)
+			}
+		}
+	}
+	acceptedTransactionIDsByBlockID, err := dbaccess.AcceptedTransactionIDsByBlockIDs(database.NoTx(), addedBlockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	chainChangeResponses := make([]*apimodels.ChainChangeResponse, len(chainChanges))
+	for i, chainChange := range chainChanges {
+		chainChangeResponses[i] = convertChainChangeModelToChainChangeResponse(chainChange, acceptedTransactionIDsByBlockID)
+	}
+	return chainChangeResponses, nil
+}
+
+// GetTransactionHistoryHandler returns all the times the transaction
+// with the given ID was accepted or unaccepted, oldest first.
+func GetTransactionHistoryHandler(txID string) (interface{}, error) {
+	if bytes, err := hex.DecodeString(txID); err != nil || len(bytes) != daghash.TxIDSize {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("The given txid is not a hex-encoded %d-byte hash", daghash.TxIDSize))
+	}
+
+	transactions, err := dbaccess.TransactionsByIDs(database.NoTx(), []string{txID})
+	if err != nil {
+		return nil, err
+	}
+	unacceptances, err := dbaccess.ChainChangeTransactionsByTransactionID(database.NoTx(), txID,
+		dbmodels.ChainChangeTransactionFieldNames.ChainChange,
+		dbmodels.ChainChangeTransactionFieldNames.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Acceptances aren't stored, so they're derived from the times in which the
+	// blocks that accept, or used to accept, the transaction were added to the
+	// selected parent chain
+	acceptingBlockTransactionHashes := make(map[uint64]string)
+	for _, transaction := range transactions {
+		if transaction.AcceptingBlockID != nil {
+			acceptingBlockTransactionHashes[*transaction.AcceptingBlockID] = transaction.TransactionHash
+		}
+	}
+	for _, unacceptance := range unacceptances {
+		acceptingBlockTransactionHashes[unacceptance.BlockID] = unacceptance.Transaction.TransactionHash
+	}
+	acceptingBlockIDs := make([]uint64, 0, len(acceptingBlockTransactionHashes))
+	for blockID := range acceptingBlockTransactionHashes {
+		acceptingBlockIDs = append(acceptingBlockIDs, blockID)
+	}
+	acceptances, err := dbaccess.AddedChainChangeBlocksByBlockIDs(database.NoTx(), acceptingBlockIDs,
+		dbmodels.ChainChangeBlockFieldNames.ChainChange,
+		dbmodels.ChainChangeBlockFieldNames.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unacceptances) == 0 && len(acceptances) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("no acceptance history was found for the given txid"))
+	}
+
+	responses := make([]*apimodels.TransactionAcceptanceChangeResponse, 0, len(unacceptances)+len(acceptances))
+	for _, unacceptance := range unacceptances {
+		responses = append(responses, &apimodels.TransactionAcceptanceChangeResponse{
+			ChainChangeID:   unacceptance.ChainChangeID,
+			ChangedAt:       uint64(unacceptance.ChainChange.ChangedAt.Unix()),
+			TransactionHash: unacceptance.Transaction.TransactionHash,
+			BlockHash:       unacceptance.Block.BlockHash,
+			BlockBlueScore:  unacceptance.Block.BlueScore,
+			IsAccepted:      false,
+		})
+	}
+	for _, acceptance := range acceptances {
+		responses = append(responses, &apimodels.TransactionAcceptanceChangeResponse{
+			ChainChangeID:   acceptance.ChainChangeID,
+			ChangedAt:       uint64(acceptance.ChainChange.ChangedAt.Unix()),
+			TransactionHash: acceptingBlockTransactionHashes[acceptance.BlockID],
+			BlockHash:       acceptance.Block.BlockHash,
+			BlockBlueScore:  acceptance.Block.BlueScore,
+			IsAccepted:      true,
+		})
+	}
+
+	// Within the same chain change, the transaction is unaccepted
+	// by the removed blocks before it's accepted by the added ones
+	sort.SliceStable(responses, func(i, j int) bool {
+		if responses[i].ChainChangeID != responses[j].ChainChangeID {
+			return responses[i].ChainChangeID < responses[j].ChainChangeID
+		}
+		return !responses[i].IsAccepted && responses[j].IsAccepted
+	})
+	return responses, nil
+}
+
+func convertChainChangeModelToChainChangeResponse(chainChange *dbmodels.ChainChange,
+	acceptedTransactionIDsByBlockID map[uint64][]string) *apimodels.ChainChangeResponse {
+
+	chainChangeResponse := &apimodels.ChainChangeResponse{
+		ID:                       chainChange.ID,
+		ChangedAt:                uint64(chainChange.ChangedAt.Unix()),
+		Depth:                    chainChange.Depth,
+		RemovedBlockHashes:       make([]string, 0),
+		AddedBlockHashes:         make([]string, 0),
+		UnacceptedTransactionIDs: make([]string, 0),
+		AcceptedTransactionIDs:   make([]string, 0),
+	}
+
+	// Removed and added blocks are indexed separately, so they're
+	// placed according to their index rather than their order in the
+	// preloaded relation, which is unspecified.
+	removedBlocks := make(map[uint32]*dbmodels.Block)
+	addedBlocks := make(map[uint32]*dbmodels.Block)
+	for i := range chainChange.ChainChangeBlocks {
+		chainChangeBlock := &chainChange.ChainChangeBlocks[i]
+		if chainChangeBlock.IsAdded {
+			addedBlocks[chainChangeBlock.Index] = &chainChangeBlock.Block
+		} else {
+			removedBlocks[chainChangeBlock.Index] = &chainChangeBlock.Block
+		}
+	}
+	for i := uint32(0); i < uint32(len(removedBlocks)); i++ {
+		chainChangeResponse.RemovedBlockHashes = append(chainChangeResponse.RemovedBlockHashes, removedBlocks[i].BlockHash)
+	}
+	for i := uint32(0); i < uint32(len(addedBlocks)); i++ {
+		chainChangeResponse.AddedBlockHashes = append(chainChangeResponse.AddedBlockHashes, addedBlocks[i].BlockHash)
+		chainChangeResponse.AcceptedTransactionIDs = append(chainChangeResponse.AcceptedTransactionIDs,
+			acceptedTransactionIDsByBlockID[addedBlocks[i].ID]...)
+	}
+
+	for _, chainChangeTransaction := range chainChange.ChainChangeTransactions {
+		chainChangeResponse.UnacceptedTransactionIDs = append(chainChangeResponse.UnacceptedTransactionIDs,
+			chainChangeTransaction.Transaction.TransactionID)
+	}
+	return chainChangeResponse
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+// minSearchHashPrefixLength is the minimum number of hex characters
+// that a query must have in order to be matched against hash prefixes
+const minSearchHashPrefixLength = 8
+
+// maxSearchResultsPerMatch is the maximum number of results
+// returned for each of the fields that a query is matched against
+const maxSearchResultsPerMatch = 20
+
+// Search result types
+const (
+	searchResultTypeBlock       = "block"
+	searchResultTypeTransaction = "transaction"
+	searchResultTypeAddress     = "address"
+)
+
+// The fields that a search query is matched against
+const (
+	searchMatchedByBlockHash       = "blockHash"
+	searchMatchedByTransactionHash = "transactionHash"
+	searchMatchedByTransactionID   = "transactionId"
+	searchMatchedByAddress         = "address"
+)
+
+type searchQueryKind int
+
+const (
+	searchQueryKindHashPrefix searchQueryKind = iota
+	searchQueryKindAddress
+)
+
+// GetSearchHandler looks up the given query as a block hash, a transaction
+// hash, a transaction ID or an address. Block hashes, transaction hashes and
+// transaction IDs also match if they start with the query.
+func GetSearchHandler(query string) (interface{}, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	kind, err := classifySearchQuery(query, config.ActiveConfig().ActiveNetParams.Prefix)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	response := &apimodels.SearchResponse{
+		Query:   query,
+		Results: make([]*apimodels.SearchResultResponse, 0),
+	}
+	switch kind {
+	case searchQueryKindHashPrefix:
+		response.Results, err = searchHashPrefix(query)
+	case searchQueryKindAddress:
+		response.Results, err = searchAddress(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// classifySearchQuery determines what the given lowercase query may be looked
+// up as, and returns an error if it isn't a well-formatted hash, hash prefix or
+// address.
+func classifySearchQuery(query string, addressPrefix util.Bech32Prefix) (searchQueryKind, error) {
+	if len(query) == 0 {
+		return 0, errors.New("the search query is empty")
+	}
+
+	if isLowercaseHex(query) {
+		if len(query) > daghash.HashSize*2 {
+			return 0, errors.Errorf("the search query is longer than a hex-encoded %d-byte hash", daghash.HashSize)
+		}
+		if len(query) < minSearchHashPrefixLength {
+			return 0, errors.Errorf("hash search queries must be at least %d characters long", minSearchHashPrefixLength)
+		}
+		return searchQueryKindHashPrefix, nil
+	}
+
+	if _, err := util.DecodeAddress(query, addressPrefix); err == nil {
+		return searchQueryKindAddress, nil
+	}
+
+	return 0, errors.New("the search query is not a hex-encoded hash or hash prefix, " +
+		"nor a well-formatted P2PKH or P2SH address")
+}
+
+func isLowercaseHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func searchHashPrefix(hashPrefix string) ([]*apimodels.SearchResultResponse, error) {
+	blocks, err := dbaccess.BlocksByHashPrefix(database.NoTx(), hashPrefix, maxSearchResultsPerMatch,
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionsByHash, err := dbaccess.TransactionsByHashPrefix(database.NoTx(), hashPrefix, maxSearchResultsPerMatch,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionsByID, err := dbaccess.TransactionsByIDPrefix(database.NoTx(), hashPrefix, maxSearchResultsPerMatch,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*apimodels.SearchResultResponse, 0, len(blocks)+len(transactionsByHash)+len(transactionsByID))
+	for _, block := range blocks {
+		results = append(results, &apimodels.SearchResultResponse{
+			Type:      searchResultTypeBlock,
+			MatchedBy: searchMatchedByBlockHash,
+			Block:     apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore),
+		})
+	}
+
+	// Transactions without signature scripts, such as coinbase
+	// transactions, have identical hashes and IDs, so they're
+	// reported only once.
+	resultTransactionIDs := make(map[uint64]struct{}, len(transactionsByHash))
+	for _, transaction := range transactionsByHash {
+		resultTransactionIDs[transaction.ID] = struct{}{}
+		results = append(results, &apimodels.SearchResultResponse{
+			Type:        searchResultTypeTransaction,
+			MatchedBy:   searchMatchedByTransactionHash,
+			Transaction: apimodels.ConvertTxModelToTxResponse(transaction, selectedTipBlueScore),
+		})
+	}
+	for _, transaction := range transactionsByID {
+		if _, ok := resultTransactionIDs[transaction.ID]; ok {
+			continue
+		}
+		results = append(results, &apimodels.SearchResultResponse{
+			Type:        searchResultTypeTransaction,
+			MatchedBy:   searchMatchedByTransactionID,
+			Transaction: apimodels.ConvertTxModelToTxResponse(transaction, selectedTipBlueScore),
+		})
+	}
+
+	return results, nil
+}
+
+func searchAddress(address string) ([]*apimodels.SearchResultResponse, error) {
+	addresses, err := dbaccess.AddressesByAddressStrings(database.NoTx(), []string{address})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*apimodels.SearchResultResponse, len(addresses))
+	for i, dbAddress := range addresses {
+		results[i] = &apimodels.SearchResultResponse{
+			Type:      searchResultTypeAddress,
+			MatchedBy: searchMatchedByAddress,
+			Address:   dbAddress.Address,
+		}
+	}
+	return results, nil
+}
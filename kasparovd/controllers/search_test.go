@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/util"
+)
+
+func TestClassifySearchQuery(t *testing.T) {
+	address, err := util.NewAddressPubKeyHash(make([]byte, 20), util.Bech32PrefixKaspa)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %s", err)
+	}
+	hash := strings.Repeat("0123456789abcdef", 4)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedKind  searchQueryKind
+		expectedError bool
+	}{
+		{"full hash", hash, searchQueryKindHashPrefix, false},
+		{"hash prefix", hash[:8], searchQueryKindHashPrefix, false},
+		{"odd length hash prefix", hash[:9], searchQueryKindHashPrefix, false},
+		{"short hash prefix", hash[:7], 0, true},
+		{"too long hash", hash + "0", 0, true},
+		{"uppercase hex", strings.ToUpper(hash), 0, true},
+		{"address", address.String(), searchQueryKindAddress, false},
+		{"address of another network", strings.Replace(address.String(), "kaspa:", "kaspatest:", 1), 0, true},
+		{"malformed address", address.String()[:len(address.String())-1], 0, true},
+		{"empty", "", 0, true},
+		{"garbage", "not a hash", 0, true},
+	}
+
+	for _, test := range tests {
+		kind, err := classifySearchQuery(test.query, util.Bech32PrefixKaspa)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: Expected an error but got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %s", test.name, err)
+			continue
+		}
+		if kind != test.expectedKind {
+			t.Errorf("%s: Expected kind %d but got %d", test.name, test.expectedKind, kind)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+// GetTransactionOutputSpenderHandler returns the transaction input that spends
+// the output with the given index of the transaction with the given ID.
+func GetTransactionOutputSpenderHandler(txID string, indexString string) (interface{}, error) {
+	if bytes, err := hex.DecodeString(txID); err != nil || len(bytes) != daghash.TxIDSize {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("The given txid is not a hex-encoded %d-byte hash", daghash.TxIDSize))
+	}
+	index, err := strconv.ParseUint(indexString, 10, 32)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error parsing output index"),
+			"The given output index is not a valid uint32")
+	}
+
+	transactionOutputs, err := dbaccess.TransactionOutputsByOutpoints(database.NoTx(),
+		[]*dbaccess.Outpoint{{TransactionID: txID, Index: uint32(index)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(transactionOutputs) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("no transaction output with the given txid and index was found"))
+	}
+
+	outputID := transactionOutputs[0].ID
+	spenders, err := outputSpenders([]uint64{outputID})
+	if err != nil {
+		return nil, err
+	}
+	spender, ok := spenders[outputID]
+	if !ok {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("the transaction output with the given txid and index was not spent"))
+	}
+	return spender, nil
+}
+
+// outputSpenders returns the transaction inputs that spend the transaction outputs
+// with the given database IDs, mapped by the IDs of the outputs. Unspent outputs
+// are missing from the map. An output might be spent by several transactions in
+// different blocks, in which case the one that was accepted is preferred.
+func outputSpenders(outputIDs []uint64) (map[uint64]*apimodels.TransactionOutputSpenderResponse, error) {
+	txIns, err := dbaccess.TransactionInputsByPreviousTransactionOutputIDs(database.NoTx(), outputIDs,
+		dbmodels.TransactionInputFieldNames.Transaction,
+		dbmodels.TransactionInputFieldNames.TransactionAcceptingBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	spenders := make(map[uint64]*apimodels.TransactionOutputSpenderResponse, len(txIns))
+	for _, txIn := range txIns {
+		if spender, ok := spenders[txIn.PreviousTransactionOutputID]; ok && spender.AcceptingBlockHash != nil {
+			continue
+		}
+		spender := &apimodels.TransactionOutputSpenderResponse{
+			TransactionID: txIn.Transaction.TransactionID,
+			InputIndex:    txIn.Index,
+		}
+		if txIn.Transaction.AcceptingBlock != nil {
+			spender.AcceptingBlockHash = &txIn.Transaction.AcceptingBlock.BlockHash
+			spender.AcceptingBlockBlueScore = &txIn.Transaction.AcceptingBlock.BlueScore
+		}
+		spenders[txIn.PreviousTransactionOutputID] = spender
+	}
+	return spenders, nil
+}
+
+// addSpendersToTxResponses sets the spenders of the outputs of all the given
+// transaction responses. txResponses[i] must be the response of txs[i], and
+// the outputs of the transactions must be preloaded.
+func addSpendersToTxResponses(txs []*dbmodels.Transaction, txResponses []*apimodels.TransactionResponse) error {
+	outputIDs := make([]uint64, 0)
+	for _, tx := range txs {
+		for _, txOut := range tx.TransactionOutputs {
+			outputIDs = append(outputIDs, txOut.ID)
+		}
+	}
+	spenders, err := outputSpenders(outputIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, tx := range txs {
+		outputIndexesToIDs := make(map[uint32]uint64, len(tx.TransactionOutputs))
+		for _, txOut := range tx.TransactionOutputs {
+			outputIndexesToIDs[txOut.Index] = txOut.ID
+		}
+		for _, txOutResponse := range txResponses[i].Outputs {
+			txOutResponse.SpentBy = spenders[outputIndexesToIDs[txOutResponse.Index]]
+		}
+	}
+	return nil
+}
+
+// addSpendersToOutputResponses sets the spenders of all the given transaction output
+// responses. outputResponses[i] must be the response of outputs[i].
+func addSpendersToOutputResponses(outputs []*dbmodels.TransactionOutput,
+	outputResponses []*apimodels.TransactionOutputResponse) error {
+
+	outputIDs := make([]uint64, len(outputs))
+	for i, output := range outputs {
+		outputIDs[i] = output.ID
+	}
+	spenders, err := outputSpenders(outputIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, output := range outputs {
+		outputResponses[i].SpentBy = spenders[output.ID]
+	}
+	return nil
+}
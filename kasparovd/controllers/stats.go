@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+// statsWindow is the period of time over which /stats measures the network
+const statsWindow = 24 * time.Hour
+
+// defaultStatsTimeseriesPoints is the number of points returned
+// by /stats/timeseries if no time range is requested
+const defaultStatsTimeseriesPoints = 24
+
+// maxStatsTimeseriesPoints is the maximum number of points
+// that may be returned by /stats/timeseries
+const maxStatsTimeseriesPoints = 1000
+
+// Network statistics metrics
+const (
+	statsMetricBlocksPerSecond       = "blocksPerSecond"
+	statsMetricTransactionsPerSecond = "transactionsPerSecond"
+	statsMetricAverageMass           = "averageMass"
+	statsMetricDifficulty            = "difficulty"
+	statsMetricHashrate              = "hashrate"
+	statsMetricCirculatingSupply     = "circulatingSupply"
+	statsMetricActiveAddresses       = "activeAddresses"
+)
+
+var statsMetrics = map[string]struct{}{
+	statsMetricBlocksPerSecond:       {},
+	statsMetricTransactionsPerSecond: {},
+	statsMetricAverageMass:           {},
+	statsMetricDifficulty:            {},
+	statsMetricHashrate:              {},
+	statsMetricCirculatingSupply:     {},
+	statsMetricActiveAddresses:       {},
+}
+
+// twoTo256 is 2^256, the number of hashes it would take
+// to find a block whose target is 1, as a float
+var twoTo256 = math.Ldexp(1, 256)
+
+// GetNetworkStatsHandler returns the network statistics of the last statsWindow.
+func GetNetworkStatsHandler() (interface{}, error) {
+	now := time.Now()
+	from := now.Truncate(time.Hour).Add(-statsWindow)
+	bucket, err := dbaccess.StatsTotal(database.NoTx(), from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	circulatingSupply, err := dbaccess.CoinbaseValueBefore(database.NoTx(), now.Truncate(time.Hour).Add(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := now.Sub(from).Seconds()
+	powMax := activePowMax()
+	return &apimodels.NetworkStatsResponse{
+		WindowSeconds:         uint64(seconds),
+		BlocksPerSecond:       statsMetricValue(statsMetricBlocksPerSecond, bucket, seconds, powMax),
+		TransactionsPerSecond: statsMetricValue(statsMetricTransactionsPerSecond, bucket, seconds, powMax),
+		AverageMass:           statsMetricValue(statsMetricAverageMass, bucket, seconds, powMax),
+		Difficulty:            statsMetricValue(statsMetricDifficulty, bucket, seconds, powMax),
+		Hashrate:              statsMetricValue(statsMetricHashrate, bucket, seconds, powMax),
+		ActiveAddresses:       bucket.ActiveAddressCount,
+		CirculatingSupply:     circulatingSupply,
+	}, nil
+}
+
+// GetStatsTimeseriesHandler returns the values of the given network statistics metric
+// between `from` and `to`, one for every interval. A nil `to` stands for now, and a nil
+// `from` stands for defaultStatsTimeseriesPoints intervals before `to`.
+func GetStatsTimeseriesHandler(metric string, intervalString string, from *time.Time, to *time.Time) (interface{}, error) {
+	if _, ok := statsMetrics[metric]; !ok {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("'%s' is not a valid metric", metric))
+	}
+
+	interval, err := dbaccess.StringToStatsInterval(intervalString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	now := time.Now()
+	if to == nil {
+		to = &now
+	}
+	if from == nil {
+		defaultFrom := to.Add(-defaultStatsTimeseriesPoints * interval.Duration())
+		from = &defaultFrom
+	}
+	if from.After(*to) {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the start of the time range is later than its end"))
+	}
+
+	alignedFrom, alignedTo := alignStatsTimeRange(*from, *to, interval.Duration())
+	if alignedTo.Sub(alignedFrom)/interval.Duration() > maxStatsTimeseriesPoints {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("more than %d points were requested", maxStatsTimeseriesPoints))
+	}
+
+	buckets, err := dbaccess.StatsBuckets(database.NoTx(), interval, alignedFrom, alignedTo)
+	if err != nil {
+		return nil, err
+	}
+	bucketsByTime := make(map[int64]*dbaccess.StatsBucket, len(buckets))
+	for _, bucket := range buckets {
+		bucketsByTime[bucket.Bucket.Unix()] = bucket
+	}
+
+	var circulatingSupply uint64
+	if metric == statsMetricCirculatingSupply {
+		circulatingSupply, err = dbaccess.CoinbaseValueBefore(database.NoTx(), alignedFrom)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	powMax := activePowMax()
+	response := &apimodels.StatsTimeseriesResponse{
+		Metric:   metric,
+		Interval: string(interval),
+		Points:   make([]*apimodels.StatsDataPoint, 0),
+	}
+	for bucketStart := alignedFrom; bucketStart.Before(alignedTo); bucketStart = bucketStart.Add(interval.Duration()) {
+		bucket, ok := bucketsByTime[bucketStart.Unix()]
+		if !ok {
+			bucket = &dbaccess.StatsBucket{Bucket: bucketStart}
+		}
+
+		// The current interval has only partially passed
+		bucketEnd := bucketStart.Add(interval.Duration())
+		if bucketEnd.After(now) {
+			bucketEnd = now
+		}
+		seconds := bucketEnd.Sub(bucketStart).Seconds()
+
+		value := statsMetricValue(metric, bucket, seconds, powMax)
+		if metric == statsMetricCirculatingSupply {
+			circulatingSupply += bucket.CoinbaseValue
+			value = float64(circulatingSupply)
+		}
+		response.Points = append(response.Points, &apimodels.StatsDataPoint{
+			Timestamp: uint64(bucketStart.Unix()),
+			Value:     value,
+		})
+	}
+	return response, nil
+}
+
+// alignStatsTimeRange extends the given time range to
+// the boundaries of the intervals that contain it
+func alignStatsTimeRange(from time.Time, to time.Time, interval time.Duration) (time.Time, time.Time) {
+	alignedFrom := from.UTC().Truncate(interval)
+	alignedTo := to.UTC().Truncate(interval)
+	if alignedTo.Before(to) || alignedTo.Equal(alignedFrom) {
+		alignedTo = alignedTo.Add(interval)
+	}
+	return alignedFrom, alignedTo
+}
+
+// statsMetricValue calculates the value of `metric` for the given bucket, which is
+// `seconds` long. Cumulative metrics such as the circulating supply return 0.
+func statsMetricValue(metric string, bucket *dbaccess.StatsBucket, seconds float64, powMax float64) float64 {
+	switch metric {
+	case statsMetricBlocksPerSecond:
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(bucket.BlockCount) / seconds
+	case statsMetricTransactionsPerSecond:
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(bucket.TransactionCount) / seconds
+	case statsMetricAverageMass:
+		if bucket.BlockCount == 0 {
+			return 0
+		}
+		return float64(bucket.TotalMass) / float64(bucket.BlockCount)
+	case statsMetricDifficulty:
+		// The difficulty of a block is the maximum target divided by its target,
+		// and its work is 2^256 divided by its target.
+		if bucket.BlockCount == 0 {
+			return 0
+		}
+		return bucket.TotalWork / float64(bucket.BlockCount) * powMax / twoTo256
+	case statsMetricHashrate:
+		if seconds <= 0 {
+			return 0
+		}
+		return bucket.TotalWork / seconds
+	case statsMetricActiveAddresses:
+		return float64(bucket.ActiveAddressCount)
+	default:
+		return 0
+	}
+}
+
+func activePowMax() float64 {
+	powMax, _ := new(big.Float).SetInt(config.ActiveConfig().NetParams().PowMax).Float64()
+	return powMax
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kasparov/dbaccess"
+)
+
+func TestStatsMetricValue(t *testing.T) {
+	const powMax = 1 << 32
+
+	// Two blocks whose target is powMax, so each
+	// has a difficulty of 1 and 2^256 / 2^32 work
+	bucket := &dbaccess.StatsBucket{
+		BlockCount:         2,
+		TransactionCount:   10,
+		TotalMass:          3000,
+		TotalWork:          2 * math.Ldexp(1, 256-32),
+		ActiveAddressCount: 7,
+	}
+
+	tests := []struct {
+		metric        string
+		seconds       float64
+		expectedValue float64
+	}{
+		{statsMetricBlocksPerSecond, 4, 0.5},
+		{statsMetricTransactionsPerSecond, 4, 2.5},
+		{statsMetricAverageMass, 4, 1500},
+		{statsMetricDifficulty, 4, 1},
+		{statsMetricHashrate, 4, math.Ldexp(1, 256-32) / 2},
+		{statsMetricActiveAddresses, 4, 7},
+		{statsMetricCirculatingSupply, 4, 0},
+		{statsMetricBlocksPerSecond, 0, 0},
+		{statsMetricHashrate, 0, 0},
+	}
+
+	for _, test := range tests {
+		value := statsMetricValue(test.metric, bucket, test.seconds, powMax)
+		if math.Abs(value-test.expectedValue) > test.expectedValue*1e-9 {
+			t.Errorf("%s over %f seconds: Expected %g but got %g", test.metric, test.seconds, test.expectedValue, value)
+		}
+	}
+
+	emptyBucket := &dbaccess.StatsBucket{}
+	for _, metric := range []string{statsMetricAverageMass, statsMetricDifficulty} {
+		if value := statsMetricValue(metric, emptyBucket, 4, powMax); value != 0 {
+			t.Errorf("%s of an empty bucket: Expected 0 but got %g", metric, value)
+		}
+	}
+}
+
+func TestAlignStatsTimeRange(t *testing.T) {
+	hour := func(h int, m int) time.Time {
+		return time.Date(2020, 6, 1, h, m, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name         string
+		from         time.Time
+		to           time.Time
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{"unaligned", hour(3, 30), hour(5, 10), hour(3, 0), hour(6, 0)},
+		{"aligned", hour(3, 0), hour(5, 0), hour(3, 0), hour(5, 0)},
+		{"empty", hour(3, 0), hour(3, 0), hour(3, 0), hour(4, 0)},
+		{"within one interval", hour(3, 10), hour(3, 20), hour(3, 0), hour(4, 0)},
+	}
+
+	for _, test := range tests {
+		from, to := alignStatsTimeRange(test.from, test.to, time.Hour)
+		if !from.Equal(test.expectedFrom) || !to.Equal(test.expectedTo) {
+			t.Errorf("%s: Expected [%s, %s) but got [%s, %s)",
+				test.name, test.expectedFrom, test.expectedTo, from, to)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/pkg/errors"
+)
+
+// GetSyncStatusHandler returns the sync status of kasparovsyncd
+func GetSyncStatusHandler() (interface{}, error) {
+	syncState, err := dbaccess.SyncState(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	if syncState == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("kasparovsyncd has not started syncing yet"))
+	}
+
+	blockCount, err := dbaccess.BlocksCount(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	return apimodels.ConvertSyncStateModelToSyncStatusResponse(syncState, blockCount, selectedTipBlueScore), nil
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/kaspanet/kaspad/domainmessage"
 	"github.com/kaspanet/kasparov/database"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/kaspanet/kasparov/apimodels"
 	"github.com/kaspanet/kasparov/dbaccess"
@@ -34,7 +36,16 @@ func GetTransactionByIDHandler(txID string) (interface{}, error) {
 		return nil, err
 	}
 	if tx == nil {
-		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no transaction with the given txid was found"))
+		// The transaction might still be pending in the mempool
+		mempoolTx, err := dbaccess.MempoolTransactionByID(database.NoTx(), txID,
+			dbmodels.MempoolTransactionRecommendedPreloadedFields...)
+		if err != nil {
+			return nil, err
+		}
+		if mempoolTx == nil {
+			return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no transaction with the given txid was found"))
+		}
+		return apimodels.ConvertMempoolTxModelToTxResponse(mempoolTx), nil
 	}
 
 	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
@@ -43,6 +54,10 @@ func GetTransactionByIDHandler(txID string) (interface{}, error) {
 	}
 
 	txResponse := apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+	err = addSpendersToTxResponses([]*dbmodels.Transaction{tx}, []*apimodels.TransactionResponse{txResponse})
+	if err != nil {
+		return nil, err
+	}
 	return txResponse, nil
 }
 
@@ -67,12 +82,21 @@ func GetTransactionByHashHandler(txHash string) (interface{}, error) {
 	}
 
 	txResponse := apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+	err = addSpendersToTxResponses([]*dbmodels.Transaction{tx}, []*apimodels.TransactionResponse{txResponse})
+	if err != nil {
+		return nil, err
+	}
 	return txResponse, nil
 }
 
-// GetTransactionsByAddressHandler searches for all transactions
-// where the given address is either an input or an output.
-func GetTransactionsByAddressHandler(address string, skip, limit int64) (interface{}, error) {
+// GetTransactionsByAddressHandler searches for up to `limit` transactions
+// where the given address is either an input or an output, and that pass
+// the given filter, ordered by their database IDs. If cursorString isn't
+// empty, the page starts right after the given cursor. Otherwise, the
+// first `skip` transactions are skipped.
+func GetTransactionsByAddressHandler(address string, filter *dbaccess.AddressTransactionsFilter, orderString string,
+	skip int64, cursorString string, limit int64) (interface{}, error) {
+
 	if limit > maxGetTransactionsLimit || limit < 1 {
 		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
 			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTransactionsLimit))
@@ -87,8 +111,24 @@ func GetTransactionsByAddressHandler(address string, skip, limit int64) (interfa
 		return nil, err
 	}
 
-	txs, err := dbaccess.TransactionsByAddress(database.NoTx(), address, dbaccess.OrderAscending, uint64(skip), uint64(limit),
-		dbmodels.TransactionRecommendedPreloadedFields...)
+	order, err := dbaccess.StringToOrder(orderString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	afterID, err := decodeTransactionCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*dbmodels.Transaction
+	if afterID != nil {
+		txs, err = dbaccess.TransactionsByAddressAfterID(database.NoTx(), address, filter, order, afterID, uint64(limit),
+			dbmodels.TransactionRecommendedPreloadedFields...)
+	} else {
+		txs, err = dbaccess.TransactionsByAddress(database.NoTx(), address, filter, order, uint64(skip), uint64(limit),
+			dbmodels.TransactionRecommendedPreloadedFields...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -98,22 +138,60 @@ func GetTransactionsByAddressHandler(address string, skip, limit int64) (interfa
 		return nil, err
 	}
 
-	txResponses := make([]*apimodels.TransactionResponse, len(txs))
+	txsResponse := &apimodels.TransactionsResponse{
+		Transactions: make([]*apimodels.TransactionResponse, len(txs)),
+	}
 	for i, tx := range txs {
-		txResponses[i] = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+		txsResponse.Transactions[i] = convertAddressTxModelToTxResponse(tx, address, selectedTipBlueScore)
+	}
+	err = addSpendersToTxResponses(txs, txsResponse.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(txs)) == limit {
+		txsResponse.NextCursor = encodeTransactionCursor(txs[len(txs)-1].ID)
 	}
 
-	return txResponses, nil
+	return txsResponse, nil
 }
 
-// GetTransactionCountByAddressHandler returns the total
-// number of transactions by address.
-func GetTransactionCountByAddressHandler(address string) (interface{}, error) {
+// GetTransactionCountByAddressHandler returns the total number of
+// transactions by address that pass the given filter.
+func GetTransactionCountByAddressHandler(address string, filter *dbaccess.AddressTransactionsFilter) (interface{}, error) {
 	if err := validateAddress(address); err != nil {
 		return nil, err
 	}
 
-	return dbaccess.TransactionsByAddressCount(database.NoTx(), address)
+	return dbaccess.TransactionsByAddressCount(database.NoTx(), address, filter)
+}
+
+// convertAddressTxModelToTxResponse converts a transaction of the given address
+// into a TransactionResponse that includes the net value change of the address
+func convertAddressTxModelToTxResponse(tx *dbmodels.Transaction, address string,
+	selectedTipBlueScore uint64) *apimodels.TransactionResponse {
+
+	txResponse := apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+	netValueChange := addressNetValueChange(tx, address)
+	txResponse.NetValueChange = &netValueChange
+	return txResponse
+}
+
+// addressNetValueChange returns the total value sent to `address` in
+// `tx`, minus the total value spent from `address` in it
+func addressNetValueChange(tx *dbmodels.Transaction, address string) int64 {
+	var netValueChange int64
+	for _, txOut := range tx.TransactionOutputs {
+		if txOut.Address != nil && txOut.Address.Address == address {
+			netValueChange += int64(txOut.Value)
+		}
+	}
+	for _, txIn := range tx.TransactionInputs {
+		previousOutput := txIn.PreviousTransactionOutput
+		if previousOutput.Address != nil && previousOutput.Address.Address == address {
+			netValueChange -= int64(previousOutput.Value)
+		}
+	}
+	return netValueChange
 }
 
 // GetTransactionsByBlockHashHandler retrieves all transactions
@@ -136,30 +214,35 @@ func GetTransactionsByBlockHashHandler(blockHash string) (interface{}, error) {
 	for i, tx := range txs {
 		txResponses[i] = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
 	}
+	err = addSpendersToTxResponses(txs, txResponses)
+	if err != nil {
+		return nil, err
+	}
 
 	return apimodels.TransactionsResponse{
 		Transactions: txResponses,
 	}, nil
 }
 
-// PostTransaction forwards a raw transaction to the JSON-RPC API server
-func PostTransaction(requestBody []byte) error {
+// PostTransaction forwards a raw transaction to the JSON-RPC API server,
+// records its submission and returns its ID and hash
+func PostTransaction(requestBody []byte) (interface{}, error) {
 	client, err := jsonrpc.GetClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rawTx := &apimodels.RawTransaction{}
 	err = json.Unmarshal(requestBody, rawTx)
 	if err != nil {
-		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
 			errors.Wrap(err, "error unmarshalling request body"),
 			"the request body is not json-formatted")
 	}
 
 	txBytes, err := hex.DecodeString(rawTx.RawTransaction)
 	if err != nil {
-		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
 			errors.Wrap(err, "error decoding hex raw transaction"),
 			"the raw transaction is not a hex-encoded transaction")
 	}
@@ -168,17 +251,104 @@ func PostTransaction(requestBody []byte) error {
 	tx := &domainmessage.MsgTx{}
 	err = tx.KaspaDecode(txReader, 0)
 	if err != nil {
-		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
 			errors.Wrap(err, "error decoding raw transaction"),
 			"error decoding raw transaction")
 	}
 
 	_, err = client.SendRawTransaction(tx, true)
+	var rejectionError *string
 	if err != nil {
-		if rpcErr := &(rpcmodel.RPCError{}); errors.As(err, &rpcErr) {
-			return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+		rpcErr := &(rpcmodel.RPCError{})
+		if !errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		// A transaction that's already in the node's mempool was
+		// submitted before, and isn't rejected
+		if !strings.Contains(rpcErr.Message, jsonrpc.AlreadyHaveTransactionMessage) {
+			rejectionError = &rpcErr.Message
 		}
-		return err
 	}
-	return nil
+
+	now := time.Now()
+	submittedTx := &dbmodels.SubmittedTransaction{
+		TransactionID:   tx.TxID().String(),
+		TransactionHash: tx.TxHash().String(),
+		RawTransaction:  txBytes,
+		RejectionError:  rejectionError,
+		BroadcastCount:  1,
+		SubmittedAt:     now,
+		LastBroadcastAt: now,
+	}
+	dbErr := dbaccess.UpsertSubmittedTransaction(database.NoTx(), submittedTx)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	if rejectionError != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+	return &apimodels.PostTransactionResponse{
+		TransactionID:   submittedTx.TransactionID,
+		TransactionHash: submittedTx.TransactionHash,
+	}, nil
+}
+
+// GetSubmittedTransactionHandler returns the lifecycle state of a
+// transaction that was submitted through PostTransaction
+func GetSubmittedTransactionHandler(txID string) (interface{}, error) {
+	if bytes, err := hex.DecodeString(txID); err != nil || len(bytes) != daghash.TxIDSize {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("The given txid is not a hex-encoded %d-byte hash", daghash.TxIDSize))
+	}
+
+	submittedTx, err := dbaccess.SubmittedTransactionByID(database.NoTx(), txID)
+	if err != nil {
+		return nil, err
+	}
+	if submittedTx == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("no submitted transaction with the given txid was found"))
+	}
+
+	response := &apimodels.SubmittedTransactionResponse{
+		TransactionID:   submittedTx.TransactionID,
+		TransactionHash: submittedTx.TransactionHash,
+		Status:          apimodels.SubmittedTransactionStatusSubmitted,
+		RejectionError:  submittedTx.RejectionError,
+		BroadcastCount:  submittedTx.BroadcastCount,
+		SubmittedAt:     uint64(submittedTx.SubmittedAt.Unix()),
+		LastBroadcastAt: uint64(submittedTx.LastBroadcastAt.Unix()),
+	}
+	if submittedTx.RejectionError != nil {
+		response.Status = apimodels.SubmittedTransactionStatusRejected
+		return response, nil
+	}
+
+	tx, err := dbaccess.TransactionByID(database.NoTx(), txID,
+		dbmodels.TransactionFieldNames.AcceptingBlock, dbmodels.TransactionFieldNames.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		response.Status = apimodels.SubmittedTransactionStatusIncluded
+		response.IncludingBlockHashes = make([]string, len(tx.Blocks))
+		for i, block := range tx.Blocks {
+			response.IncludingBlockHashes[i] = block.BlockHash
+		}
+		if tx.AcceptingBlock != nil {
+			response.Status = apimodels.SubmittedTransactionStatusAccepted
+			response.AcceptingBlockHash = &tx.AcceptingBlock.BlockHash
+		}
+		return response, nil
+	}
+
+	mempoolTx, err := dbaccess.MempoolTransactionByID(database.NoTx(), txID)
+	if err != nil {
+		return nil, err
+	}
+	if mempoolTx != nil {
+		response.Status = apimodels.SubmittedTransactionStatusInMempool
+	}
+	return response, nil
 }
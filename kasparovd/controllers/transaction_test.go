@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+func TestAddressNetValueChange(t *testing.T) {
+	const (
+		address      = "kaspatest:qq0d6h0prjm5mpdld5pncst3adu0yam6xch4tr69k2"
+		otherAddress = "kaspatest:qzz8ypsnn0w9h5ztnyfql7yg3yu5pmdpqsh4p3xnsv"
+	)
+
+	output := func(address string, value uint64) dbmodels.TransactionOutput {
+		return dbmodels.TransactionOutput{Address: &dbmodels.Address{Address: address}, Value: value}
+	}
+	input := func(address string, value uint64) dbmodels.TransactionInput {
+		return dbmodels.TransactionInput{PreviousTransactionOutput: output(address, value)}
+	}
+
+	tests := []struct {
+		name                   string
+		tx                     *dbmodels.Transaction
+		expectedNetValueChange int64
+	}{
+		{
+			name: "incoming",
+			tx: &dbmodels.Transaction{
+				TransactionInputs:  []dbmodels.TransactionInput{input(otherAddress, 100)},
+				TransactionOutputs: []dbmodels.TransactionOutput{output(address, 60), output(otherAddress, 30)},
+			},
+			expectedNetValueChange: 60,
+		},
+		{
+			name: "outgoing with change",
+			tx: &dbmodels.Transaction{
+				TransactionInputs:  []dbmodels.TransactionInput{input(address, 70), input(address, 30)},
+				TransactionOutputs: []dbmodels.TransactionOutput{output(otherAddress, 80), output(address, 15)},
+			},
+			expectedNetValueChange: -85,
+		},
+		{
+			name: "coinbase",
+			tx: &dbmodels.Transaction{
+				TransactionOutputs: []dbmodels.TransactionOutput{output(address, 50)},
+			},
+			expectedNetValueChange: 50,
+		},
+		{
+			name: "output without an address",
+			tx: &dbmodels.Transaction{
+				TransactionInputs: []dbmodels.TransactionInput{input(address, 10)},
+				TransactionOutputs: []dbmodels.TransactionOutput{
+					{Value: 9},
+				},
+			},
+			expectedNetValueChange: -10,
+		},
+		{
+			name: "unrelated",
+			tx: &dbmodels.Transaction{
+				TransactionInputs:  []dbmodels.TransactionInput{input(otherAddress, 10)},
+				TransactionOutputs: []dbmodels.TransactionOutput{output(otherAddress, 9)},
+			},
+			expectedNetValueChange: 0,
+		},
+	}
+
+	for _, test := range tests {
+		netValueChange := addressNetValueChange(test.tx, address)
+		if netValueChange != test.expectedNetValueChange {
+			t.Errorf("%s: Expected net value change %d but got %d", test.name,
+				test.expectedNetValueChange, netValueChange)
+		}
+	}
+}
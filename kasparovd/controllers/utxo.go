@@ -34,5 +34,9 @@ func GetUTXOsByAddressHandler(address string) (interface{}, error) {
 			return nil, err
 		}
 	}
+	err = addSpendersToOutputResponses(transactionOutputs, UTXOsResponses)
+	if err != nil {
+		return nil, err
+	}
 	return UTXOsResponses, nil
 }
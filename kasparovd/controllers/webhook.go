@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	webhookSubscriptionIDSize     = 16
+	webhookSubscriptionSecretSize = 32
+
+	// maxWebhookConfirmationThreshold is the highest confirmation
+	// threshold that a webhook subscription may have
+	maxWebhookConfirmationThreshold = 10000
+
+	maxGetWebhookDeliveriesLimit = 100
+)
+
+// PostWebhookSubscriptionHandler creates a webhook subscription that watches
+// the given addresses. Only clients with one of the configured API keys may
+// create subscriptions, and only the API key that created a subscription may
+// access it afterwards. The response contains the secret that the deliveries
+// of the subscription are signed with, which isn't returned again.
+func PostWebhookSubscriptionHandler(apiKey string, requestBody []byte) (interface{}, error) {
+	err := validateWebhookAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &apimodels.WebhookSubscriptionRequest{}
+	err = json.Unmarshal(requestBody, request)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"the request body is not json-formatted")
+	}
+
+	err = validateWebhookURL(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ConfirmationThreshold > maxWebhookConfirmationThreshold || request.ConfirmationThreshold < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("confirmation threshold higher than %d or lower than 1 was requested",
+				maxWebhookConfirmationThreshold))
+	}
+
+	if len(request.Addresses) > maxBatchSize || len(request.Addresses) < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("more than %d or less than 1 addresses were requested", maxBatchSize))
+	}
+	addresses := make([]string, 0, len(request.Addresses))
+	addressSet := make(map[string]struct{}, len(request.Addresses))
+	for _, address := range request.Addresses {
+		err := validateAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := addressSet[address]; ok {
+			continue
+		}
+		addressSet[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+
+	subscriptionID, err := randomHexString(webhookSubscriptionIDSize)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHexString(webhookSubscriptionSecretSize)
+	if err != nil {
+		return nil, err
+	}
+	subscription := &dbmodels.WebhookSubscription{
+		ID:                    subscriptionID,
+		URL:                   request.URL,
+		Secret:                secret,
+		APIKey:                apiKey,
+		ConfirmationThreshold: request.ConfirmationThreshold,
+		CreatedAt:             time.Now(),
+	}
+
+	dbTx, err := database.NewTx()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.RollbackUnlessCommitted()
+
+	err = dbaccess.InsertWebhookSubscription(dbTx, subscription, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertWebhookSubscriptionToResponse(subscription, addresses)
+	response.Secret = subscription.Secret
+	return response, nil
+}
+
+// GetWebhookSubscriptionHandler returns the webhook subscription with the
+// given ID, if it was created with the given API key
+func GetWebhookSubscriptionHandler(apiKey string, subscriptionID string) (interface{}, error) {
+	subscription, err := webhookSubscriptionByID(apiKey, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := dbaccess.WebhookSubscriptionAddresses(database.NoTx(), subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertWebhookSubscriptionToResponse(subscription, addresses), nil
+}
+
+// DeleteWebhookSubscriptionHandler deletes the webhook subscription with the
+// given ID, along with its delivery log, if it was created with the given API key
+func DeleteWebhookSubscriptionHandler(apiKey string, subscriptionID string) (interface{}, error) {
+	subscription, err := webhookSubscriptionByID(apiKey, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := dbaccess.DeleteWebhookSubscription(database.NoTx(), subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, webhookSubscriptionNotFoundError()
+	}
+	return nil, nil
+}
+
+// GetWebhookDeliveriesHandler returns the delivery log of the webhook subscription
+// with the given ID, newest first, if it was created with the given API key
+func GetWebhookDeliveriesHandler(apiKey string, subscriptionID string, skip, limit int64) (interface{}, error) {
+	if limit > maxGetWebhookDeliveriesLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetWebhookDeliveriesLimit))
+	}
+
+	if skip < 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.New("skip lower than 0 was requested"))
+	}
+
+	subscription, err := webhookSubscriptionByID(apiKey, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := dbaccess.WebhookDeliveriesBySubscriptionID(database.NoTx(), subscription.ID,
+		uint64(skip), uint64(limit), dbmodels.WebhookDeliveryFieldNames.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryResponses := make([]*apimodels.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryResponses[i] = &apimodels.WebhookDeliveryResponse{
+			ID:             delivery.ID,
+			Event:          delivery.Event,
+			Address:        delivery.Address,
+			TransactionID:  delivery.Transaction.TransactionID,
+			CreatedAt:      uint64(delivery.CreatedAt.Unix()),
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+		}
+		if delivery.NextAttemptAt != nil {
+			nextAttemptAt := uint64(delivery.NextAttemptAt.Unix())
+			deliveryResponses[i].NextAttemptAt = &nextAttemptAt
+		}
+		if delivery.DeliveredAt != nil {
+			deliveredAt := uint64(delivery.DeliveredAt.Unix())
+			deliveryResponses[i].DeliveredAt = &deliveredAt
+		}
+	}
+	return deliveryResponses, nil
+}
+
+func validateWebhookSubscriptionID(subscriptionID string) error {
+	if !isValidHexHash(subscriptionID, webhookSubscriptionIDSize) {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("the given webhook subscription ID is not a hex-encoded %d-byte ID",
+				webhookSubscriptionIDSize))
+	}
+	return nil
+}
+
+func validateWebhookAPIKey(apiKey string) error {
+	if _, ok := config.ActiveConfig().APIKeyTiers()[apiKey]; !ok || apiKey == "" {
+		return httpserverutils.NewHandlerError(http.StatusUnauthorized,
+			errors.Errorf("webhook subscriptions require an API key in the %s header",
+				httpserverutils.APIKeyHeader))
+	}
+	return nil
+}
+
+func webhookSubscriptionNotFoundError() error {
+	return httpserverutils.NewHandlerError(http.StatusNotFound,
+		errors.New("no webhook subscription with the given ID was found"))
+}
+
+// webhookSubscriptionByID returns the webhook subscription with the given ID.
+// Subscriptions that were created with a different API key are reported as
+// not found, so that their IDs can't be probed with other keys.
+func webhookSubscriptionByID(apiKey string, subscriptionID string) (*dbmodels.WebhookSubscription, error) {
+	err := validateWebhookAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateWebhookSubscriptionID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := dbaccess.WebhookSubscriptionByID(database.NoTx(), subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil || subtle.ConstantTimeCompare([]byte(subscription.APIKey), []byte(apiKey)) != 1 {
+		return nil, webhookSubscriptionNotFoundError()
+	}
+	return subscription, nil
+}
+
+func validateWebhookURL(webhookURL string) error {
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the given webhook URL is not an absolute http or https URL"))
+	}
+	return nil
+}
+
+func randomHexString(size int) (string, error) {
+	bytes := make([]byte, size)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func convertWebhookSubscriptionToResponse(subscription *dbmodels.WebhookSubscription,
+	addresses []string) *apimodels.WebhookSubscriptionResponse {
+
+	return &apimodels.WebhookSubscriptionResponse{
+		ID:                    subscription.ID,
+		URL:                   subscription.URL,
+		Addresses:             addresses,
+		ConfirmationThreshold: subscription.ConfirmationThreshold,
+		CreatedAt:             uint64(subscription.CreatedAt.Unix()),
+	}
+}
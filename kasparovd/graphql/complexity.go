@@ -0,0 +1,190 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// databaseFieldComplexity is the additional cost of fields whose
+// resolvers query the database, rather than resolving from the
+// relations that were preloaded with their parent objects
+const databaseFieldComplexity = 10
+
+// databaseFields are the fields that cost databaseFieldComplexity, by type
+var databaseFields = map[string]map[string]struct{}{
+	"Query": {
+		"block":       {},
+		"blocks":      {},
+		"transaction": {},
+	},
+	blockTypeName: {
+		"children": {},
+	},
+	outputTypeName: {
+		"spender": {},
+	},
+	addressTypeName: {
+		"transactionCount": {},
+		"transactions":     {},
+	},
+}
+
+// complexityCalculator estimates the cost of a query before it's executed.
+// Every selected field costs 1, plus the cost of the fields selected on it.
+// The cost of a list field is multiplied by its limit argument, or by
+// maxPageLimit if it doesn't have one. Fields that query the database
+// cost an additional databaseFieldComplexity. The calculation stops as
+// soon as the cost exceeds maxComplexity.
+type complexityCalculator struct {
+	fragments     map[string]*ast.FragmentDefinition
+	variables     map[string]interface{}
+	maxComplexity int
+}
+
+// queryComplexity returns the estimated cost of the operation named
+// `operationName` in `document`, or of its only operation if
+// `operationName` is empty. Costs higher than maxComplexity are
+// reported as maxComplexity+1.
+func queryComplexity(schema gql.Schema, document *ast.Document, operationName string,
+	variables map[string]interface{}, maxComplexity int) int {
+
+	calculator := &complexityCalculator{
+		fragments:     make(map[string]*ast.FragmentDefinition),
+		variables:     variables,
+		maxComplexity: maxComplexity,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			calculator.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil || operation.Operation != ast.OperationTypeQuery {
+		// Execution will report the missing or unsupported operation
+		return 0
+	}
+
+	return calculator.selectionSetComplexity(operation.SelectionSet, schema.QueryType())
+}
+
+func (c *complexityCalculator) selectionSetComplexity(selectionSet *ast.SelectionSet, objectType *gql.Object) int {
+	if selectionSet == nil || objectType == nil {
+		return 0
+	}
+
+	complexity := 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			complexity += c.fieldComplexity(selection, objectType)
+		case *ast.InlineFragment:
+			complexity += c.selectionSetComplexity(selection.SelectionSet, objectType)
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[selection.Name.Value]; ok {
+				complexity += c.selectionSetComplexity(fragment.SelectionSet, objectType)
+			}
+		}
+		if complexity > c.maxComplexity {
+			return c.maxComplexity + 1
+		}
+	}
+	return complexity
+}
+
+func (c *complexityCalculator) fieldComplexity(field *ast.Field, objectType *gql.Object) int {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		// Introspection fields are cheap, since they don't access the database
+		return 1
+	}
+	fieldDefinition, ok := objectType.Fields()[field.Name.Value]
+	if !ok {
+		return 1
+	}
+
+	fieldType, _ := gql.GetNamed(fieldDefinition.Type).(*gql.Object)
+	complexity := 1 + c.selectionSetComplexity(field.SelectionSet, fieldType)
+	if isListType(fieldDefinition.Type) {
+		multiplier := maxPageLimit
+		if limit, ok := c.limitArgument(field, fieldDefinition); ok {
+			multiplier = limit
+		}
+		if complexity > c.maxComplexity/multiplier {
+			return c.maxComplexity + 1
+		}
+		complexity *= multiplier
+	}
+	if _, ok := databaseFields[objectType.Name()][field.Name.Value]; ok {
+		complexity += databaseFieldComplexity
+	}
+	if complexity > c.maxComplexity {
+		return c.maxComplexity + 1
+	}
+	return complexity
+}
+
+// limitArgument returns the limit argument of the given list field,
+// as given in the query or as defaulted by the schema
+func (c *complexityCalculator) limitArgument(field *ast.Field, fieldDefinition *gql.FieldDefinition) (int, bool) {
+	var limit interface{}
+	for _, argument := range fieldDefinition.Args {
+		if argument.Name() == "limit" {
+			limit = argument.DefaultValue
+		}
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit = value.Value
+		case *ast.Variable:
+			if variable, ok := c.variables[value.Name.Value]; ok {
+				limit = variable
+			}
+		}
+	}
+
+	var limitValue int
+	switch limit := limit.(type) {
+	case int:
+		limitValue = limit
+	case float64:
+		limitValue = int(limit)
+	case string:
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return 0, false
+		}
+		limitValue = parsed
+	default:
+		return 0, false
+	}
+	if limitValue < 1 {
+		// Such a limit is rejected when the field is resolved
+		limitValue = 1
+	}
+	return limitValue, true
+}
+
+func isListType(fieldType gql.Type) bool {
+	for {
+		switch wrappedType := fieldType.(type) {
+		case *gql.NonNull:
+			fieldType = wrappedType.OfType
+		case *gql.List:
+			return true
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/pkg/errors"
+)
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+var (
+	schema     gql.Schema
+	schemaErr  error
+	schemaOnce sync.Once
+)
+
+type contextKey int
+
+const requestStateContextKey contextKey = iota
+
+// PostGraphQLHandler executes the GraphQL query in the request body.
+// Syntax, validation and execution errors are reported in the
+// errors field of the result, as the GraphQL spec requires.
+func PostGraphQLHandler(ctx context.Context, requestBody []byte) (interface{}, error) {
+	schemaOnce.Do(func() {
+		schema, schemaErr = newSchema()
+	})
+	if schemaErr != nil {
+		return nil, schemaErr
+	}
+
+	request := &Request{}
+	err := json.Unmarshal(requestBody, request)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"the request body is not json-formatted")
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(request.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+
+	validationResult := gql.ValidateDocument(&schema, document, nil)
+	if !validationResult.IsValid {
+		return &gql.Result{Errors: validationResult.Errors}, nil
+	}
+
+	maxComplexity := config.ActiveConfig().GraphQLMaxComplexity
+	complexity := queryComplexity(schema, document, request.OperationName, request.Variables, maxComplexity)
+	if complexity > maxComplexity {
+		return &gql.Result{
+			Errors: gqlerrors.FormatErrors(errors.Errorf("the query is too complex: its complexity "+
+				"is higher than the maximum of %d", maxComplexity)),
+		}, nil
+	}
+
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       context.WithValue(ctx, requestStateContextKey, &requestState{}),
+	}), nil
+}
+
+// requestState holds data that is shared by the resolvers of a single request
+type requestState struct {
+	selectedTipBlueScoreOnce sync.Once
+	selectedTipBlueScore     uint64
+	selectedTipBlueScoreErr  error
+
+	batchLoadersLock sync.Mutex
+	batchLoaders     map[string]*batchLoader
+}
+
+// requestSelectedTipBlueScore returns the selected tip blue score, which is
+// fetched once per request so that all the confirmations in the result are
+// calculated against the same selected tip
+func requestSelectedTipBlueScore(ctx context.Context) (uint64, error) {
+	state, ok := ctx.Value(requestStateContextKey).(*requestState)
+	if !ok {
+		return dbaccess.SelectedTipBlueScore(database.NoTx())
+	}
+	state.selectedTipBlueScoreOnce.Do(func() {
+		state.selectedTipBlueScore, state.selectedTipBlueScoreErr = dbaccess.SelectedTipBlueScore(database.NoTx())
+	})
+	return state.selectedTipBlueScore, state.selectedTipBlueScoreErr
+}
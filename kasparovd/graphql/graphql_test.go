@@ -0,0 +1,200 @@
+package graphql
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+func parseQuery(t *testing.T, query string) *ast.Document {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("parser.Parse: %s", err)
+	}
+	return document
+}
+
+func TestPreloadedFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		typeName       string
+		expectedFields []dbmodels.FieldName
+	}{
+		{
+			name:           "scalars only",
+			query:          `{ block(hash: "00") { hash blueScore children { hash } } }`,
+			typeName:       blockTypeName,
+			expectedFields: nil,
+		},
+		{
+			name: "nested relations",
+			query: `{ transaction(id: "00") {
+				confirmations status
+				outputs { value address { address } spender { index } }
+				inputs { previousOutput { transaction { id acceptingBlock { hash } } } }
+			} }`,
+			typeName: transactionTypeName,
+			expectedFields: []dbmodels.FieldName{
+				"AcceptingBlock",
+				"TransactionOutputs",
+				"TransactionOutputs.Address",
+				"TransactionInputs",
+				"TransactionInputs.PreviousTransactionOutput",
+				"TransactionInputs.PreviousTransactionOutput.Transaction",
+				"TransactionInputs.PreviousTransactionOutput.Transaction.AcceptingBlock",
+			},
+		},
+		{
+			name: "fragments",
+			query: `{ block(hash: "00") { ...parents ... on Block { transactions { raw } } } }
+				fragment parents on Block { parents { acceptingBlock { hash } } }`,
+			typeName: blockTypeName,
+			expectedFields: []dbmodels.FieldName{
+				"ParentBlocks",
+				"ParentBlocks.AcceptingBlock",
+				"Transactions",
+				"Transactions.RawTransaction",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		document := parseQuery(t, test.query)
+		info := gql.ResolveInfo{Fragments: make(map[string]ast.Definition)}
+		for _, definition := range document.Definitions {
+			switch definition := definition.(type) {
+			case *ast.OperationDefinition:
+				info.FieldASTs = []*ast.Field{definition.SelectionSet.Selections[0].(*ast.Field)}
+			case *ast.FragmentDefinition:
+				info.Fragments[definition.Name.Value] = definition
+			}
+		}
+
+		fields := preloadedFields(info, test.typeName)
+		if !reflect.DeepEqual(fields, test.expectedFields) {
+			t.Errorf("%s: Expected preloaded fields %v but got %v", test.name, test.expectedFields, fields)
+		}
+	}
+}
+
+func TestQueryComplexity(t *testing.T) {
+	schema, err := newSchema()
+	if err != nil {
+		t.Fatalf("newSchema: %s", err)
+	}
+
+	const maxComplexity = 1000
+	tests := []struct {
+		name               string
+		query              string
+		operationName      string
+		variables          map[string]interface{}
+		expectedComplexity int
+	}{
+		{
+			name:               "single field",
+			query:              `{ block(hash: "00") { hash } }`,
+			expectedComplexity: 2 + databaseFieldComplexity,
+		},
+		{
+			name:               "relation with a default limit",
+			query:              `{ block(hash: "00") { parents { hash } } }`,
+			expectedComplexity: 1 + defaultPageLimit*2 + databaseFieldComplexity,
+		},
+		{
+			name:               "default limit",
+			query:              `{ blocks { hash blueScore } }`,
+			expectedComplexity: defaultPageLimit*3 + databaseFieldComplexity,
+		},
+		{
+			name:               "literal limit",
+			query:              `{ blocks(limit: 5) { hash transactions(limit: 2) { id } } }`,
+			expectedComplexity: 5*(1+1+2*2) + databaseFieldComplexity,
+		},
+		{
+			name:               "variable limit",
+			query:              `query Q($limit: Int) { blocks(limit: $limit) { hash } }`,
+			variables:          map[string]interface{}{"limit": float64(3)},
+			expectedComplexity: 3*2 + databaseFieldComplexity,
+		},
+		{
+			name: "fragments and operation name",
+			query: `query A { blocks(limit: 2) { ...f } } query B { blocks(limit: 4) { ...f } }
+				fragment f on Block { hash ... on Block { blueScore } }`,
+			operationName:      "B",
+			expectedComplexity: 4*3 + databaseFieldComplexity,
+		},
+		{
+			name:               "nested database fields",
+			query:              `{ transaction(id: "00") { outputs(limit: 2) { spender { index } } } }`,
+			expectedComplexity: 1 + 2*(1+2+databaseFieldComplexity) + databaseFieldComplexity,
+		},
+		{
+			name:               "introspection",
+			query:              `{ __schema { types { name fields { name } } } }`,
+			expectedComplexity: 1,
+		},
+		{
+			name:               "too complex",
+			query:              `{ blocks(limit: 100) { parents { parents { hash } } } }`,
+			expectedComplexity: maxComplexity + 1,
+		},
+		{
+			name:               "default limits of nested lists",
+			query:              `{ blocks(limit: 3) { transactions { outputs { spender { index } } } } }`,
+			expectedComplexity: maxComplexity + 1,
+		},
+	}
+
+	for _, test := range tests {
+		document := parseQuery(t, test.query)
+		complexity := queryComplexity(schema, document, test.operationName, test.variables, maxComplexity)
+		if complexity != test.expectedComplexity {
+			t.Errorf("%s: Expected complexity %d but got %d", test.name, test.expectedComplexity, complexity)
+		}
+	}
+}
+
+func TestBatchLoader(t *testing.T) {
+	var fetchedBatches [][]uint64
+	loader := newBatchLoader(func(ids []uint64) (map[uint64]interface{}, error) {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		fetchedBatches = append(fetchedBatches, ids)
+		results := make(map[uint64]interface{}, len(ids))
+		for _, id := range ids {
+			results[id] = id * 10
+		}
+		return results, nil
+	})
+
+	thunks := []func() (interface{}, error){loader.load(1), loader.load(2), loader.load(2)}
+	for i, thunk := range thunks {
+		result, err := thunk()
+		if err != nil {
+			t.Fatalf("thunk %d: %s", i, err)
+		}
+		expectedResult := uint64([]int{10, 20, 20}[i])
+		if result != expectedResult {
+			t.Errorf("thunk %d: Expected result %d but got %v", i, expectedResult, result)
+		}
+	}
+
+	result, err := loader.load(3)()
+	if err != nil {
+		t.Fatalf("thunk of 3: %s", err)
+	}
+	if result != uint64(30) {
+		t.Errorf("thunk of 3: Expected result 30 but got %v", result)
+	}
+
+	expectedBatches := [][]uint64{{1, 2}, {3}}
+	if !reflect.DeepEqual(fetchedBatches, expectedBatches) {
+		t.Errorf("Expected fetched batches %v but got %v", expectedBatches, fetchedBatches)
+	}
+}
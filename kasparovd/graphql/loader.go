@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// batchLoader batches the database lookups of a single field across all
+// the objects of a request. Resolvers add the ID of their object with load,
+// and the first call to one of the returned thunks fetches the results of
+// all the IDs that were added so far with a single query. Since thunks are
+// called breadth-first, all the objects in the same level of the result
+// are fetched together.
+type batchLoader struct {
+	sync.Mutex
+	fetch      func(ids []uint64) (map[uint64]interface{}, error)
+	pendingIDs map[uint64]struct{}
+	results    map[uint64]interface{}
+	err        error
+}
+
+func newBatchLoader(fetch func(ids []uint64) (map[uint64]interface{}, error)) *batchLoader {
+	return &batchLoader{
+		fetch:      fetch,
+		pendingIDs: make(map[uint64]struct{}),
+		results:    make(map[uint64]interface{}),
+	}
+}
+
+// load adds `id` to the next batch, and returns a thunk that
+// returns its result once the batch is fetched
+func (l *batchLoader) load(id uint64) func() (interface{}, error) {
+	l.Lock()
+	defer l.Unlock()
+	if _, ok := l.results[id]; !ok {
+		l.pendingIDs[id] = struct{}{}
+	}
+
+	return func() (interface{}, error) {
+		l.Lock()
+		defer l.Unlock()
+		if l.err != nil {
+			return nil, l.err
+		}
+		if result, ok := l.results[id]; ok {
+			return result, nil
+		}
+
+		ids := make([]uint64, 0, len(l.pendingIDs))
+		for pendingID := range l.pendingIDs {
+			ids = append(ids, pendingID)
+		}
+		l.pendingIDs = make(map[uint64]struct{})
+		results, err := l.fetch(ids)
+		if err != nil {
+			l.err = err
+			return nil, err
+		}
+		for _, fetchedID := range ids {
+			l.results[fetchedID] = results[fetchedID]
+		}
+		return l.results[id], nil
+	}
+}
+
+// requestBatchLoader returns the batch loader of the current request that's
+// identified by `name` and by the dbmodels fields that are preloaded on the
+// fetched objects, and creates it if it doesn't exist yet
+func requestBatchLoader(ctx context.Context, name string, preloadedFields []dbmodels.FieldName,
+	fetch func(ids []uint64) (map[uint64]interface{}, error)) *batchLoader {
+
+	state, ok := ctx.Value(requestStateContextKey).(*requestState)
+	if !ok {
+		return newBatchLoader(fetch)
+	}
+
+	key := name
+	for _, fieldName := range preloadedFields {
+		key += "," + string(fieldName)
+	}
+
+	state.batchLoadersLock.Lock()
+	defer state.batchLoadersLock.Unlock()
+	if state.batchLoaders == nil {
+		state.batchLoaders = make(map[string]*batchLoader)
+	}
+	loader, ok := state.batchLoaders[key]
+	if !ok {
+		loader = newBatchLoader(fetch)
+		state.batchLoaders[key] = loader
+	}
+	return loader
+}
@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// relation maps a GraphQL field onto the dbmodels field that
+// has to be preloaded in order to resolve it
+type relation struct {
+	fieldName dbmodels.FieldName
+
+	// typeName is the GraphQL type of the preloaded field, whose
+	// selected fields may require preloading nested fields. It's
+	// empty if the preloaded field is only used to compute a scalar.
+	typeName string
+}
+
+// relations maps the fields of every GraphQL type that are resolved from
+// preloaded dbmodels fields onto these fields. Relations that aren't
+// preloaded, such as the children of a block, are fetched by their own
+// resolvers.
+var relations = map[string]map[string]relation{
+	blockTypeName: {
+		"confirmations":  {fieldName: dbmodels.BlockFieldNames.AcceptingBlock},
+		"acceptingBlock": {fieldName: dbmodels.BlockFieldNames.AcceptingBlock, typeName: blockTypeName},
+		"parents":        {fieldName: dbmodels.BlockFieldNames.ParentBlocks, typeName: blockTypeName},
+		"acceptedBlocks": {fieldName: dbmodels.BlockFieldNames.AcceptedBlocks, typeName: blockTypeName},
+		"transactions":   {fieldName: dbmodels.BlockFieldNames.Transactions, typeName: transactionTypeName},
+	},
+	transactionTypeName: {
+		"raw":            {fieldName: dbmodels.TransactionFieldNames.RawTransaction},
+		"status":         {fieldName: dbmodels.TransactionFieldNames.AcceptingBlock},
+		"confirmations":  {fieldName: dbmodels.TransactionFieldNames.AcceptingBlock},
+		"acceptingBlock": {fieldName: dbmodels.TransactionFieldNames.AcceptingBlock, typeName: blockTypeName},
+		"subnetwork":     {fieldName: dbmodels.TransactionFieldNames.Subnetwork, typeName: subnetworkTypeName},
+		"blocks":         {fieldName: dbmodels.TransactionFieldNames.Blocks, typeName: blockTypeName},
+		"inputs":         {fieldName: dbmodels.TransactionFieldNames.TransactionInputs, typeName: inputTypeName},
+		"outputs":        {fieldName: dbmodels.TransactionFieldNames.TransactionOutputs, typeName: outputTypeName},
+	},
+	outputTypeName: {
+		"address":     {fieldName: dbmodels.TransactionOutputFieldNames.Address, typeName: addressTypeName},
+		"transaction": {fieldName: dbmodels.TransactionOutputFieldNames.Transaction, typeName: transactionTypeName},
+	},
+	inputTypeName: {
+		"transaction":    {fieldName: dbmodels.TransactionInputFieldNames.Transaction, typeName: transactionTypeName},
+		"previousOutput": {fieldName: dbmodels.TransactionInputFieldNames.PreviousTransactionOutput, typeName: outputTypeName},
+	},
+}
+
+// preloadedFields returns the dbmodels fields that have to be preloaded
+// for the objects of type `typeName` that are returned by the resolved
+// field, in order to resolve all the fields selected on them.
+func preloadedFields(info gql.ResolveInfo, typeName string) []dbmodels.FieldName {
+	collector := &preloadCollector{
+		fragments: info.Fragments,
+		seen:      make(map[dbmodels.FieldName]struct{}),
+	}
+	for _, field := range info.FieldASTs {
+		collector.collect(field.SelectionSet, typeName, "")
+	}
+	return collector.fieldNames
+}
+
+type preloadCollector struct {
+	fragments  map[string]ast.Definition
+	seen       map[dbmodels.FieldName]struct{}
+	fieldNames []dbmodels.FieldName
+}
+
+func (c *preloadCollector) collect(selectionSet *ast.SelectionSet, typeName string, prefix dbmodels.FieldName) {
+	if selectionSet == nil {
+		return
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			relation, ok := relations[typeName][selection.Name.Value]
+			if !ok {
+				continue
+			}
+			fieldName := relation.fieldName
+			if prefix != "" {
+				fieldName = dbmodels.PrefixFieldNames(prefix, []dbmodels.FieldName{fieldName})[0]
+			}
+			c.add(fieldName)
+			if relation.typeName != "" {
+				c.collect(selection.SelectionSet, relation.typeName, fieldName)
+			}
+		case *ast.InlineFragment:
+			c.collect(selection.SelectionSet, typeName, prefix)
+		case *ast.FragmentSpread:
+			fragment, ok := c.fragments[selection.Name.Value].(*ast.FragmentDefinition)
+			if ok {
+				c.collect(fragment.SelectionSet, typeName, prefix)
+			}
+		}
+	}
+}
+
+func (c *preloadCollector) add(fieldName dbmodels.FieldName) {
+	if _, ok := c.seen[fieldName]; ok {
+		return
+	}
+	c.seen[fieldName] = struct{}{}
+	c.fieldNames = append(c.fieldNames, fieldName)
+}
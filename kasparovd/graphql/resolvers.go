@@ -0,0 +1,396 @@
+package graphql
+
+import (
+	"encoding/hex"
+	"sort"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/kaspanet/kasparov/serializer"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPageLimit = 25
+	maxPageLimit     = 100
+)
+
+func resolveBlock(p gql.ResolveParams) (interface{}, error) {
+	blockHash := p.Args["hash"].(string)
+	if !isValidHexHash(blockHash) {
+		return nil, errors.Errorf("the given block hash is not a hex-encoded %d-byte hash", hashSize)
+	}
+	block, err := dbaccess.BlockByHash(database.NoTx(), blockHash, preloadedFields(p.Info, blockTypeName)...)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func resolveBlocks(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return dbaccess.Blocks(database.NoTx(), dbaccess.OrderDescending, skip, limit,
+		preloadedFields(p.Info, blockTypeName)...)
+}
+
+func resolveTransaction(p gql.ResolveParams) (interface{}, error) {
+	transactionID, hasID := p.Args["id"].(string)
+	transactionHash, hasHash := p.Args["hash"].(string)
+	if hasID == hasHash {
+		return nil, errors.New("exactly one of id and hash must be given")
+	}
+
+	preloadedFields := preloadedFields(p.Info, transactionTypeName)
+	var transaction *dbmodels.Transaction
+	var err error
+	if hasID {
+		if !isValidHexHash(transactionID) {
+			return nil, errors.Errorf("the given transaction ID is not a hex-encoded %d-byte hash", hashSize)
+		}
+		transaction, err = dbaccess.TransactionByID(database.NoTx(), transactionID, preloadedFields...)
+	} else {
+		if !isValidHexHash(transactionHash) {
+			return nil, errors.Errorf("the given transaction hash is not a hex-encoded %d-byte hash", hashSize)
+		}
+		transaction, err = dbaccess.TransactionByHash(database.NoTx(), transactionHash, preloadedFields...)
+	}
+	if err != nil || transaction == nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func resolveAddress(p gql.ResolveParams) (interface{}, error) {
+	address := p.Args["address"].(string)
+	_, err := util.DecodeAddress(address, config.ActiveConfig().ActiveNetParams.Prefix)
+	if err != nil {
+		return nil, errors.New("the given address is not a well-formatted P2PKH or P2SH address")
+	}
+	return &dbmodels.Address{Address: address}, nil
+}
+
+func resolveBlockHash(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(*dbmodels.Block).BlockHash, nil
+}
+
+func resolveBlockTimestamp(p gql.ResolveParams) (interface{}, error) {
+	return uint64(p.Source.(*dbmodels.Block).Timestamp.Unix()), nil
+}
+
+func resolveBlockNonce(p gql.ResolveParams) (interface{}, error) {
+	return serializer.BytesToUint64(p.Source.(*dbmodels.Block).Nonce), nil
+}
+
+func resolveBlockConfirmations(p gql.ResolveParams) (interface{}, error) {
+	return confirmations(p, p.Source.(*dbmodels.Block).AcceptingBlock)
+}
+
+func resolveBlockAcceptingBlock(p gql.ResolveParams) (interface{}, error) {
+	return nullableBlock(p.Source.(*dbmodels.Block).AcceptingBlock), nil
+}
+
+func resolveBlockParents(p gql.ResolveParams) (interface{}, error) {
+	return blocksPage(p.Args, p.Source.(*dbmodels.Block).ParentBlocks)
+}
+
+func resolveBlockChildren(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	preloadedFields := preloadedFields(p.Info, blockTypeName)
+	loader := requestBatchLoader(p.Context, "children", preloadedFields, func(blockIDs []uint64) (map[uint64]interface{}, error) {
+		childrenByParentBlockID, err := dbaccess.ChildBlocksByParentBlockIDs(database.NoTx(), blockIDs, preloadedFields...)
+		if err != nil {
+			return nil, err
+		}
+		children := make(map[uint64]interface{}, len(blockIDs))
+		for _, blockID := range blockIDs {
+			children[blockID] = childrenByParentBlockID[blockID]
+		}
+		return children, nil
+	})
+	loadChildren := loader.load(p.Source.(*dbmodels.Block).ID)
+	return func() (interface{}, error) {
+		children, err := loadChildren()
+		if err != nil {
+			return nil, err
+		}
+		start, end := pageBounds(skip, limit, len(children.([]*dbmodels.Block)))
+		return children.([]*dbmodels.Block)[start:end], nil
+	}, nil
+}
+
+func resolveBlockAcceptedBlocks(p gql.ResolveParams) (interface{}, error) {
+	return blocksPage(p.Args, p.Source.(*dbmodels.Block).AcceptedBlocks)
+}
+
+func resolveBlockTransactions(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	transactions := append([]*dbmodels.Transaction(nil), p.Source.(*dbmodels.Block).Transactions...)
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].ID < transactions[j].ID
+	})
+	start, end := pageBounds(skip, limit, len(transactions))
+	return transactions[start:end], nil
+}
+
+func resolveTransactionHash(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(*dbmodels.Transaction).TransactionHash, nil
+}
+
+func resolveTransactionID(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(*dbmodels.Transaction).TransactionID, nil
+}
+
+func resolveTransactionLockTime(p gql.ResolveParams) (interface{}, error) {
+	return serializer.BytesToUint64(p.Source.(*dbmodels.Transaction).LockTime), nil
+}
+
+func resolveTransactionPayload(p gql.ResolveParams) (interface{}, error) {
+	return hex.EncodeToString(p.Source.(*dbmodels.Transaction).Payload), nil
+}
+
+func resolveTransactionRaw(p gql.ResolveParams) (interface{}, error) {
+	transaction := p.Source.(*dbmodels.Transaction)
+	if transaction.RawTransaction == nil {
+		return nil, errors.Errorf("missing raw transaction of transaction %s", transaction.TransactionID)
+	}
+	return hex.EncodeToString(transaction.RawTransaction.TransactionData), nil
+}
+
+func resolveTransactionStatus(p gql.ResolveParams) (interface{}, error) {
+	if p.Source.(*dbmodels.Transaction).AcceptingBlock != nil {
+		return apimodels.TransactionStatusAccepted, nil
+	}
+	return apimodels.TransactionStatusIncluded, nil
+}
+
+func resolveTransactionConfirmations(p gql.ResolveParams) (interface{}, error) {
+	return confirmations(p, p.Source.(*dbmodels.Transaction).AcceptingBlock)
+}
+
+func resolveTransactionAcceptingBlock(p gql.ResolveParams) (interface{}, error) {
+	return nullableBlock(p.Source.(*dbmodels.Transaction).AcceptingBlock), nil
+}
+
+func resolveTransactionSubnetwork(p gql.ResolveParams) (interface{}, error) {
+	return &p.Source.(*dbmodels.Transaction).Subnetwork, nil
+}
+
+func resolveTransactionBlocks(p gql.ResolveParams) (interface{}, error) {
+	transaction := p.Source.(*dbmodels.Transaction)
+	blocks := make([]*dbmodels.Block, len(transaction.Blocks))
+	for i := range transaction.Blocks {
+		blocks[i] = &transaction.Blocks[i]
+	}
+	return blocksPage(p.Args, blocks)
+}
+
+func resolveTransactionInputs(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	transaction := p.Source.(*dbmodels.Transaction)
+	inputs := make([]*dbmodels.TransactionInput, len(transaction.TransactionInputs))
+	for i := range transaction.TransactionInputs {
+		inputs[i] = &transaction.TransactionInputs[i]
+	}
+	sort.Slice(inputs, func(i, j int) bool {
+		return inputs[i].Index < inputs[j].Index
+	})
+	start, end := pageBounds(skip, limit, len(inputs))
+	return inputs[start:end], nil
+}
+
+func resolveTransactionOutputs(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	transaction := p.Source.(*dbmodels.Transaction)
+	outputs := make([]*dbmodels.TransactionOutput, len(transaction.TransactionOutputs))
+	for i := range transaction.TransactionOutputs {
+		outputs[i] = &transaction.TransactionOutputs[i]
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		return outputs[i].Index < outputs[j].Index
+	})
+	start, end := pageBounds(skip, limit, len(outputs))
+	return outputs[start:end], nil
+}
+
+func resolveOutputScriptPubKey(p gql.ResolveParams) (interface{}, error) {
+	return hex.EncodeToString(p.Source.(*dbmodels.TransactionOutput).ScriptPubKey), nil
+}
+
+func resolveOutputAddress(p gql.ResolveParams) (interface{}, error) {
+	address := p.Source.(*dbmodels.TransactionOutput).Address
+	if address == nil {
+		return nil, nil
+	}
+	return address, nil
+}
+
+func resolveOutputTransaction(p gql.ResolveParams) (interface{}, error) {
+	return &p.Source.(*dbmodels.TransactionOutput).Transaction, nil
+}
+
+func resolveOutputSpender(p gql.ResolveParams) (interface{}, error) {
+	preloadedFields := dedupFieldNames(append(preloadedFields(p.Info, inputTypeName),
+		dbmodels.TransactionInputFieldNames.Transaction, dbmodels.TransactionInputFieldNames.TransactionAcceptingBlock))
+	loader := requestBatchLoader(p.Context, "spender", preloadedFields, func(outputIDs []uint64) (map[uint64]interface{}, error) {
+		inputs, err := dbaccess.TransactionInputsByPreviousTransactionOutputIDs(database.NoTx(),
+			outputIDs, preloadedFields...)
+		if err != nil {
+			return nil, err
+		}
+		spenders := make(map[uint64]*dbmodels.TransactionInput, len(outputIDs))
+		for _, input := range inputs {
+			spender, ok := spenders[input.PreviousTransactionOutputID]
+			if !ok || (spender.Transaction.AcceptingBlock == nil && input.Transaction.AcceptingBlock != nil) {
+				spenders[input.PreviousTransactionOutputID] = input
+			}
+		}
+		results := make(map[uint64]interface{}, len(outputIDs))
+		for _, outputID := range outputIDs {
+			if spender, ok := spenders[outputID]; ok {
+				results[outputID] = spender
+			} else {
+				// An untyped nil, which GraphQL resolves to null
+				results[outputID] = nil
+			}
+		}
+		return results, nil
+	})
+	return loader.load(p.Source.(*dbmodels.TransactionOutput).ID), nil
+}
+
+func resolveInputSignatureScript(p gql.ResolveParams) (interface{}, error) {
+	return hex.EncodeToString(p.Source.(*dbmodels.TransactionInput).SignatureScript), nil
+}
+
+func resolveInputSequence(p gql.ResolveParams) (interface{}, error) {
+	return serializer.BytesToUint64(p.Source.(*dbmodels.TransactionInput).Sequence), nil
+}
+
+func resolveInputTransaction(p gql.ResolveParams) (interface{}, error) {
+	return &p.Source.(*dbmodels.TransactionInput).Transaction, nil
+}
+
+func resolveInputPreviousOutput(p gql.ResolveParams) (interface{}, error) {
+	return &p.Source.(*dbmodels.TransactionInput).PreviousTransactionOutput, nil
+}
+
+func resolveAddressTransactionCount(p gql.ResolveParams) (interface{}, error) {
+	return dbaccess.TransactionsByAddressCount(database.NoTx(), p.Source.(*dbmodels.Address).Address, nil)
+}
+
+func resolveAddressTransactions(p gql.ResolveParams) (interface{}, error) {
+	skip, limit, err := pageArgsValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return dbaccess.TransactionsByAddress(database.NoTx(), p.Source.(*dbmodels.Address).Address, nil,
+		dbaccess.OrderAscending, skip, limit, preloadedFields(p.Info, transactionTypeName)...)
+}
+
+func resolveSubnetworkID(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(*dbmodels.Subnetwork).SubnetworkID, nil
+}
+
+// nullableBlock converts nil block pointers to an untyped nil,
+// which GraphQL resolves to null
+func nullableBlock(block *dbmodels.Block) interface{} {
+	if block == nil {
+		return nil
+	}
+	return block
+}
+
+func confirmations(p gql.ResolveParams, acceptingBlock *dbmodels.Block) (interface{}, error) {
+	if acceptingBlock == nil {
+		return uint64(0), nil
+	}
+	selectedTipBlueScore, err := requestSelectedTipBlueScore(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	return selectedTipBlueScore - acceptingBlock.BlueScore + 1, nil
+}
+
+func pageArgsValues(args map[string]interface{}) (skip uint64, limit uint64, err error) {
+	skipArg, _ := args["skip"].(int)
+	limitArg, _ := args["limit"].(int)
+	if skipArg < 0 {
+		return 0, 0, errors.New("skip lower than 0 was requested")
+	}
+	if limitArg > maxPageLimit || limitArg < 1 {
+		return 0, 0, errors.Errorf("limit higher than %d or lower than 1 was requested", maxPageLimit)
+	}
+	return uint64(skipArg), uint64(limitArg), nil
+}
+
+// blocksPage returns the page of `blocks` that's selected by the page
+// arguments in `args`, in the same order as dbaccess.BlocksByIDs
+func blocksPage(args map[string]interface{}, blocks []*dbmodels.Block) (interface{}, error) {
+	skip, limit, err := pageArgsValues(args)
+	if err != nil {
+		return nil, err
+	}
+	blocks = append([]*dbmodels.Block(nil), blocks...)
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].BlueScore != blocks[j].BlueScore {
+			return blocks[i].BlueScore < blocks[j].BlueScore
+		}
+		return blocks[i].ID < blocks[j].ID
+	})
+	start, end := pageBounds(skip, limit, len(blocks))
+	return blocks[start:end], nil
+}
+
+// pageBounds returns the bounds of the page that starts after `skip`
+// items and has up to `limit` items, in a list of `length` items
+func pageBounds(skip uint64, limit uint64, length int) (start int, end int) {
+	if skip >= uint64(length) {
+		return length, length
+	}
+	start = int(skip)
+	end = length
+	if limit < uint64(length-start) {
+		end = start + int(limit)
+	}
+	return start, end
+}
+
+func dedupFieldNames(fieldNames []dbmodels.FieldName) []dbmodels.FieldName {
+	seen := make(map[dbmodels.FieldName]struct{}, len(fieldNames))
+	deduped := make([]dbmodels.FieldName, 0, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		if _, ok := seen[fieldName]; ok {
+			continue
+		}
+		seen[fieldName] = struct{}{}
+		deduped = append(deduped, fieldName)
+	}
+	return deduped
+}
+
+const hashSize = 32
+
+func isValidHexHash(hash string) bool {
+	bytes, err := hex.DecodeString(hash)
+	return err == nil && len(bytes) == hashSize
+}
@@ -0,0 +1,239 @@
+package graphql
+
+import (
+	"strconv"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+// GraphQL type names
+const (
+	blockTypeName       = "Block"
+	transactionTypeName = "Transaction"
+	outputTypeName      = "Output"
+	inputTypeName       = "Input"
+	addressTypeName     = "Address"
+	subnetworkTypeName  = "Subnetwork"
+)
+
+// uint64Type is a scalar for unsigned 64-bit integers, which don't fit in the
+// 32-bit GraphQL Int type. It's serialized as a decimal string, so that clients
+// don't lose precision when parsing it.
+var uint64Type = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Uint64",
+	Description: "An unsigned 64-bit integer, serialized as a decimal string",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case uint64:
+			return strconv.FormatUint(value, 10)
+		case *uint64:
+			if value == nil {
+				return nil
+			}
+			return strconv.FormatUint(*value, 10)
+		case uint32:
+			return strconv.FormatUint(uint64(value), 10)
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if value, ok := value.(string); ok {
+			return parseUint64(value)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue:
+			return parseUint64(valueAST.Value)
+		case *ast.IntValue:
+			return parseUint64(valueAST.Value)
+		}
+		return nil
+	},
+})
+
+func parseUint64(value string) interface{} {
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// pageArgs are the arguments of fields that return a page of a list
+var pageArgs = gql.FieldConfigArgument{
+	"skip": &gql.ArgumentConfig{
+		Type:         gql.Int,
+		DefaultValue: 0,
+	},
+	"limit": &gql.ArgumentConfig{
+		Type:         gql.Int,
+		DefaultValue: defaultPageLimit,
+		Description:  "The maximum number of items to return, up to " + strconv.Itoa(maxPageLimit),
+	},
+}
+
+// newSchema builds the GraphQL schema. Fields without a resolver are resolved
+// from the dbmodels field with the same name. Fields that map onto relations of
+// dbmodels objects are resolved from the preloaded relations, which are
+// determined by the query. See relations.
+func newSchema() (gql.Schema, error) {
+	var blockType, transactionType, outputType, inputType, addressType, subnetworkType *gql.Object
+
+	nonNullString := gql.NewNonNull(gql.String)
+	nonNullInt := gql.NewNonNull(gql.Int)
+	nonNullBoolean := gql.NewNonNull(gql.Boolean)
+	nonNullUint64 := gql.NewNonNull(uint64Type)
+	listOf := func(objectType *gql.Object) gql.Output {
+		return gql.NewNonNull(gql.NewList(gql.NewNonNull(objectType)))
+	}
+
+	blockType = gql.NewObject(gql.ObjectConfig{
+		Name: blockTypeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"hash":                 &gql.Field{Type: nonNullString, Resolve: resolveBlockHash},
+				"version":              &gql.Field{Type: nonNullInt},
+				"hashMerkleRoot":       &gql.Field{Type: nonNullString},
+				"acceptedIdMerkleRoot": &gql.Field{Type: nonNullString},
+				"utxoCommitment":       &gql.Field{Type: nonNullString},
+				"timestamp":            &gql.Field{Type: nonNullUint64, Resolve: resolveBlockTimestamp},
+				"bits":                 &gql.Field{Type: nonNullUint64},
+				"nonce":                &gql.Field{Type: nonNullUint64, Resolve: resolveBlockNonce},
+				"blueScore":            &gql.Field{Type: nonNullUint64},
+				"isChainBlock":         &gql.Field{Type: nonNullBoolean},
+				"mass":                 &gql.Field{Type: nonNullUint64},
+				"confirmations":        &gql.Field{Type: nonNullUint64, Resolve: resolveBlockConfirmations},
+				"acceptingBlock":       &gql.Field{Type: blockType, Resolve: resolveBlockAcceptingBlock},
+				"parents":              &gql.Field{Type: listOf(blockType), Args: pageArgs, Resolve: resolveBlockParents},
+				"children":             &gql.Field{Type: listOf(blockType), Args: pageArgs, Resolve: resolveBlockChildren},
+				"acceptedBlocks":       &gql.Field{Type: listOf(blockType), Args: pageArgs, Resolve: resolveBlockAcceptedBlocks},
+				"transactions":         &gql.Field{Type: listOf(transactionType), Args: pageArgs, Resolve: resolveBlockTransactions},
+			}
+		}),
+	})
+
+	transactionType = gql.NewObject(gql.ObjectConfig{
+		Name: transactionTypeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"hash":           &gql.Field{Type: nonNullString, Resolve: resolveTransactionHash},
+				"id":             &gql.Field{Type: nonNullString, Resolve: resolveTransactionID},
+				"lockTime":       &gql.Field{Type: nonNullUint64, Resolve: resolveTransactionLockTime},
+				"gas":            &gql.Field{Type: nonNullUint64},
+				"payloadHash":    &gql.Field{Type: nonNullString},
+				"payload":        &gql.Field{Type: nonNullString, Resolve: resolveTransactionPayload},
+				"mass":           &gql.Field{Type: nonNullUint64},
+				"version":        &gql.Field{Type: nonNullInt},
+				"raw":            &gql.Field{Type: nonNullString, Resolve: resolveTransactionRaw},
+				"status":         &gql.Field{Type: nonNullString, Resolve: resolveTransactionStatus},
+				"confirmations":  &gql.Field{Type: nonNullUint64, Resolve: resolveTransactionConfirmations},
+				"acceptingBlock": &gql.Field{Type: blockType, Resolve: resolveTransactionAcceptingBlock},
+				"subnetwork":     &gql.Field{Type: gql.NewNonNull(subnetworkType), Resolve: resolveTransactionSubnetwork},
+				"blocks":         &gql.Field{Type: listOf(blockType), Args: pageArgs, Resolve: resolveTransactionBlocks},
+				"inputs":         &gql.Field{Type: listOf(inputType), Args: pageArgs, Resolve: resolveTransactionInputs},
+				"outputs":        &gql.Field{Type: listOf(outputType), Args: pageArgs, Resolve: resolveTransactionOutputs},
+			}
+		}),
+	})
+
+	outputType = gql.NewObject(gql.ObjectConfig{
+		Name: outputTypeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"index":        &gql.Field{Type: nonNullInt},
+				"value":        &gql.Field{Type: nonNullUint64},
+				"scriptPubKey": &gql.Field{Type: nonNullString, Resolve: resolveOutputScriptPubKey},
+				"isSpent":      &gql.Field{Type: nonNullBoolean},
+				"address":      &gql.Field{Type: addressType, Resolve: resolveOutputAddress},
+				"transaction":  &gql.Field{Type: gql.NewNonNull(transactionType), Resolve: resolveOutputTransaction},
+				"spender": &gql.Field{
+					Type:        inputType,
+					Description: "The input that spends the output. The accepted one is preferred if there are several",
+					Resolve:     resolveOutputSpender,
+				},
+			}
+		}),
+	})
+
+	inputType = gql.NewObject(gql.ObjectConfig{
+		Name: inputTypeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"index":           &gql.Field{Type: nonNullInt},
+				"signatureScript": &gql.Field{Type: nonNullString, Resolve: resolveInputSignatureScript},
+				"sequence":        &gql.Field{Type: nonNullUint64, Resolve: resolveInputSequence},
+				"transaction":     &gql.Field{Type: gql.NewNonNull(transactionType), Resolve: resolveInputTransaction},
+				"previousOutput":  &gql.Field{Type: gql.NewNonNull(outputType), Resolve: resolveInputPreviousOutput},
+			}
+		}),
+	})
+
+	addressType = gql.NewObject(gql.ObjectConfig{
+		Name: addressTypeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"address":          &gql.Field{Type: nonNullString},
+				"transactionCount": &gql.Field{Type: nonNullUint64, Resolve: resolveAddressTransactionCount},
+				"transactions": &gql.Field{
+					Type:        listOf(transactionType),
+					Description: "The transactions that pay to or spend from the address, oldest first",
+					Args:        pageArgs,
+					Resolve:     resolveAddressTransactions,
+				},
+			}
+		}),
+	})
+
+	subnetworkType = gql.NewObject(gql.ObjectConfig{
+		Name: subnetworkTypeName,
+		Fields: gql.Fields{
+			"id":       &gql.Field{Type: nonNullString, Resolve: resolveSubnetworkID},
+			"gasLimit": &gql.Field{Type: uint64Type},
+		},
+	})
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"block": &gql.Field{
+				Type: blockType,
+				Args: gql.FieldConfigArgument{
+					"hash": &gql.ArgumentConfig{Type: nonNullString},
+				},
+				Resolve: resolveBlock,
+			},
+			"blocks": &gql.Field{
+				Type:        listOf(blockType),
+				Description: "The blocks in the database, newest first",
+				Args:        pageArgs,
+				Resolve:     resolveBlocks,
+			},
+			"transaction": &gql.Field{
+				Type:        transactionType,
+				Description: "The transaction with the given ID or hash. Exactly one of them must be given",
+				Args: gql.FieldConfigArgument{
+					"id":   &gql.ArgumentConfig{Type: gql.String},
+					"hash": &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: resolveTransaction,
+			},
+			"address": &gql.Field{
+				Type: addressType,
+				Args: gql.FieldConfigArgument{
+					"address": &gql.ArgumentConfig{Type: nonNullString},
+				},
+				Resolve: resolveAddress,
+			},
+		},
+	})
+
+	schema, err := gql.NewSchema(gql.SchemaConfig{Query: queryType})
+	if err != nil {
+		return gql.Schema{}, errors.Wrap(err, "error building the GraphQL schema")
+	}
+	return schema, nil
+}
@@ -15,7 +15,10 @@ import (
 	"github.com/kaspanet/kasparov/database"
 	"github.com/kaspanet/kasparov/jsonrpc"
 	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/kaspanet/kasparov/kasparovd/notifications"
+	"github.com/kaspanet/kasparov/kasparovd/rebroadcast"
 	"github.com/kaspanet/kasparov/kasparovd/server"
+	"github.com/kaspanet/kasparov/metrics"
 	"github.com/kaspanet/kasparov/version"
 )
 
@@ -58,6 +61,23 @@ func main() {
 	}
 	defer jsonrpc.Close()
 
+	stopNotifications, err := notifications.Start()
+	if err != nil {
+		panic(errors.Errorf("Error listening to DAG events: %s", err))
+	}
+	defer stopNotifications()
+
+	if config.ActiveConfig().RebroadcastInterval != 0 {
+		stopRebroadcast := rebroadcast.Start(config.ActiveConfig().RebroadcastInterval,
+			config.ActiveConfig().RebroadcastMaxAge)
+		defer stopRebroadcast()
+	}
+
+	if config.ActiveConfig().MetricsListen != "" {
+		shutdownMetricsServer := metrics.StartServer(config.ActiveConfig().MetricsListen)
+		defer shutdownMetricsServer()
+	}
+
 	shutdownServer := server.Start(config.ActiveConfig().HTTPListen)
 	defer shutdownServer()
 
@@ -0,0 +1,146 @@
+package notifications
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/pkg/errors"
+)
+
+// maxSubscriptionsPerClient is the maximum number of
+// topics a single client may be subscribed to.
+const maxSubscriptionsPerClient = 100
+
+// hub fans out notifications to the clients that are subscribed to them
+type hub struct {
+	sync.RWMutex
+	clients     map[*client]struct{}
+	subscribers map[string]map[*client]struct{}
+}
+
+var defaultHub = &hub{
+	clients:     make(map[*client]struct{}),
+	subscribers: make(map[string]map[*client]struct{}),
+}
+
+func (h *hub) register(c *client) {
+	h.Lock()
+	defer h.Unlock()
+	h.clients[c] = struct{}{}
+}
+
+// unregister removes the client from the hub and closes its send channel.
+// It's safe to call unregister several times for the same client.
+func (h *hub) unregister(c *client) {
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	for topic := range c.topics {
+		h.removeSubscriber(topic, c)
+	}
+	delete(h.clients, c)
+	close(c.send)
+}
+
+func (h *hub) subscribe(c *client, topic string) error {
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := c.topics[topic]; ok {
+		return nil
+	}
+	if len(c.topics) >= maxSubscriptionsPerClient {
+		return errors.Errorf("cannot subscribe to more than %d topics", maxSubscriptionsPerClient)
+	}
+	c.topics[topic] = struct{}{}
+	if _, ok := h.subscribers[topic]; !ok {
+		h.subscribers[topic] = make(map[*client]struct{})
+	}
+	h.subscribers[topic][c] = struct{}{}
+	return nil
+}
+
+func (h *hub) unsubscribe(c *client, topic string) {
+	h.Lock()
+	defer h.Unlock()
+	delete(c.topics, topic)
+	h.removeSubscriber(topic, c)
+}
+
+// removeSubscriber must be called with the hub lock held
+func (h *hub) removeSubscriber(topic string, c *client) {
+	subscribers, ok := h.subscribers[topic]
+	if !ok {
+		return
+	}
+	delete(subscribers, c)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, topic)
+	}
+}
+
+// hasClients returns whether any client is connected. It's used
+// to skip building notifications when nobody would receive them.
+func (h *hub) hasClients() bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.clients) > 0
+}
+
+func (h *hub) hasSubscribers(topic string) bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.subscribers[topic]) > 0
+}
+
+// publish sends data to all the clients that are subscribed to topic.
+// Clients that don't keep up with their notifications are disconnected.
+func (h *hub) publish(topic string, data interface{}) error {
+	if !h.hasSubscribers(topic) {
+		return nil
+	}
+
+	message, err := json.Marshal(&apimodels.WebSocketMessage{
+		Topic: topic,
+		Data:  data,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var slowClients []*client
+	h.RLock()
+	for c := range h.subscribers[topic] {
+		if !c.trySend(message) {
+			slowClients = append(slowClients, c)
+		}
+	}
+	h.RUnlock()
+
+	for _, c := range slowClients {
+		log.Warnf("Disconnecting WebSocket client %s since it doesn't keep up with its notifications", c.remoteAddr)
+		h.unregister(c)
+	}
+	return nil
+}
+
+// reply sends a message to a single client. Clients that don't
+// keep up with their messages are disconnected.
+func (h *hub) reply(c *client, message *apimodels.WebSocketMessage) error {
+	serializedMessage, err := json.Marshal(message)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	h.RLock()
+	_, isRegistered := h.clients[c]
+	sent := !isRegistered || c.trySend(serializedMessage)
+	h.RUnlock()
+
+	if !sent {
+		h.unregister(c)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package notifications
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kasparov/logger"
+)
+
+var (
+	log   = logger.Logger("NTFN")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
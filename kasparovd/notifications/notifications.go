@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/dagevents"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+// Start starts listening to the DAG events sent by kasparovsyncd and
+// publishing notifications about them to the subscribed WebSocket
+// clients. It returns a function that stops listening.
+func Start() (func(), error) {
+	events, stop, err := dagevents.Listen()
+	if err != nil {
+		return nil, err
+	}
+
+	spawn("notifications-Start", func() {
+		for event := range events {
+			err := handleEvent(event)
+			if err != nil {
+				log.Errorf("Error handling DAG event %s: %s", event.Kind, err)
+			}
+		}
+	})
+
+	return func() {
+		err := stop()
+		if err != nil {
+			log.Errorf("Error stopping to listen to DAG events: %s", err)
+		}
+	}, nil
+}
+
+func handleEvent(event *dagevents.Event) error {
+	if !defaultHub.hasClients() {
+		return nil
+	}
+
+	switch event.Kind {
+	case dagevents.KindBlocksAdded:
+		return publishBlockAddedNotifications(event.BlockHashes)
+	case dagevents.KindChainChanged:
+		return publishChainChangedNotifications(event.RemovedChainBlockHashes, event.AddedChainBlockHashes)
+	case dagevents.KindSelectedTipChanged:
+		return publishSelectedTipNotifications(event.BlockHashes)
+	case dagevents.KindTransactionsUnaccepted:
+		return publishUnacceptedTransactionsNotifications(event.TransactionIDs)
+	default:
+		return errors.Errorf("unknown DAG event kind %s", event.Kind)
+	}
+}
+
+func publishBlockAddedNotifications(blockHashes []string) error {
+	preloadedFields := dbmodels.PrefixFieldNames(dbmodels.BlockFieldNames.Transactions, dbmodels.TransactionRecommendedPreloadedFields)
+	preloadedFields = append(preloadedFields, dbmodels.BlockFieldNames.ParentBlocks)
+
+	dbBlocks, err := dbaccess.BlocksByHashes(database.NoTx(), blockHashes, preloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return err
+	}
+
+	for _, dbBlock := range dbBlocks {
+		err := defaultHub.publish(BlocksTopic, apimodels.ConvertBlockModelToBlockResponse(dbBlock, selectedTipBlueScore))
+		if err != nil {
+			return err
+		}
+
+		err = publishTransactionsNotifications(TransactionsTopic, dbBlock.Transactions, selectedTipBlueScore)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishChainChangedNotifications(removedChainBlockHashes []string, addedChainBlockHashes []string) error {
+	dbAddedChainBlocks, err := dbaccess.BlocksByHashes(database.NoTx(), addedChainBlockHashes,
+		dbmodels.BlockFieldNames.AcceptedBlocks)
+	if err != nil {
+		return err
+	}
+	dbAddedChainBlocksByHash := make(map[string]*dbmodels.Block, len(dbAddedChainBlocks))
+	for _, dbBlock := range dbAddedChainBlocks {
+		dbAddedChainBlocksByHash[dbBlock.BlockHash] = dbBlock
+	}
+
+	notificationData := &apimodels.SelectedParentChainNotification{
+		AddedChainBlocks:   make([]*apimodels.AddedChainBlock, 0, len(addedChainBlockHashes)),
+		RemovedBlockHashes: removedChainBlockHashes,
+	}
+	for _, hash := range addedChainBlockHashes {
+		dbBlock, ok := dbAddedChainBlocksByHash[hash]
+		if !ok {
+			return errors.Errorf("added chain block %s was not found", hash)
+		}
+		acceptedBlockHashes := make([]string, len(dbBlock.AcceptedBlocks))
+		for i, acceptedBlock := range dbBlock.AcceptedBlocks {
+			acceptedBlockHashes[i] = acceptedBlock.BlockHash
+		}
+		notificationData.AddedChainBlocks = append(notificationData.AddedChainBlocks, &apimodels.AddedChainBlock{
+			Hash:                hash,
+			AcceptedBlockHashes: acceptedBlockHashes,
+		})
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return err
+	}
+
+	acceptedTransactions, err := dbaccess.AcceptedTransactionsByBlockHashes(database.NoTx(), addedChainBlockHashes,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+	err = publishTransactionsNotifications(AcceptedTransactionsTopic, acceptedTransactions, selectedTipBlueScore)
+	if err != nil {
+		return err
+	}
+
+	return defaultHub.publish(SelectedParentChainTopic, notificationData)
+}
+
+func publishSelectedTipNotifications(blockHashes []string) error {
+	dbBlocks, err := dbaccess.BlocksByHashes(database.NoTx(), blockHashes, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+	for _, dbBlock := range dbBlocks {
+		block := apimodels.ConvertBlockModelToBlockResponse(dbBlock, dbBlock.BlueScore)
+		err := defaultHub.publish(SelectedTipTopic, block)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishUnacceptedTransactionsNotifications(transactionIDs []uint64) error {
+	dbTransactions, err := dbaccess.TransactionsByDBIDs(database.NoTx(), transactionIDs,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return err
+	}
+
+	return publishTransactionsNotifications(UnacceptedTransactionsTopic, dbTransactions, selectedTipBlueScore)
+}
+
+// publishTransactionsNotifications publishes a notification for each transaction
+// of the given transactions to each of the addresses it involves
+func publishTransactionsNotifications(topicPrefix string, dbTransactions []*dbmodels.Transaction,
+	selectedTipBlueScore uint64) error {
+
+	for _, dbTransaction := range dbTransactions {
+		transaction := apimodels.ConvertTxModelToTxResponse(dbTransaction, selectedTipBlueScore)
+		for _, address := range transaction.UniqueAddresses() {
+			err := defaultHub.publish(addressTopic(topicPrefix, address), transaction)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"strings"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+)
+
+// The topics below mirror the MQTT topics published by kasparovsyncd
+const (
+	// BlocksTopic is a topic for new blocks
+	BlocksTopic = "dag/blocks"
+
+	// SelectedTipTopic is a topic for DAG selected tips
+	SelectedTipTopic = "dag/selected-tip"
+
+	// SelectedParentChainTopic is a topic for changes in the selected parent chain
+	SelectedParentChainTopic = "dag/selected-parent-chain"
+
+	// TransactionsTopic is a topic prefix for transactions of an address
+	TransactionsTopic = "transactions"
+
+	// AcceptedTransactionsTopic is a topic prefix for accepted transactions of an address
+	AcceptedTransactionsTopic = "transactions/accepted"
+
+	// UnacceptedTransactionsTopic is a topic prefix for unaccepted transactions of an address
+	UnacceptedTransactionsTopic = "transactions/unaccepted"
+)
+
+var dagTopics = map[string]struct{}{
+	BlocksTopic:              {},
+	SelectedTipTopic:         {},
+	SelectedParentChainTopic: {},
+}
+
+// transactionsTopicPrefixes are ordered from the most specific prefix
+// to the least, so that the address is parsed correctly.
+var transactionsTopicPrefixes = []string{
+	AcceptedTransactionsTopic,
+	UnacceptedTransactionsTopic,
+	TransactionsTopic,
+}
+
+func addressTopic(topicPrefix string, address string) string {
+	return topicPrefix + "/" + address
+}
+
+// isValidTopic returns whether clients may subscribe to the given topic
+func isValidTopic(topic string) bool {
+	if _, ok := dagTopics[topic]; ok {
+		return true
+	}
+	for _, prefix := range transactionsTopicPrefixes {
+		if !strings.HasPrefix(topic, prefix+"/") {
+			continue
+		}
+		address := strings.TrimPrefix(topic, prefix+"/")
+		_, err := util.DecodeAddress(address, config.ActiveConfig().ActiveNetParams.Prefix)
+		return err == nil
+	}
+	return false
+}
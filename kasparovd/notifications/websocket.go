@@ -0,0 +1,182 @@
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kaspanet/kasparov/apimodels"
+)
+
+const (
+	// sendBufferSize is the number of messages that may wait to be
+	// written to a client before it's considered too slow.
+	sendBufferSize = 256
+
+	// writeWait is the time allowed to write a message to a client
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong from a client
+	pongWait = 60 * time.Second
+
+	// pingPeriod is the period in which pings are sent to clients. It
+	// must be shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+
+	// maxRequestSize is the maximum size of a request sent by a client
+	maxRequestSize = 1024
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// The REST API allows requests from all origins, and so does the WebSocket
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// client is a single WebSocket connection. Its topics
+// are guarded by the lock of the hub it's registered to.
+type client struct {
+	conn       *websocket.Conn
+	remoteAddr string
+	send       chan []byte
+	topics     map[string]struct{}
+}
+
+// trySend queues a message to be written to the client without blocking.
+// It returns false if the client's send buffer is full.
+func (c *client) trySend(message []byte) bool {
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// HandleWebSocket upgrades the request to a WebSocket connection, on which
+// the client can subscribe to the same topics that are published to MQTT.
+func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied to the client with an HTTP error
+		log.Debugf("Error upgrading a WebSocket connection from %s: %s", r.RemoteAddr, err)
+		return
+	}
+
+	c := &client{
+		conn:       conn,
+		remoteAddr: r.RemoteAddr,
+		send:       make(chan []byte, sendBufferSize),
+		topics:     make(map[string]struct{}),
+	}
+	defaultHub.register(c)
+	log.Debugf("WebSocket client %s connected", c.remoteAddr)
+
+	spawn("notifications-writeLoop", func() {
+		c.writeLoop()
+	})
+	c.readLoop()
+}
+
+// readLoop handles the requests of the client until it disconnects
+func (c *client) readLoop() {
+	defer func() {
+		defaultHub.unregister(c)
+		err := c.conn.Close()
+		if err != nil {
+			log.Debugf("Error closing the WebSocket connection of %s: %s", c.remoteAddr, err)
+		}
+		log.Debugf("WebSocket client %s disconnected", c.remoteAddr)
+	}()
+
+	c.conn.SetReadLimit(maxRequestSize)
+	err := c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	if err != nil {
+		return
+	}
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		err = defaultHub.reply(c, c.handleRequest(message))
+		if err != nil {
+			log.Errorf("Error replying to WebSocket client %s: %s", c.remoteAddr, err)
+			return
+		}
+	}
+}
+
+func (c *client) handleRequest(message []byte) *apimodels.WebSocketMessage {
+	request := &apimodels.WebSocketRequest{}
+	err := json.Unmarshal(message, request)
+	if err != nil {
+		return &apimodels.WebSocketMessage{Error: "the request is not json-formatted"}
+	}
+
+	reply := &apimodels.WebSocketMessage{
+		Action: request.Action,
+		Topic:  request.Topic,
+	}
+	switch request.Action {
+	case apimodels.WebSocketActionSubscribe:
+		if !isValidTopic(request.Topic) {
+			reply.Error = "the given topic is invalid"
+			return reply
+		}
+		err := defaultHub.subscribe(c, request.Topic)
+		if err != nil {
+			reply.Error = err.Error()
+		}
+	case apimodels.WebSocketActionUnsubscribe:
+		defaultHub.unsubscribe(c, request.Topic)
+	default:
+		reply.Error = "the given action is invalid"
+	}
+	return reply
+}
+
+// writeLoop writes the messages queued for the client, and
+// pings it periodically, until its send channel is closed.
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		// Closing the connection makes readLoop return as well
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			err := c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err != nil {
+				return
+			}
+			if !ok {
+				// The hub closed the channel
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			err = c.conn.WriteMessage(websocket.TextMessage, message)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			err := c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err != nil {
+				return
+			}
+			err = c.conn.WriteMessage(websocket.PingMessage, nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package rebroadcast
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kasparov/logger"
+)
+
+var (
+	log   = logger.Logger("RBCT")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,113 @@
+package rebroadcast
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/domainmessage"
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/jsonrpc"
+	"github.com/pkg/errors"
+)
+
+// maxTransactionsPerRound is the maximum number of
+// transactions that are rebroadcast every interval
+const maxTransactionsPerRound = 100
+
+// Start periodically rebroadcasts transactions that were submitted through
+// Kasparov during the last `maxAge`, were not rejected by the node, and were
+// not included in any block within `interval` since they were last broadcast.
+// Transactions that the node rejects when they're rebroadcast are recorded as
+// rejected, and are not rebroadcast anymore.
+// It returns a function to stop rebroadcasting.
+func Start(interval time.Duration, maxAge time.Duration) func() {
+	doneChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+
+	spawn("rebroadcast-Start", func() {
+		defer close(stoppedChan)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := rebroadcast(interval, maxAge)
+				if err != nil {
+					log.Errorf("Error rebroadcasting transactions: %s", err)
+				}
+			case <-doneChan:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(doneChan)
+		<-stoppedChan
+	}
+}
+
+func rebroadcast(interval time.Duration, maxAge time.Duration) error {
+	client, err := jsonrpc.GetClient()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	submittedTxs, err := dbaccess.SubmittedTransactionsToRebroadcast(database.NoTx(),
+		now.Add(-maxAge), now.Add(-interval), maxTransactionsPerRound)
+	if err != nil {
+		return err
+	}
+
+	for _, submittedTx := range submittedTxs {
+		rejectionError, err := rebroadcastTransaction(client, submittedTx)
+		if err != nil {
+			// The node might be unreachable. Either way, the rest should
+			// still be rebroadcast.
+			log.Debugf("Error rebroadcasting transaction %s: %s", submittedTx.TransactionID, err)
+		}
+		if rejectionError != nil {
+			log.Infof("Transaction %s was rejected when it was rebroadcast: %s", submittedTx.TransactionID, *rejectionError)
+		}
+		err = dbaccess.UpdateSubmittedTransactionBroadcast(database.NoTx(), submittedTx.ID, time.Now(), rejectionError)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(submittedTxs) > 0 {
+		log.Infof("Rebroadcast %d transactions", len(submittedTxs))
+	}
+	return nil
+}
+
+// rebroadcastTransaction sends the given submitted transaction to the node again.
+// If the node rejects it, the rejection error is returned, and the transaction is
+// not rebroadcast anymore once it's recorded.
+func rebroadcastTransaction(client *jsonrpc.Client, submittedTx *dbmodels.SubmittedTransaction) (rejectionError *string, err error) {
+	tx := &domainmessage.MsgTx{}
+	err = tx.KaspaDecode(bytes.NewReader(submittedTx.RawTransaction), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.SendRawTransaction(tx, true)
+	if err == nil {
+		return nil, nil
+	}
+	rpcErr := &(rpcmodel.RPCError{})
+	if !errors.As(err, &rpcErr) {
+		return nil, err
+	}
+	if strings.Contains(rpcErr.Message, jsonrpc.AlreadyHaveTransactionMessage) {
+		// The transaction is still in the node's mempool
+		return nil, nil
+	}
+	return &rpcErr.Message, nil
+}
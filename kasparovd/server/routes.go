@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/kaspanet/kasparov/dbaccess"
 	"github.com/kaspanet/kasparov/httpserverutils"
 	"github.com/kaspanet/kasparov/kasparovd/controllers"
+	"github.com/kaspanet/kasparov/kasparovd/graphql"
+	"github.com/kaspanet/kasparov/kasparovd/notifications"
 	"github.com/pkg/errors"
 
 	"github.com/gorilla/mux"
@@ -18,18 +21,50 @@ const (
 	routeParamTxHash    = "txHash"
 	routeParamAddress   = "address"
 	routeParamBlockHash = "blockHash"
+	routeParamIndex     = "index"
+	routeParamBlueScore = "blueScore"
+
+	routeParamFromBlockHash = "fromBlockHash"
+	routeParamToBlockHash   = "toBlockHash"
+
+	routeParamWebhookSubscriptionID = "subscriptionID"
 )
 
 const (
-	queryParamSkip  = "skip"
-	queryParamLimit = "limit"
-	queryParamOrder = "order"
+	queryParamSkip     = "skip"
+	queryParamLimit    = "limit"
+	queryParamOrder    = "order"
+	queryParamCursor   = "cursor"
+	queryParamDepth    = "depth"
+	queryParamMinDepth = "minDepth"
+
+	queryParamFromBlueScore = "fromBlueScore"
+	queryParamToBlueScore   = "toBlueScore"
+	queryParamFromTime      = "fromTime"
+	queryParamToTime        = "toTime"
+	queryParamDirection     = "direction"
+	queryParamAcceptedOnly  = "acceptedOnly"
+
+	queryParamMetric   = "metric"
+	queryParamInterval = "interval"
+	queryParamBy       = "by"
+	queryParamQuery    = "q"
 )
 
 const (
 	defaultGetTransactionsLimit = 100
+	defaultGetTransactionsOrder = string(dbaccess.OrderAscending)
 	defaultGetBlocksLimit       = 25
 	defaultGetBlocksOrder       = string(dbaccess.OrderDescending)
+	defaultDAGTraversalDepth    = 5
+	defaultGetChainBlocksOrder  = string(dbaccess.OrderAscending)
+	defaultGetReorgsLimit       = 25
+	defaultGetReorgsMinDepth    = 1
+	defaultStatsInterval        = string(dbaccess.StatsIntervalHour)
+	defaultGetTopAddressesLimit = 25
+	defaultGetTopAddressesBy    = string(dbaccess.AddressRankingBalance)
+
+	defaultGetWebhookDeliveriesLimit = 25
 )
 
 func mainHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string, _ []byte) (interface{}, error) {
@@ -40,19 +75,53 @@ func mainHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string
 	}, nil
 }
 
+// addUnlimitedRoutes adds the routes that are not rate limited: health
+// probes, which are usually sent from a single host
+func addUnlimitedRoutes(router *mux.Router) {
+	router.HandleFunc(
+		"/health/live",
+		httpserverutils.MakeHandler(getLivenessHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/health/ready",
+		httpserverutils.MakeHandler(getReadinessHandler)).
+		Methods("GET")
+}
+
 func addRoutes(router *mux.Router) {
 	router.HandleFunc("/", httpserverutils.MakeHandler(mainHandler))
 
+	// Only the upgrade request of a WebSocket connection is rate limited,
+	// which bounds the rate at which a client can open connections
+	router.HandleFunc("/ws", notifications.HandleWebSocket).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/transaction/id/{%s}", routeParamTxID),
 		httpserverutils.MakeHandler(getTransactionByIDHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/transaction/id/{%s}/output/{%s}/spender", routeParamTxID, routeParamIndex),
+		httpserverutils.MakeHandler(getTransactionOutputSpenderHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/transaction/id/{%s}/history", routeParamTxID),
+		httpserverutils.MakeHandler(getTransactionHistoryHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/transaction/hash/{%s}", routeParamTxHash),
 		httpserverutils.MakeHandler(getTransactionByHashHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/transaction/submitted/{%s}", routeParamTxID),
+		httpserverutils.MakeHandler(getSubmittedTransactionHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/transactions/address/{%s}", routeParamAddress),
 		httpserverutils.MakeHandler(getTransactionsByAddressHandler)).
@@ -73,11 +142,51 @@ func addRoutes(router *mux.Router) {
 		httpserverutils.MakeHandler(getUTXOsByAddressHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/balance/address/{%s}", routeParamAddress),
+		httpserverutils.MakeHandler(getAddressBalanceHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/addresses/top",
+		httpserverutils.MakeHandler(getTopAddressesHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/block/{%s}", routeParamBlockHash),
 		httpserverutils.MakeHandler(getBlockByHashHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/children", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockChildrenHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/selected-parent", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockSelectedParentHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/accepted-blocks", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockAcceptedBlocksHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/accepting-block", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockAcceptingBlockHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/ancestors", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockAncestorsHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/descendants", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockDescendantsHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		"/blocks",
 		httpserverutils.MakeHandler(getBlocksHandler)).
@@ -88,15 +197,115 @@ func addRoutes(router *mux.Router) {
 		httpserverutils.MakeHandler(getBlockCountHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		"/chain",
+		httpserverutils.MakeHandler(getChainBlocksHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/chain/blue-score/{%s}", routeParamBlueScore),
+		httpserverutils.MakeHandler(getChainBlockAtBlueScoreHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/chain/between/{%s}/{%s}", routeParamFromBlockHash, routeParamToBlockHash),
+		httpserverutils.MakeHandler(getChainBetweenHashesHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/chain/changes/{%s}", routeParamBlockHash),
+		httpserverutils.MakeHandler(getChainChangesHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/reorgs",
+		httpserverutils.MakeHandler(getReorgsHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/stats",
+		httpserverutils.MakeHandler(getNetworkStatsHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/stats/timeseries",
+		httpserverutils.MakeHandler(getStatsTimeseriesHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/search",
+		httpserverutils.MakeHandler(getSearchHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		"/fee-estimates",
 		httpserverutils.MakeHandler(getFeeEstimatesHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		"/sync-status",
+		httpserverutils.MakeHandler(getSyncStatusHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/mempool",
+		httpserverutils.MakeHandler(getMempoolHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/mempool/address/{%s}", routeParamAddress),
+		httpserverutils.MakeHandler(getMempoolByAddressHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		"/transaction",
 		httpserverutils.MakeHandler(postTransactionHandler)).
 		Methods("POST")
+
+	router.HandleFunc(
+		"/transactions/ids",
+		httpserverutils.MakeHandler(postTransactionsByIDsHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		"/blocks/hashes",
+		httpserverutils.MakeHandler(postBlocksByHashesHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		"/utxos/addresses",
+		httpserverutils.MakeHandler(postUTXOsByAddressesHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		"/transaction-outputs/outpoints",
+		httpserverutils.MakeHandler(postTransactionOutputsByOutpointsHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		"/webhooks",
+		httpserverutils.MakeHandler(postWebhookSubscriptionHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		fmt.Sprintf("/webhooks/{%s}", routeParamWebhookSubscriptionID),
+		httpserverutils.MakeHandler(getWebhookSubscriptionHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/webhooks/{%s}", routeParamWebhookSubscriptionID),
+		httpserverutils.MakeHandler(deleteWebhookSubscriptionHandler)).
+		Methods("DELETE")
+
+	router.HandleFunc(
+		fmt.Sprintf("/webhooks/{%s}/deliveries", routeParamWebhookSubscriptionID),
+		httpserverutils.MakeHandler(getWebhookDeliveriesHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/graphql",
+		httpserverutils.MakeHandler(postGraphQLHandler)).
+		Methods("POST")
 }
 
 func convertQueryParamToInt64(queryParams map[string]string, param string, defaultValue int64) (int64, error) {
@@ -114,6 +323,101 @@ func convertQueryParamToInt64(queryParams map[string]string, param string, defau
 	return defaultValue, nil
 }
 
+func convertQueryParamToUint64Pointer(queryParams map[string]string, param string) (*uint64, error) {
+	if _, ok := queryParams[param]; !ok {
+		return nil, nil
+	}
+	uint64Value, err := strconv.ParseUint(queryParams[param], 10, 64)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Couldn't parse the '%s' query parameter", param)
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(
+			http.StatusUnprocessableEntity,
+			errors.Wrap(err, errorMessage),
+			errorMessage)
+	}
+	return &uint64Value, nil
+}
+
+func convertQueryParamToTimePointer(queryParams map[string]string, param string) (*time.Time, error) {
+	unixTime, err := convertQueryParamToUint64Pointer(queryParams, param)
+	if err != nil || unixTime == nil {
+		return nil, err
+	}
+	timeValue := time.Unix(int64(*unixTime), 0)
+	return &timeValue, nil
+}
+
+func convertQueryParamToBool(queryParams map[string]string, param string, defaultValue bool) (bool, error) {
+	if _, ok := queryParams[param]; !ok {
+		return defaultValue, nil
+	}
+	boolValue, err := strconv.ParseBool(queryParams[param])
+	if err != nil {
+		errorMessage := fmt.Sprintf("Couldn't parse the '%s' query parameter", param)
+		return false, httpserverutils.NewHandlerErrorWithCustomClientMessage(
+			http.StatusUnprocessableEntity,
+			errors.Wrap(err, errorMessage),
+			errorMessage)
+	}
+	return boolValue, nil
+}
+
+// convertQueryParamsToAddressTransactionsFilter builds a filter for the transactions
+// of an address out of the query parameters. Times are given in unix seconds.
+func convertQueryParamsToAddressTransactionsFilter(queryParams map[string]string) (*dbaccess.AddressTransactionsFilter, error) {
+	filter := &dbaccess.AddressTransactionsFilter{}
+	var err error
+	filter.FromBlueScore, err = convertQueryParamToUint64Pointer(queryParams, queryParamFromBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	filter.ToBlueScore, err = convertQueryParamToUint64Pointer(queryParams, queryParamToBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	if filter.FromBlueScore != nil && filter.ToBlueScore != nil && *filter.FromBlueScore > *filter.ToBlueScore {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("'%s' cannot be greater than '%s'", queryParamFromBlueScore, queryParamToBlueScore))
+	}
+	filter.FromTime, err = convertQueryParamToTimePointer(queryParams, queryParamFromTime)
+	if err != nil {
+		return nil, err
+	}
+	filter.ToTime, err = convertQueryParamToTimePointer(queryParams, queryParamToTime)
+	if err != nil {
+		return nil, err
+	}
+	if filter.FromTime != nil && filter.ToTime != nil && filter.FromTime.After(*filter.ToTime) {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("'%s' cannot be later than '%s'", queryParamFromTime, queryParamToTime))
+	}
+	if directionString, ok := queryParams[queryParamDirection]; ok {
+		filter.Direction, err = dbaccess.StringToTransactionDirection(directionString)
+		if err != nil {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+		}
+	}
+	filter.AcceptedOnly, err = convertQueryParamToBool(queryParams, queryParamAcceptedOnly, false)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// cursorQueryParam returns the cursor query parameter, or an empty
+// string if it's missing. It can't be used together with skip.
+func cursorQueryParam(queryParams map[string]string) (string, error) {
+	cursor, ok := queryParams[queryParamCursor]
+	if !ok {
+		return "", nil
+	}
+	if _, ok := queryParams[queryParamSkip]; ok {
+		return "", httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("the '%s' and '%s' query parameters cannot be used together", queryParamSkip, queryParamCursor))
+	}
+	return cursor, nil
+}
+
 func getTransactionByIDHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
@@ -126,9 +430,40 @@ func getTransactionByHashHandler(_ *httpserverutils.ServerContext, _ *http.Reque
 	return controllers.GetTransactionByHashHandler(routeParams[routeParamTxHash])
 }
 
+func getTransactionOutputSpenderHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetTransactionOutputSpenderHandler(routeParams[routeParamTxID], routeParams[routeParamIndex])
+}
+
+func getTransactionHistoryHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetTransactionHistoryHandler(routeParams[routeParamTxID])
+}
+
+func getSubmittedTransactionHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetSubmittedTransactionHandler(routeParams[routeParamTxID])
+}
+
 func getTransactionsByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
 	_ []byte) (interface{}, error) {
 
+	filter, err := convertQueryParamsToAddressTransactionsFilter(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	order := defaultGetTransactionsOrder
+	if orderParamValue, ok := queryParams[queryParamOrder]; ok {
+		order = orderParamValue
+	}
+
+	cursor, err := cursorQueryParam(queryParams)
+	if err != nil {
+		return nil, err
+	}
 	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
 	if err != nil {
 		return nil, err
@@ -137,12 +472,17 @@ func getTransactionsByAddressHandler(_ *httpserverutils.ServerContext, _ *http.R
 	if err != nil {
 		return nil, err
 	}
-	return controllers.GetTransactionsByAddressHandler(routeParams[routeParamAddress], skip, limit)
+	return controllers.GetTransactionsByAddressHandler(routeParams[routeParamAddress], filter, order, skip, cursor, limit)
 }
 
-func getTransactionCountByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getTransactionCountByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
 	_ []byte) (interface{}, error) {
-	return controllers.GetTransactionCountByAddressHandler(routeParams[routeParamAddress])
+
+	filter, err := convertQueryParamsToAddressTransactionsFilter(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetTransactionCountByAddressHandler(routeParams[routeParamAddress], filter)
 }
 
 func getTransactionsByBlockHashHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
@@ -157,25 +497,194 @@ func getUTXOsByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request,
 	return controllers.GetUTXOsByAddressHandler(routeParams[routeParamAddress])
 }
 
+func getAddressBalanceHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetAddressBalanceHandler(routeParams[routeParamAddress])
+}
+
 func getBlockByHashHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
 	return controllers.GetBlockByHashHandler(routeParams[routeParamBlockHash])
 }
 
-func getFeeEstimatesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+func getBlockChildrenHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetFeeEstimatesHandler()
+	return controllers.GetBlockChildrenHandler(routeParams[routeParamBlockHash])
 }
 
-func getBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+func getBlockSelectedParentHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
+	return controllers.GetBlockSelectedParentHandler(routeParams[routeParamBlockHash])
+}
+
+func getBlockAcceptedBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetBlockAcceptedBlocksHandler(routeParams[routeParamBlockHash])
+}
+
+func getBlockAcceptingBlockHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetBlockAcceptingBlockHandler(routeParams[routeParamBlockHash])
+}
+
+func getBlockAncestorsHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	depth, err := convertQueryParamToInt64(queryParams, queryParamDepth, defaultDAGTraversalDepth)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetBlockAncestorsHandler(routeParams[routeParamBlockHash], depth)
+}
+
+func getBlockDescendantsHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	depth, err := convertQueryParamToInt64(queryParams, queryParamDepth, defaultDAGTraversalDepth)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetBlockDescendantsHandler(routeParams[routeParamBlockHash], depth)
+}
+
+func getChainBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetBlocksLimit)
+	if err != nil {
+		return nil, err
+	}
+	order := defaultGetChainBlocksOrder
+	if orderParamValue, ok := queryParams[queryParamOrder]; ok {
+		order = orderParamValue
+	}
+	fromBlueScore, err := convertQueryParamToUint64Pointer(queryParams, queryParamFromBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	toBlueScore, err := convertQueryParamToUint64Pointer(queryParams, queryParamToBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetChainBlocksHandler(order, fromBlueScore, toBlueScore, limit)
+}
+
+func getChainBlockAtBlueScoreHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetChainBlockAtBlueScoreHandler(routeParams[routeParamBlueScore])
+}
+
+func getChainBetweenHashesHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetChainBetweenHashesHandler(routeParams[routeParamFromBlockHash], routeParams[routeParamToBlockHash])
+}
+
+func getChainChangesHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetBlocksLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetChainChangesHandler(routeParams[routeParamBlockHash], limit)
+}
+
+func getTopAddressesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	by := defaultGetTopAddressesBy
+	if byParamValue, ok := queryParams[queryParamBy]; ok {
+		by = byParamValue
+	}
 	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
 	if err != nil {
 		return nil, err
 	}
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetTopAddressesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetTopAddressesHandler(by, skip, limit)
+}
+
+func getReorgsHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	minDepth := uint64(defaultGetReorgsMinDepth)
+	minDepthParamValue, err := convertQueryParamToUint64Pointer(queryParams, queryParamMinDepth)
+	if err != nil {
+		return nil, err
+	}
+	if minDepthParamValue != nil {
+		minDepth = *minDepthParamValue
+	}
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetReorgsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetReorgsHandler(minDepth, skip, limit)
+}
+
+func getNetworkStatsHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetNetworkStatsHandler()
+}
+
+func getStatsTimeseriesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	metric, ok := queryParams[queryParamMetric]
+	if !ok {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("the '%s' query parameter is required", queryParamMetric))
+	}
+	interval := defaultStatsInterval
+	if intervalParamValue, ok := queryParams[queryParamInterval]; ok {
+		interval = intervalParamValue
+	}
+	from, err := convertQueryParamToTimePointer(queryParams, queryParamFromTime)
+	if err != nil {
+		return nil, err
+	}
+	to, err := convertQueryParamToTimePointer(queryParams, queryParamToTime)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetStatsTimeseriesHandler(metric, interval, from, to)
+}
+
+func getSearchHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	query, ok := queryParams[queryParamQuery]
+	if !ok {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("the '%s' query parameter is required", queryParamQuery))
+	}
+	return controllers.GetSearchHandler(query)
+}
+
+func getFeeEstimatesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetFeeEstimatesHandler()
+}
+
+func getBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
 	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetBlocksLimit)
 	if err != nil {
 		return nil, err
@@ -184,7 +693,16 @@ func getBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[s
 	if orderParamValue, ok := queryParams[queryParamOrder]; ok {
 		order = orderParamValue
 	}
-	return controllers.GetBlocksHandler(order, skip, limit)
+
+	cursor, err := cursorQueryParam(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetBlocksHandler(order, skip, cursor, limit)
 }
 
 func getBlockCountHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
@@ -192,7 +710,104 @@ func getBlockCountHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ m
 	return controllers.GetBlockCountHandler()
 }
 
+func getSyncStatusHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetSyncStatusHandler()
+}
+
+func getMempoolHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetTransactionsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetMempoolHandler(skip, limit)
+}
+
+func getMempoolByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetTransactionsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetMempoolByAddressHandler(routeParams[routeParamAddress], skip, limit)
+}
+
+func getLivenessHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetLivenessHandler()
+}
+
+func getReadinessHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetReadinessHandler()
+}
+
 func postTransactionHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
 	requestBody []byte) (interface{}, error) {
-	return nil, controllers.PostTransaction(requestBody)
+	return controllers.PostTransaction(requestBody)
+}
+
+func postTransactionsByIDsHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return controllers.PostTransactionsByIDsHandler(requestBody)
+}
+
+func postBlocksByHashesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return controllers.PostBlocksByHashesHandler(requestBody)
+}
+
+func postUTXOsByAddressesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return controllers.PostUTXOsByAddressesHandler(requestBody)
+}
+
+func postTransactionOutputsByOutpointsHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return controllers.PostTransactionOutputsByOutpointsHandler(requestBody)
+}
+
+func postWebhookSubscriptionHandler(_ *httpserverutils.ServerContext, r *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return controllers.PostWebhookSubscriptionHandler(r.Header.Get(httpserverutils.APIKeyHeader), requestBody)
+}
+
+func getWebhookSubscriptionHandler(_ *httpserverutils.ServerContext, r *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetWebhookSubscriptionHandler(r.Header.Get(httpserverutils.APIKeyHeader), routeParams[routeParamWebhookSubscriptionID])
+}
+
+func deleteWebhookSubscriptionHandler(_ *httpserverutils.ServerContext, r *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.DeleteWebhookSubscriptionHandler(r.Header.Get(httpserverutils.APIKeyHeader), routeParams[routeParamWebhookSubscriptionID])
+}
+
+func getWebhookDeliveriesHandler(_ *httpserverutils.ServerContext, r *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetWebhookDeliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetWebhookDeliveriesHandler(r.Header.Get(httpserverutils.APIKeyHeader), routeParams[routeParamWebhookSubscriptionID], skip, limit)
+}
+
+func postGraphQLHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+	return graphql.PostGraphQLHandler(ctx, requestBody)
 }
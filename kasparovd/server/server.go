@@ -8,6 +8,8 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/kaspanet/kasparov/httpserverutils"
+	"github.com/kaspanet/kasparov/kasparovd/config"
+	"github.com/kaspanet/kasparov/metrics"
 )
 
 const gracefulShutdownTimeout = 30 * time.Second
@@ -19,8 +21,15 @@ func Start(listenAddr string) func() {
 	router.Use(httpserverutils.AddRequestMetadataMiddleware)
 	router.Use(httpserverutils.RecoveryMiddleware)
 	router.Use(httpserverutils.LoggingMiddleware)
+	router.Use(metrics.HTTPMiddleware)
 	router.Use(httpserverutils.SetJSONMiddleware)
-	addRoutes(router)
+	addUnlimitedRoutes(router)
+
+	// Routes that are added to the main router before this
+	// subrouter are matched first, and are not rate limited
+	rateLimitedRouter := router.NewRoute().Subrouter()
+	rateLimitedRouter.Use(newRateLimiter().Middleware)
+	addRoutes(rateLimitedRouter)
 	httpServer := &http.Server{
 		Addr:    listenAddr,
 		Handler: handlers.CORS()(router),
@@ -39,3 +48,12 @@ func Start(listenAddr string) func() {
 		}
 	}
 }
+
+func newRateLimiter() *httpserverutils.RateLimiter {
+	cfg := config.ActiveConfig()
+	ipTier := httpserverutils.RateLimitTier{
+		RequestsPerSecond: cfg.RateLimit,
+		Burst:             cfg.RateLimitBurst,
+	}
+	return httpserverutils.NewRateLimiter(ipTier, cfg.APIKeyTiers(), cfg.RateLimitTrustProxy)
+}
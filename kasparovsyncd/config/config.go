@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/kaspanet/kaspad/util"
@@ -20,8 +21,13 @@ const (
 
 var (
 	// Default configuration options
-	defaultLogDir = util.AppDataDir("kasparov_syncd", false)
-	activeConfig  *Config
+	defaultLogDir                 = util.AppDataDir("kasparov_syncd", false)
+	defaultFetchWorkers           = 8
+	defaultPrefetchBatches        = 2
+	defaultMempoolPollInterval    = 5 * time.Second
+	defaultKafkaTopic             = "kasparov-notifications"
+	defaultConfirmationThresholds = []uint64{1, 10, 100}
+	activeConfig                  *Config
 )
 
 // ActiveConfig returns the active configuration struct
@@ -31,16 +37,31 @@ func ActiveConfig() *Config {
 
 // Config defines the configuration options for the sync daemon.
 type Config struct {
-	Migrate           bool   `long:"migrate" description:"Migrate the database to the latest version. The daemon will not start when using this flag."`
-	MQTTBrokerAddress string `long:"mqttaddress" description:"MQTT broker address" required:"false"`
-	MQTTUser          string `long:"mqttuser" description:"MQTT server user" required:"false"`
-	MQTTPassword      string `long:"mqttpass" description:"MQTT server password" required:"false"`
+	Migrate                bool          `long:"migrate" description:"Migrate the database to the latest version. The daemon will not start when using this flag."`
+	MQTTBrokerAddress      string        `long:"mqttaddress" description:"MQTT broker address" required:"false"`
+	MQTTUser               string        `long:"mqttuser" description:"MQTT server user" required:"false"`
+	MQTTPassword           string        `long:"mqttpass" description:"MQTT server password" required:"false"`
+	NATSURL                string        `long:"natsurl" description:"NATS server URL" required:"false"`
+	NATSUser               string        `long:"natsuser" description:"NATS server user" required:"false"`
+	NATSPassword           string        `long:"natspass" description:"NATS server password" required:"false"`
+	KafkaBrokers           []string      `long:"kafkabroker" description:"Kafka broker address. May be passed multiple times" required:"false"`
+	KafkaTopic             string        `long:"kafkatopic" description:"Kafka topic that all notifications are published to (default: kasparov-notifications)"`
+	ConfirmationThresholds []uint64      `long:"confirmationthreshold" description:"Number of confirmations at which transactions are published to transactions/confirmed/{n}/{address}. May be passed multiple times (default: 1, 10 and 100)"`
+	FetchWorkers           int           `long:"fetchworkers" description:"Maximum number of concurrent block requests to the node (default: 8)"`
+	PrefetchBatches        int           `long:"prefetchbatches" description:"Maximum number of block batches to prefetch from the node while previous batches are being inserted into the database (default: 2)"`
+	MetricsListen          string        `long:"metricslisten" description:"HTTP address to serve Prometheus metrics on. Metrics are not served if not set" required:"false"`
+	MempoolPollInterval    time.Duration `long:"mempoolpollinterval" description:"Interval in which the mempool of the node is polled for pending transactions. 0 disables mempool indexing (default: 5s)"`
 	config.KasparovFlags
 }
 
 // Parse parses the CLI arguments and returns a config struct.
 func Parse() error {
-	activeConfig = &Config{}
+	activeConfig = &Config{
+		FetchWorkers:        defaultFetchWorkers,
+		PrefetchBatches:     defaultPrefetchBatches,
+		MempoolPollInterval: defaultMempoolPollInterval,
+		KafkaTopic:          defaultKafkaTopic,
+	}
 	parser := flags.NewParser(activeConfig, flags.HelpFlag)
 	_, err := parser.Parse()
 	// Show the version and exit if the version flag was specified.
@@ -66,5 +87,34 @@ func Parse() error {
 		return errors.New("--mqttaddress, --mqttuser, and --mqttpass must be passed all together")
 	}
 
+	if activeConfig.NATSURL == "" && (activeConfig.NATSUser != "" || activeConfig.NATSPassword != "") {
+		return errors.New("--natsuser and --natspass require --natsurl")
+	}
+
+	if activeConfig.KafkaTopic == "" {
+		return errors.New("--kafkatopic must not be empty")
+	}
+
+	if len(activeConfig.ConfirmationThresholds) == 0 {
+		activeConfig.ConfirmationThresholds = defaultConfirmationThresholds
+	}
+	for _, threshold := range activeConfig.ConfirmationThresholds {
+		if threshold < 1 {
+			return errors.New("--confirmationthreshold must be at least 1")
+		}
+	}
+
+	if activeConfig.FetchWorkers < 1 {
+		return errors.New("--fetchworkers must be at least 1")
+	}
+
+	if activeConfig.PrefetchBatches < 0 {
+		return errors.New("--prefetchbatches must not be negative")
+	}
+
+	if activeConfig.MempoolPollInterval < 0 {
+		return errors.New("--mempoolpollinterval must not be negative")
+	}
+
 	return nil
 }
@@ -14,7 +14,9 @@ import (
 	"github.com/kaspanet/kasparov/database"
 	"github.com/kaspanet/kasparov/jsonrpc"
 	"github.com/kaspanet/kasparov/kasparovsyncd/config"
-	"github.com/kaspanet/kasparov/kasparovsyncd/mqtt"
+	"github.com/kaspanet/kasparov/kasparovsyncd/notifications"
+	"github.com/kaspanet/kasparov/kasparovsyncd/webhooks"
+	"github.com/kaspanet/kasparov/metrics"
 	"github.com/kaspanet/kasparov/version"
 	"github.com/pkg/errors"
 )
@@ -60,11 +62,22 @@ func main() {
 		}
 	}()
 
-	err = mqtt.Connect()
+	err = notifications.Connect()
 	if err != nil {
-		panic(errors.Errorf("Error connecting to MQTT: %s", err))
+		panic(errors.Errorf("Error connecting to notification publishers: %s", err))
+	}
+	defer notifications.Close()
+
+	stopRelay := notifications.StartRelay()
+	defer stopRelay()
+
+	stopWebhookDispatcher := webhooks.StartDispatcher()
+	defer stopWebhookDispatcher()
+
+	if config.ActiveConfig().MetricsListen != "" {
+		shutdownMetricsServer := metrics.StartServer(config.ActiveConfig().MetricsListen)
+		defer shutdownMetricsServer()
 	}
-	defer mqtt.Close()
 
 	err = jsonrpc.Connect(&config.ActiveConfig().KasparovFlags, true)
 	if err != nil {
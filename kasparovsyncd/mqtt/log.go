@@ -1,5 +0,0 @@
-package mqtt
-
-import "github.com/kaspanet/kasparov/logger"
-
-var log = logger.Logger("MQTT")
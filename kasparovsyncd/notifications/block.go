@@ -1,4 +1,4 @@
-package mqtt
+package notifications
 
 import (
 	"github.com/kaspanet/kasparov/apimodels"
@@ -7,12 +7,12 @@ import (
 	"github.com/kaspanet/kasparov/dbmodels"
 )
 
-// BlocksTopic is an MQTT topic for new blocks
+// BlocksTopic is a topic for new blocks
 const BlocksTopic = "dag/blocks"
 
 // PublishBlockAddedNotifications publishes notifications for the block
 // that was added, and notifications for its transactions.
-func PublishBlockAddedNotifications(hash string) error {
+func PublishBlockAddedNotifications(ctx database.Context, hash string) error {
 	if !isConnected() {
 		return nil
 	}
@@ -20,20 +20,20 @@ func PublishBlockAddedNotifications(hash string) error {
 	preloadedFields := dbmodels.PrefixFieldNames(dbmodels.BlockFieldNames.Transactions, dbmodels.TransactionRecommendedPreloadedFields)
 	preloadedFields = append(preloadedFields, dbmodels.BlockFieldNames.ParentBlocks)
 
-	dbBlock, err := dbaccess.BlockByHash(database.NoTx(), hash, preloadedFields...)
+	dbBlock, err := dbaccess.BlockByHash(ctx, hash, preloadedFields...)
 	if err != nil {
 		return err
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = publish(BlocksTopic, apimodels.ConvertBlockModelToBlockResponse(dbBlock, selectedTipBlueScore))
+	err = enqueue(ctx, BlocksTopic, apimodels.ConvertBlockModelToBlockResponse(dbBlock, selectedTipBlueScore))
 	if err != nil {
 		return err
 	}
 
-	return publishTransactionsNotifications(TransactionsTopic, dbBlock.Transactions, selectedTipBlueScore)
+	return publishTransactionsNotifications(ctx, TransactionsTopic, dbBlock.Transactions, selectedTipBlueScore)
 }
@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Message is a notification published by a ChannelPublisher
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// ChannelPublisher publishes notifications to an in-process channel.
+// It's meant for tests and for embedding kasparovsyncd in another
+// process. Publishing blocks while the channel is full.
+type ChannelPublisher struct {
+	messages chan *Message
+	closed   bool
+	lock     sync.RWMutex
+}
+
+// NewChannelPublisher returns a new ChannelPublisher whose
+// channel can hold up to `capacity` unread messages
+func NewChannelPublisher(capacity int) *ChannelPublisher {
+	return &ChannelPublisher{
+		messages: make(chan *Message, capacity),
+	}
+}
+
+// Messages returns the channel that notifications are published to.
+// The channel is closed when the publisher is closed.
+func (p *ChannelPublisher) Messages() <-chan *Message {
+	return p.messages
+}
+
+// Name returns the name of the publisher
+func (p *ChannelPublisher) Name() string {
+	return "channel"
+}
+
+// Publish sends the given payload with its topic to the channel
+func (p *ChannelPublisher) Publish(topic string, payload []byte) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return errors.New("the channel publisher is closed")
+	}
+	p.messages <- &Message{Topic: topic, Payload: payload}
+	return nil
+}
+
+// Close closes the channel
+func (p *ChannelPublisher) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.messages)
+}
@@ -0,0 +1,148 @@
+package notifications
+
+import (
+	"path"
+	"strconv"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/kasparovsyncd/config"
+)
+
+const (
+	// ConfirmedTransactionsTopic is a topic for transactions that reached
+	// a confirmation threshold. The full topic of a transaction that reached
+	// n confirmations is ConfirmedTransactionsTopic/n/address.
+	ConfirmedTransactionsTopic = "transactions/confirmed"
+)
+
+// PublishConfirmedTransactionsNotifications publishes a notification for each accepted
+// transaction that reached one of the configured confirmation thresholds since the
+// previous call. It must be called within the database transaction that updates the
+// selected parent chain, after the update, with the hashes of the chain blocks that
+// were removed from it. Transactions whose confirmations were lowered below a threshold
+// by the update, whether they were re-accepted or the selected tip blue score decreased,
+// are published again once they reach it.
+func PublishConfirmedTransactionsNotifications(ctx database.Context, removedChainHashes []string) error {
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	lowestRemovedChainBlueScore, err := lowestBlueScore(ctx, removedChainHashes)
+	if err != nil {
+		return err
+	}
+
+	dbThresholds, err := dbaccess.ConfirmationNotificationThresholds(ctx)
+	if err != nil {
+		return err
+	}
+	notifiedBlueScores := make(map[uint64]uint64, len(dbThresholds))
+	for _, dbThreshold := range dbThresholds {
+		notifiedBlueScores[dbThreshold.Threshold] = dbThreshold.NotifiedBlueScore
+	}
+
+	for _, threshold := range config.ActiveConfig().ConfirmationThresholds {
+		if selectedTipBlueScore+1 < threshold {
+			// No transaction has reached this threshold yet
+			continue
+		}
+
+		toBlueScore := selectedTipBlueScore + 1 - threshold
+		notifiedBlueScore, ok := notifiedBlueScores[threshold]
+		if !ok {
+			// This threshold was just configured. Transactions that had
+			// already reached it are not published.
+			notifiedBlueScore = toBlueScore
+		}
+		fromBlueScore := confirmationNotificationsStartBlueScore(notifiedBlueScore, toBlueScore,
+			lowestRemovedChainBlueScore)
+
+		if fromBlueScore < toBlueScore && isConnected() {
+			err := publishConfirmedTransactionsNotifications(ctx, threshold, fromBlueScore, toBlueScore,
+				selectedTipBlueScore)
+			if err != nil {
+				return err
+			}
+		}
+
+		if ok && notifiedBlueScore == toBlueScore {
+			continue
+		}
+		err := dbaccess.UpdateConfirmationNotificationThreshold(ctx, &dbmodels.ConfirmationNotificationThreshold{
+			Threshold:         threshold,
+			NotifiedBlueScore: toBlueScore,
+		})
+		if err != nil {
+			return err
+		}
+		notifiedBlueScores[threshold] = toBlueScore
+	}
+
+	return nil
+}
+
+// confirmationNotificationsStartBlueScore returns the blue score above which the
+// transactions accepted by chain blocks reached a confirmation threshold since it
+// was last notified, given the highest blue score of a chain block whose accepted
+// transactions were notified, the highest blue score of a chain block whose accepted
+// transactions now reach the threshold, and the lowest blue score of the chain blocks
+// that were removed from the selected parent chain since, if any.
+func confirmationNotificationsStartBlueScore(notifiedBlueScore uint64, toBlueScore uint64,
+	lowestRemovedChainBlueScore *uint64) uint64 {
+
+	fromBlueScore := notifiedBlueScore
+
+	// The transactions accepted by the removed chain blocks may be re-accepted
+	// by the chain blocks that replaced them, in which case they need to be
+	// notified again.
+	if lowestRemovedChainBlueScore != nil && *lowestRemovedChainBlueScore <= fromBlueScore {
+		fromBlueScore = 0
+		if *lowestRemovedChainBlueScore > 0 {
+			fromBlueScore = *lowestRemovedChainBlueScore - 1
+		}
+	}
+
+	// The selected tip blue score may have decreased, in which case
+	// some notified transactions no longer reach the threshold.
+	if fromBlueScore > toBlueScore {
+		fromBlueScore = toBlueScore
+	}
+
+	return fromBlueScore
+}
+
+func lowestBlueScore(ctx database.Context, blockHashes []string) (*uint64, error) {
+	if len(blockHashes) == 0 {
+		return nil, nil
+	}
+
+	blocks, err := dbaccess.BlocksByHashes(ctx, blockHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var lowest *uint64
+	for _, block := range blocks {
+		if lowest == nil || block.BlueScore < *lowest {
+			blueScore := block.BlueScore
+			lowest = &blueScore
+		}
+	}
+	return lowest, nil
+}
+
+func publishConfirmedTransactionsNotifications(ctx database.Context, threshold uint64, fromBlueScore uint64,
+	toBlueScore uint64, selectedTipBlueScore uint64) error {
+
+	dbTransactions, err := dbaccess.AcceptedTransactionsByChainBlueScoreRange(ctx, fromBlueScore, toBlueScore,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	topic := path.Join(ConfirmedTransactionsTopic, strconv.FormatUint(threshold, 10))
+	return publishTransactionsNotifications(ctx, topic, dbTransactions, selectedTipBlueScore)
+}
@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	kafkaBatchTimeout = 10 * time.Millisecond
+
+	// kafkaTopicHeader is the header of Kafka messages that
+	// holds the topic name the notification was published to
+	kafkaTopicHeader = "topic"
+)
+
+// kafkaWriter is the subset of *kafka.Writer that kafkaPublisher uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// kafkaPublisher publishes notifications to a single Kafka topic.
+// Kafka topic names can't contain the slashes and colons that notification
+// topics are made of, nor should a Kafka topic be created per address, so
+// the notification topic is set as both the key and the topic header of every
+// message instead. Since messages are partitioned by their key, notifications
+// of the same topic are consumed in the order they were published.
+type kafkaPublisher struct {
+	writer kafkaWriter
+}
+
+func newKafkaPublisher(brokers []string, topic string) *kafkaPublisher {
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:      brokers,
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: kafkaBatchTimeout,
+	})
+	log.Infof("Publishing to Kafka topic %s in %s", topic, brokers)
+
+	return &kafkaPublisher{writer: writer}
+}
+
+func (p *kafkaPublisher) Name() string {
+	return "kafka"
+}
+
+func (p *kafkaPublisher) Publish(topic string, payload []byte) error {
+	err := p.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(topic),
+		Value: payload,
+		Headers: []kafka.Header{
+			{Key: kafkaTopicHeader, Value: []byte(topic)},
+		},
+	})
+	return errors.WithStack(err)
+}
+
+func (p *kafkaPublisher) Close() {
+	err := p.writer.Close()
+	if err != nil {
+		log.Errorf("Error closing the Kafka writer: %s", err)
+	}
+}
@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+)
+
+const (
+	mqttQualityOfService    = 2
+	mqttQuiesceMilliseconds = 250
+)
+
+// mqttPublisher publishes notifications to an MQTT broker
+type mqttPublisher struct {
+	client mqtt.Client
+}
+
+func newMQTTPublisher(brokerAddress string, user string, password string) (*mqttPublisher, error) {
+	options := mqtt.NewClientOptions()
+	options.AddBroker(brokerAddress)
+	options.SetUsername(user)
+	options.SetPassword(password)
+	options.SetAutoReconnect(true)
+
+	client := mqtt.NewClient(options)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	log.Infof("Connected to MQTT in %s", brokerAddress)
+
+	return &mqttPublisher{client: client}, nil
+}
+
+func (p *mqttPublisher) Name() string {
+	return "mqtt"
+}
+
+func (p *mqttPublisher) Publish(topic string, payload []byte) error {
+	token := p.client.Publish(topic, mqttQualityOfService, false, payload)
+	token.Wait()
+	if token.Error() != nil {
+		return errors.WithStack(token.Error())
+	}
+	return nil
+}
+
+func (p *mqttPublisher) Close() {
+	p.client.Disconnect(mqttQuiesceMilliseconds)
+}
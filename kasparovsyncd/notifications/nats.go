@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const natsReconnectWait = 2 * time.Second
+
+// natsConnection is the subset of *nats.Conn that natsPublisher uses
+type natsConnection interface {
+	Publish(subject string, data []byte) error
+	Close()
+}
+
+// natsPublisher publishes notifications to a NATS server. The
+// topic names are used as-is as subjects, so subscribers should use
+// the full topic name, since NATS wildcards only match dot-separated
+// tokens.
+type natsPublisher struct {
+	conn natsConnection
+}
+
+func newNATSPublisher(url string, user string, password string) (*natsPublisher, error) {
+	options := []nats.Option{
+		nats.Name("kasparovsyncd"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(natsReconnectWait),
+	}
+	if user != "" {
+		options = append(options, nats.UserInfo(user, password))
+	}
+
+	conn, err := nats.Connect(url, options...)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Connected to NATS in %s", url)
+
+	return &natsPublisher{conn: conn}, nil
+}
+
+func (p *natsPublisher) Name() string {
+	return "nats"
+}
+
+func (p *natsPublisher) Publish(topic string, payload []byte) error {
+	return errors.WithStack(p.conn.Publish(topic, payload))
+}
+
+func (p *natsPublisher) Close() {
+	p.conn.Close()
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"encoding/json"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/kasparovsyncd/config"
+	"github.com/kaspanet/kasparov/metrics"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Publisher publishes notification payloads to a message broker.
+// Topics are the slash-separated topic names defined in this package,
+// and payloads are JSON-encoded apimodels types.
+type Publisher interface {
+	// Name returns a short name of the publisher, used in logs and metrics
+	Name() string
+
+	// Publish publishes the given payload to the given topic
+	Publish(topic string, payload []byte) error
+
+	// Close disconnects the publisher from its message broker
+	Close()
+}
+
+// publishers are the publishers that notifications are currently published to
+var publishers []Publisher
+
+var publishFailuresCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "notifications",
+	Name:      "publish_failures_total",
+	Help:      "Number of notifications that failed to be published, by publisher",
+}, []string{"publisher"})
+
+// Connect connects to all the message brokers that are defined in the config
+func Connect() error {
+	cfg := config.ActiveConfig()
+
+	if cfg.MQTTBrokerAddress != "" {
+		publisher, err := newMQTTPublisher(cfg.MQTTBrokerAddress, cfg.MQTTUser, cfg.MQTTPassword)
+		if err != nil {
+			Close()
+			return errors.Wrap(err, "error connecting to MQTT")
+		}
+		Register(publisher)
+	}
+
+	if cfg.NATSURL != "" {
+		publisher, err := newNATSPublisher(cfg.NATSURL, cfg.NATSUser, cfg.NATSPassword)
+		if err != nil {
+			Close()
+			return errors.Wrap(err, "error connecting to NATS")
+		}
+		Register(publisher)
+	}
+
+	if len(cfg.KafkaBrokers) > 0 {
+		Register(newKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic))
+	}
+
+	return nil
+}
+
+// Register adds the given publisher to the publishers
+// that notifications are published to
+func Register(publisher Publisher) {
+	publishers = append(publishers, publisher)
+	log.Infof("Publishing notifications to %s", publisher.Name())
+}
+
+// Close closes all the publishers and removes them
+func Close() {
+	for _, publisher := range publishers {
+		publisher.Close()
+	}
+	publishers = nil
+}
+
+func isConnected() bool {
+	return len(publishers) > 0
+}
+
+// enqueue encodes the given data as JSON and adds it to the outbox, from which
+// the relay publishes it to the given topic. Notifications enqueued within a
+// database transaction are only published once it commits, so they are
+// neither lost nor published for changes that were rolled back.
+func enqueue(ctx database.Context, topic string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return dbaccess.InsertOutboxNotification(ctx, topic, payload)
+}
+
+// publish publishes the given payload to the given topic with all the
+// publishers. A failure of one publisher doesn't prevent the others
+// from publishing, and the first failure is returned.
+func publish(topic string, payload []byte) error {
+	var firstErr error
+	for _, publisher := range publishers {
+		err := publisher.Publish(topic, payload)
+		if err != nil {
+			publishFailuresCounter.WithLabelValues(publisher.Name()).Inc()
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "error publishing to %s", publisher.Name())
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,234 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeMQTTToken struct {
+	mqtt.Token
+	err error
+}
+
+func (t *fakeMQTTToken) Wait() bool {
+	return true
+}
+
+func (t *fakeMQTTToken) Error() error {
+	return t.err
+}
+
+type fakeMQTTClient struct {
+	mqtt.Client
+	messages []*Message
+}
+
+func (c *fakeMQTTClient) Publish(topic string, _ byte, _ bool, payload interface{}) mqtt.Token {
+	c.messages = append(c.messages, &Message{Topic: topic, Payload: payload.([]byte)})
+	return &fakeMQTTToken{}
+}
+
+func (c *fakeMQTTClient) Disconnect(uint) {}
+
+type fakeNATSConnection struct {
+	messages []*Message
+	closed   bool
+}
+
+func (c *fakeNATSConnection) Publish(subject string, data []byte) error {
+	c.messages = append(c.messages, &Message{Topic: subject, Payload: data})
+	return nil
+}
+
+func (c *fakeNATSConnection) Close() {
+	c.closed = true
+}
+
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+	closed   bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Name() string {
+	return "failing"
+}
+
+func (failingPublisher) Publish(string, []byte) error {
+	return errors.New("publish failed")
+}
+
+func (failingPublisher) Close() {}
+
+func TestPublish(t *testing.T) {
+	mqttClient := &fakeMQTTClient{}
+	natsConn := &fakeNATSConnection{}
+	kafkaWriter := &fakeKafkaWriter{}
+	channelPublisher := NewChannelPublisher(1)
+
+	Register(&mqttPublisher{client: mqttClient})
+	Register(&natsPublisher{conn: natsConn})
+	Register(&kafkaPublisher{writer: kafkaWriter})
+	Register(channelPublisher)
+	defer Close()
+
+	const topic = "transactions/accepted/kaspa:qz"
+	data := map[string]string{"transactionId": "abc"}
+	expectedPayload, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("json.Marshal: %s", err)
+	}
+
+	err = publish(topic, expectedPayload)
+	if err != nil {
+		t.Fatalf("publish: %s", err)
+	}
+
+	checkMessages := func(publisherName string, messages []*Message) {
+		if len(messages) != 1 {
+			t.Fatalf("%s: Expected 1 message but got %d", publisherName, len(messages))
+		}
+		if messages[0].Topic != topic {
+			t.Errorf("%s: Expected topic %s but got %s", publisherName, topic, messages[0].Topic)
+		}
+		if !bytes.Equal(messages[0].Payload, expectedPayload) {
+			t.Errorf("%s: Expected payload %s but got %s", publisherName, expectedPayload, messages[0].Payload)
+		}
+	}
+
+	checkMessages("mqtt", mqttClient.messages)
+	checkMessages("nats", natsConn.messages)
+	checkMessages("channel", []*Message{<-channelPublisher.Messages()})
+
+	if len(kafkaWriter.messages) != 1 {
+		t.Fatalf("kafka: Expected 1 message but got %d", len(kafkaWriter.messages))
+	}
+	kafkaMessage := kafkaWriter.messages[0]
+	if string(kafkaMessage.Key) != topic {
+		t.Errorf("kafka: Expected key %s but got %s", topic, kafkaMessage.Key)
+	}
+	if len(kafkaMessage.Headers) != 1 || kafkaMessage.Headers[0].Key != kafkaTopicHeader ||
+		string(kafkaMessage.Headers[0].Value) != topic {
+		t.Errorf("kafka: Expected a single %s header with value %s but got %v",
+			kafkaTopicHeader, topic, kafkaMessage.Headers)
+	}
+	if !bytes.Equal(kafkaMessage.Value, expectedPayload) {
+		t.Errorf("kafka: Expected payload %s but got %s", expectedPayload, kafkaMessage.Value)
+	}
+
+	Close()
+	if !natsConn.closed || !kafkaWriter.closed {
+		t.Errorf("Expected all publishers to be closed")
+	}
+	if _, ok := <-channelPublisher.Messages(); ok {
+		t.Errorf("Expected the channel of the channel publisher to be closed")
+	}
+	if isConnected() {
+		t.Errorf("Expected no publishers after Close")
+	}
+}
+
+func TestPublishFailure(t *testing.T) {
+	channelPublisher := NewChannelPublisher(1)
+	Register(failingPublisher{})
+	Register(channelPublisher)
+	defer Close()
+
+	err := publish(BlocksTopic, []byte("{}"))
+	if err == nil {
+		t.Fatalf("publish: Expected an error but got none")
+	}
+
+	// The failure of one publisher must not prevent the others from publishing
+	select {
+	case message := <-channelPublisher.Messages():
+		if message.Topic != BlocksTopic {
+			t.Errorf("Expected topic %s but got %s", BlocksTopic, message.Topic)
+		}
+	default:
+		t.Errorf("Expected the channel publisher to publish despite the failure")
+	}
+}
+
+func TestAddSequenceToPayload(t *testing.T) {
+	tests := []struct {
+		payload         string
+		expectedPayload string
+		expectedError   bool
+	}{
+		{`{"hash":"abc","blueScore":5}`, `{"sequence":7,"hash":"abc","blueScore":5}`, false},
+		{`{}`, `{"sequence":7}`, false},
+		{` { } `, `{"sequence":7}`, false},
+		{`["abc"]`, ``, true},
+		{`{`, ``, true},
+		{``, ``, true},
+	}
+
+	for _, test := range tests {
+		payload, err := addSequenceToPayload([]byte(test.payload), 7)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: Expected an error but got none", test.payload)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %s", test.payload, err)
+			continue
+		}
+		if string(payload) != test.expectedPayload {
+			t.Errorf("%s: Expected %s but got %s", test.payload, test.expectedPayload, payload)
+		}
+		if !json.Valid(payload) {
+			t.Errorf("%s: Result %s is not valid JSON", test.payload, payload)
+		}
+	}
+}
+
+func TestConfirmationNotificationsStartBlueScore(t *testing.T) {
+	blueScore := func(blueScore uint64) *uint64 {
+		return &blueScore
+	}
+
+	tests := []struct {
+		name                        string
+		notifiedBlueScore           uint64
+		toBlueScore                 uint64
+		lowestRemovedChainBlueScore *uint64
+		expectedFromBlueScore       uint64
+	}{
+		{"tip advanced", 100, 105, nil, 100},
+		{"tip unchanged", 100, 100, nil, 100},
+		{"removed above notified", 100, 105, blueScore(102), 100},
+		{"removed below notified", 100, 105, blueScore(98), 97},
+		{"removed at notified", 100, 105, blueScore(100), 99},
+		{"tip decreased", 100, 95, nil, 95},
+		{"removed below decreased tip", 100, 95, blueScore(90), 89},
+		{"removed at genesis", 100, 105, blueScore(0), 0},
+	}
+
+	for _, test := range tests {
+		fromBlueScore := confirmationNotificationsStartBlueScore(test.notifiedBlueScore, test.toBlueScore,
+			test.lowestRemovedChainBlueScore)
+		if fromBlueScore != test.expectedFromBlueScore {
+			t.Errorf("%s: Expected %d but got %d", test.name, test.expectedFromBlueScore, fromBlueScore)
+		}
+	}
+}
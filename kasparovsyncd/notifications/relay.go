@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/metrics"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// relayPollInterval is the interval in which the outbox
+	// is checked for new notifications once it's empty
+	relayPollInterval = 250 * time.Millisecond
+
+	// relayBatchSize is the maximum number of notifications
+	// that are read from the outbox at once
+	relayBatchSize = 100
+
+	// relayMinRetryDelay and relayMaxRetryDelay bound the delay before
+	// retrying to publish a notification, which doubles on every failure
+	relayMinRetryDelay = time.Second
+	relayMaxRetryDelay = time.Minute
+
+	// sentNotificationsRetention is how long sent notifications
+	// are kept in the outbox before they're deleted
+	sentNotificationsRetention = 24 * time.Hour
+)
+
+var unsentNotificationsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "notifications",
+	Name:      "unsent",
+	Help:      "Number of notifications in the outbox that were not published yet",
+})
+
+// StartRelay starts publishing the notifications in the outbox, in the order
+// they were enqueued. A notification that fails to be published is retried
+// with an exponential backoff, and the notifications after it wait until it
+// succeeds. Since a notification is retried with all the publishers, consumers
+// may receive it more than once, and should use its sequence number to detect
+// duplicates as well as gaps. It returns a function to stop the relay.
+func StartRelay() func() {
+	if !isConnected() {
+		// No publishers are defined -- nothing is enqueued, so there's nothing to relay
+		return func() {}
+	}
+
+	doneChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+
+	spawn("notifications-StartRelay", func() {
+		defer close(stoppedChan)
+
+		retryDelay := relayMinRetryDelay
+		lastPruneTime := time.Time{}
+		for {
+			delay := relayPollInterval
+			err := relayOutbox()
+			if err != nil {
+				log.Errorf("Error relaying notifications: %s. Retrying in %s", err, retryDelay)
+				delay = retryDelay
+				retryDelay *= 2
+				if retryDelay > relayMaxRetryDelay {
+					retryDelay = relayMaxRetryDelay
+				}
+			} else {
+				retryDelay = relayMinRetryDelay
+			}
+
+			if time.Since(lastPruneTime) > time.Hour {
+				err := dbaccess.DeleteOutboxNotificationsSentBefore(database.NoTx(),
+					time.Now().Add(-sentNotificationsRetention))
+				if err != nil {
+					log.Errorf("Error deleting sent notifications: %s", err)
+				} else {
+					lastPruneTime = time.Now()
+				}
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-doneChan:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(doneChan)
+		<-stoppedChan
+	}
+}
+
+// relayOutbox publishes the unsent notifications in the outbox until it's
+// empty, and stops at the first notification that fails to be published.
+func relayOutbox() error {
+	for {
+		outboxNotifications, err := dbaccess.UnsentOutboxNotifications(database.NoTx(), relayBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(outboxNotifications) == 0 {
+			unsentNotificationsGauge.Set(0)
+			return nil
+		}
+
+		for _, outboxNotification := range outboxNotifications {
+			payload, err := addSequenceToPayload(outboxNotification.Payload, outboxNotification.Sequence)
+			if err != nil {
+				return err
+			}
+
+			err = publish(outboxNotification.Topic, payload)
+			if err != nil {
+				updateErr := dbaccess.UpdateOutboxNotificationFailed(database.NoTx(), outboxNotification.ID, err.Error())
+				if updateErr != nil {
+					return updateErr
+				}
+				return err
+			}
+
+			err = dbaccess.UpdateOutboxNotificationSent(database.NoTx(), outboxNotification.ID, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		unsentNotificationCount, err := dbaccess.UnsentOutboxNotificationCount(database.NoTx())
+		if err != nil {
+			return err
+		}
+		unsentNotificationsGauge.Set(float64(unsentNotificationCount))
+	}
+}
+
+// addSequenceToPayload adds the sequence number of a notification as a
+// "sequence" field to its payload, which must be a JSON object.
+func addSequenceToPayload(payload []byte, sequence uint64) ([]byte, error) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) < 2 || payload[0] != '{' || payload[len(payload)-1] != '}' {
+		return nil, errors.Errorf("notification payload is not a JSON object: %s", payload)
+	}
+
+	sequencedPayload := make([]byte, 0, len(payload)+32)
+	sequencedPayload = append(sequencedPayload, `{"sequence":`...)
+	sequencedPayload = strconv.AppendUint(sequencedPayload, sequence, 10)
+	rest := bytes.TrimSpace(payload[1:])
+	if len(rest) > 1 {
+		sequencedPayload = append(sequencedPayload, ',')
+	}
+	return append(sequencedPayload, rest...), nil
+}
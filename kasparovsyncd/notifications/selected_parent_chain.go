@@ -1,16 +1,17 @@
-package mqtt
+package notifications
 
 import (
 	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
 	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
 )
 
-// SelectedParentChainTopic is an MQTT topic for changes in the
+// SelectedParentChainTopic is a topic for changes in the
 // selected parent chain
 const SelectedParentChainTopic = "dag/selected-parent-chain"
 
 // PublishSelectedParentChainNotifications publishes notifications for changes in the selected parent chain
-func PublishSelectedParentChainNotifications(removedChainHashes []string, addedChainBlocks []rpcmodel.ChainBlock) error {
+func PublishSelectedParentChainNotifications(ctx database.Context, removedChainHashes []string, addedChainBlocks []rpcmodel.ChainBlock) error {
 	if !isConnected() {
 		return nil
 	}
@@ -32,5 +33,5 @@ func PublishSelectedParentChainNotifications(removedChainHashes []string, addedC
 	}
 	notificationData.RemovedBlockHashes = removedChainHashes
 
-	return publish(SelectedParentChainTopic, notificationData)
+	return enqueue(ctx, SelectedParentChainTopic, notificationData)
 }
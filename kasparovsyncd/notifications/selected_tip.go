@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+const (
+	// SelectedTipTopic is a topic for DAG selected tips
+	SelectedTipTopic = "dag/selected-tip"
+)
+
+// PublishSelectedTipNotification publishes notification for a new selected tip.
+// It must be called within the database transaction that updates the selected
+// parent chain, so that the notification is enqueued atomically with the update.
+func PublishSelectedTipNotification(ctx database.Context, selectedTipHash string) error {
+	if !isConnected() {
+		return nil
+	}
+	dbBlock, err := dbaccess.BlockByHash(ctx, selectedTipHash, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	block := apimodels.ConvertBlockModelToBlockResponse(dbBlock, dbBlock.BlueScore)
+	return enqueue(ctx, SelectedTipTopic, block)
+}
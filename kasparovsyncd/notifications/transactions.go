@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"github.com/kaspanet/kasparov/database"
+	"path"
+
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+const (
+	// TransactionsTopic is a topic for transactions
+	TransactionsTopic = "transactions"
+
+	// AcceptedTransactionsTopic is a topic for accepted transactions
+	AcceptedTransactionsTopic = "transactions/accepted"
+
+	// UnacceptedTransactionsTopic is a topic for unaccepted transactions
+	UnacceptedTransactionsTopic = "transactions/unaccepted"
+)
+
+// publishTransactionsNotifications publishes notifications for each transaction of the given transactions
+func publishTransactionsNotifications(ctx database.Context, topic string, dbTransactions []*dbmodels.Transaction, selectedTipBlueScore uint64) error {
+	for _, dbTransaction := range dbTransactions {
+		transaction := apimodels.ConvertTxModelToTxResponse(dbTransaction, selectedTipBlueScore)
+		addresses := transaction.UniqueAddresses()
+		for _, address := range addresses {
+			err := publishTransactionNotificationForAddress(ctx, transaction, address, topic)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func publishTransactionNotificationForAddress(ctx database.Context, transaction *apimodels.TransactionResponse,
+	address string, topic string) error {
+
+	return enqueue(ctx, path.Join(topic, address), transaction)
+}
+
+// PublishAcceptedTransactionsNotifications publishes notification for each accepted transaction of the given chain-block
+func PublishAcceptedTransactionsNotifications(ctx database.Context, addedChainBlocks []rpcmodel.ChainBlock) error {
+	if !isConnected() {
+		return nil
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, addedChainBlock := range addedChainBlocks {
+		for _, acceptedBlock := range addedChainBlock.AcceptedBlocks {
+			dbTransactions, err := dbaccess.TransactionsByIDsAndBlockHash(ctx, acceptedBlock.AcceptedTxIDs, acceptedBlock.Hash,
+				dbmodels.TransactionRecommendedPreloadedFields...)
+			if err != nil {
+				return err
+			}
+
+			err = publishTransactionsNotifications(ctx, AcceptedTransactionsTopic, dbTransactions, selectedTipBlueScore)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PublishUnacceptedTransactionsNotifications publishes notification for each unaccepted transaction of the given chain-block
+func PublishUnacceptedTransactionsNotifications(ctx database.Context, unacceptedTransactions []*dbmodels.Transaction) error {
+	if !isConnected() {
+		return nil
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = publishTransactionsNotifications(ctx, UnacceptedTransactionsTopic, unacceptedTransactions, selectedTipBlueScore)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// updateAddressBalances updates the balances of the addresses that the given transactions
+// pay to or spend from. It must be called whenever the transactions are accepted or
+// unaccepted, since that's when their previous outputs are marked spent or unspent.
+// The transactions must be loaded with their outputs and their inputs' previous outputs.
+func updateAddressBalances(dbTx *database.TxContext, dbTransactions []*dbmodels.Transaction,
+	isAccepted bool, acceptingBlueScore uint64) error {
+
+	changes := addressBalanceChanges(dbTransactions, isAccepted)
+	if !isAccepted {
+		return dbaccess.UpdateAddressBalances(dbTx, changes, nil)
+	}
+	return dbaccess.UpdateAddressBalances(dbTx, changes, &acceptingBlueScore)
+}
+
+// addressBalanceChanges sums the changes that accepting (or unaccepting)
+// the given transactions causes to the balances of their addresses.
+// The changes are sorted by address ID.
+func addressBalanceChanges(dbTransactions []*dbmodels.Transaction, isAccepted bool) []*dbaccess.AddressBalanceChange {
+	sign := int64(1)
+	if !isAccepted {
+		sign = -1
+	}
+
+	changesByAddressID := make(map[uint64]*dbaccess.AddressBalanceChange)
+	changeOf := func(addressID uint64) *dbaccess.AddressBalanceChange {
+		change, ok := changesByAddressID[addressID]
+		if !ok {
+			change = &dbaccess.AddressBalanceChange{AddressID: addressID}
+			changesByAddressID[addressID] = change
+		}
+		return change
+	}
+
+	for _, dbTransaction := range dbTransactions {
+		touchedAddressIDs := make(map[uint64]struct{})
+		for _, dbTransactionOutput := range dbTransaction.TransactionOutputs {
+			if dbTransactionOutput.AddressID == nil {
+				continue
+			}
+			changeOf(*dbTransactionOutput.AddressID).BalanceChange += sign * int64(dbTransactionOutput.Value)
+			touchedAddressIDs[*dbTransactionOutput.AddressID] = struct{}{}
+		}
+		for _, dbTransactionInput := range dbTransaction.TransactionInputs {
+			dbPreviousTransactionOutput := dbTransactionInput.PreviousTransactionOutput
+			if dbPreviousTransactionOutput.AddressID == nil {
+				continue
+			}
+			changeOf(*dbPreviousTransactionOutput.AddressID).BalanceChange -= sign * int64(dbPreviousTransactionOutput.Value)
+			touchedAddressIDs[*dbPreviousTransactionOutput.AddressID] = struct{}{}
+		}
+		for addressID := range touchedAddressIDs {
+			changeOf(addressID).TransactionCountChange += sign
+		}
+	}
+
+	changes := make([]*dbaccess.AddressBalanceChange, 0, len(changesByAddressID))
+	for _, change := range changesByAddressID {
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].AddressID < changes[j].AddressID
+	})
+	return changes
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"time"
+
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+// recordChainChange persists a change in the selected parent chain, along with
+// the transactions that it unaccepted. The transactions that it accepted aren't
+// persisted, since they're derived from the added chain blocks. It must be
+// called within the database transaction that applies the change.
+func recordChainChange(dbTx *database.TxContext, unacceptedTransactions []*dbmodels.Transaction,
+	removedChainHashes []string, addedChainBlocks []rpcmodel.ChainBlock) error {
+
+	addedChainBlockHashes := make([]string, len(addedChainBlocks))
+	for i, addedChainBlock := range addedChainBlocks {
+		addedChainBlockHashes[i] = addedChainBlock.Hash
+	}
+
+	changedBlockHashes := make([]string, 0, len(removedChainHashes)+len(addedChainBlockHashes))
+	changedBlockHashes = append(changedBlockHashes, removedChainHashes...)
+	changedBlockHashes = append(changedBlockHashes, addedChainBlockHashes...)
+	dbBlocks, err := dbaccess.BlocksByHashes(dbTx, changedBlockHashes)
+	if err != nil {
+		return err
+	}
+	blockHashesToIDs := make(map[string]uint64, len(dbBlocks))
+	for _, dbBlock := range dbBlocks {
+		blockHashesToIDs[dbBlock.BlockHash] = dbBlock.ID
+	}
+
+	chainChange := &dbmodels.ChainChange{
+		ChangedAt:               time.Now(),
+		Depth:                   uint64(len(removedChainHashes)),
+		ChainChangeBlocks:       make([]dbmodels.ChainChangeBlock, 0, len(removedChainHashes)+len(addedChainBlocks)),
+		ChainChangeTransactions: make([]dbmodels.ChainChangeTransaction, 0, len(unacceptedTransactions)),
+	}
+	for i, removedHash := range removedChainHashes {
+		blockID, ok := blockHashesToIDs[removedHash]
+		if !ok {
+			return errors.Errorf("missing block for hash: %s", removedHash)
+		}
+		chainChange.ChainChangeBlocks = append(chainChange.ChainChangeBlocks, dbmodels.ChainChangeBlock{
+			BlockID: blockID,
+			IsAdded: false,
+			Index:   uint32(i),
+		})
+	}
+	for i, addedHash := range addedChainBlockHashes {
+		blockID, ok := blockHashesToIDs[addedHash]
+		if !ok {
+			return errors.Errorf("missing block for hash: %s", addedHash)
+		}
+		chainChange.ChainChangeBlocks = append(chainChange.ChainChangeBlocks, dbmodels.ChainChangeBlock{
+			BlockID: blockID,
+			IsAdded: true,
+			Index:   uint32(i),
+		})
+	}
+	for _, transaction := range unacceptedTransactions {
+		if transaction.AcceptingBlockID == nil {
+			return errors.Errorf("unaccepted transaction %s has no accepting block", transaction.TransactionID)
+		}
+		chainChange.ChainChangeTransactions = append(chainChange.ChainChangeTransactions, dbmodels.ChainChangeTransaction{
+			TransactionID: transaction.ID,
+			BlockID:       *transaction.AcceptingBlockID,
+		})
+	}
+
+	return dbaccess.InsertChainChange(dbTx, chainChange)
+}
@@ -0,0 +1,148 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/hex"
+	gosync "sync"
+
+	"github.com/kaspanet/kaspad/domainmessage"
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kaspad/util/daghash"
+	"github.com/kaspanet/kasparov/jsonrpc"
+)
+
+// fetchSemaphore bounds the number of concurrent block requests to the node.
+// Every request holds a slot only for its own duration, so requests
+// that are issued by other requests can't deadlock.
+var fetchSemaphore chan struct{}
+
+// initFetcher sets the maximum number of concurrent block requests to the node
+func initFetcher(workers int) {
+	fetchSemaphore = make(chan struct{}, workers)
+}
+
+func acquireFetchSlot() {
+	fetchSemaphore <- struct{}{}
+}
+
+func releaseFetchSlot() {
+	<-fetchSemaphore
+}
+
+// fetchBlock downloads the serialized block and raw block data of
+// the block with hash blockHash. Both are requested concurrently.
+func fetchBlock(client *jsonrpc.Client, blockHash *daghash.Hash) (
+	*rawAndVerboseBlock, error) {
+	log.Debugf("Getting block %s from the RPC server", blockHash)
+
+	var msgBlock *domainmessage.MsgBlock
+	var getBlockErr error
+	done := make(chan struct{})
+	spawn("fetchBlock-GetBlock", func() {
+		defer close(done)
+		acquireFetchSlot()
+		defer releaseFetchSlot()
+		msgBlock, getBlockErr = client.GetBlock(blockHash, nil)
+	})
+
+	acquireFetchSlot()
+	verboseBlock, err := client.GetBlockVerboseTx(blockHash, nil)
+	releaseFetchSlot()
+
+	<-done
+	if getBlockErr != nil {
+		return nil, getBlockErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bytes.NewBuffer(make([]byte, 0, msgBlock.SerializeSize()))
+	err = msgBlock.Serialize(writer)
+	if err != nil {
+		return nil, err
+	}
+	rawBlock := hex.EncodeToString(writer.Bytes())
+
+	return &rawAndVerboseBlock{
+		Raw:     rawBlock,
+		Verbose: verboseBlock,
+	}, nil
+}
+
+// fetchBlocks downloads the blocks with the given hashes concurrently, and
+// returns them in the same order. The number of concurrent requests to the
+// node is bounded by --fetchworkers.
+func fetchBlocks(client *jsonrpc.Client, blockHashes []*daghash.Hash) ([]*rawAndVerboseBlock, error) {
+	blocks := make([]*rawAndVerboseBlock, len(blockHashes))
+	errs := make([]error, len(blockHashes))
+
+	var waitGroup gosync.WaitGroup
+	waitGroup.Add(len(blockHashes))
+	for i, blockHash := range blockHashes {
+		i, blockHash := i, blockHash
+		spawn("fetchBlocks-fetchBlock", func() {
+			defer waitGroup.Done()
+			blocks[i], errs[i] = fetchBlock(client, blockHash)
+		})
+	}
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// blocksBatch is a batch of blocks returned by getBlocks
+type blocksBatch struct {
+	rawBlocks     []string
+	verboseBlocks []rpcmodel.GetBlockVerboseResult
+}
+
+// prefetchBlocksBatches calls getBlocks starting from startHash until there
+// are no more blocks, and sends the batches in order on the returned channel,
+// so that the following batches are downloaded while the current one is
+// being inserted into the database. Up to prefetchBatches batches are kept in
+// memory until they're received. The channel is closed once there are no more
+// blocks, after sending an error if one occurred. Closing doneChan stops
+// prefetching.
+func prefetchBlocksBatches(client *jsonrpc.Client, startHash *string, prefetchBatches int,
+	doneChan <-chan struct{}) (batches <-chan *blocksBatch, errChan <-chan error) {
+
+	batchChan := make(chan *blocksBatch, prefetchBatches)
+	prefetchErrChan := make(chan error, 1)
+	spawn("prefetchBlocksBatches", func() {
+		defer close(batchChan)
+		for {
+			if startHash != nil {
+				log.Debugf("Calling getBlocks with start hash %s", *startHash)
+			} else {
+				log.Debugf("Calling getBlocks with no start hash")
+			}
+			blocksResult, err := client.GetBlocks(true, true, startHash)
+			if err != nil {
+				prefetchErrChan <- err
+				return
+			}
+			if len(blocksResult.Hashes) == 0 {
+				return
+			}
+			log.Debugf("Got %d blocks", len(blocksResult.Hashes))
+
+			startHash = &blocksResult.Hashes[len(blocksResult.Hashes)-1]
+			batch := &blocksBatch{
+				rawBlocks:     blocksResult.RawBlocks,
+				verboseBlocks: blocksResult.VerboseBlocks,
+			}
+			select {
+			case batchChan <- batch:
+			case <-doneChan:
+				return
+			}
+		}
+	})
+	return batchChan, prefetchErrChan
+}
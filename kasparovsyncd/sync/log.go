@@ -1,9 +1,11 @@
 package sync
 
 import (
+	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/kaspanet/kasparov/logger"
 )
 
 var (
-	log = logger.Logger("SYNC")
+	log   = logger.Logger("SYNC")
+	spawn = panics.GoroutineWrapperFunc(log)
 )
@@ -0,0 +1,311 @@
+package sync
+
+import (
+	"encoding/hex"
+	"time"
+
+	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/kaspanet/kasparov/jsonrpc"
+	"github.com/kaspanet/kasparov/kasparovsyncd/config"
+	"github.com/kaspanet/kasparov/serializer"
+	"github.com/pkg/errors"
+)
+
+// maxMempoolTransactionsPerPoll is the maximum number of new mempool
+// transactions that are fetched from the node in a single poll. The
+// rest are fetched in the following polls.
+const maxMempoolTransactionsPerPoll = 500
+
+// startMempoolSync starts polling the mempool of the node every --mempoolpollinterval
+// and returns a function to stop it. Polling runs in its own goroutine, so that the
+// RPC calls it makes don't hold up the processing of blocks and chain changes.
+// A transaction may still be added to the mempool tables right after the block
+// that includes it was processed, in which case it's removed in the next poll.
+func startMempoolSync(client *jsonrpc.Client) func() {
+	interval := config.ActiveConfig().MempoolPollInterval
+	if interval == 0 {
+		return func() {}
+	}
+
+	doneChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	spawn("sync-startMempoolSync", func() {
+		defer close(stoppedChan)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := syncMempool(client)
+				if err != nil {
+					// Failing to sync the mempool shouldn't stop the sync
+					log.Warnf("Error syncing the mempool: %s", err)
+				}
+			case <-doneChan:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(doneChan)
+		<-stoppedChan
+	}
+}
+
+// syncMempool updates the mempool tables to match the mempool of the node:
+// transactions that entered the mempool are added, and transactions that left
+// it, either because they were included in a block or because they were
+// evicted, are removed.
+func syncMempool(client *jsonrpc.Client) error {
+	nodeMempoolTxIDs, err := client.GetRawMempool()
+	if err != nil {
+		return err
+	}
+	nodeMempoolTxIDsSet := make(map[string]struct{}, len(nodeMempoolTxIDs))
+	for _, txID := range nodeMempoolTxIDs {
+		nodeMempoolTxIDsSet[txID.String()] = struct{}{}
+	}
+
+	dbMempoolTxIDs, err := dbaccess.MempoolTransactionIDs(database.NoTx())
+	if err != nil {
+		return err
+	}
+	dbMempoolTxIDsSet := make(map[string]struct{}, len(dbMempoolTxIDs))
+	removedTxIDs := make([]string, 0)
+	for _, txID := range dbMempoolTxIDs {
+		dbMempoolTxIDsSet[txID] = struct{}{}
+		if _, ok := nodeMempoolTxIDsSet[txID]; !ok {
+			removedTxIDs = append(removedTxIDs, txID)
+		}
+	}
+
+	addedTxs := make([]*rpcmodel.TxRawResult, 0)
+	for txID := range nodeMempoolTxIDsSet {
+		if len(addedTxs) == maxMempoolTransactionsPerPoll {
+			break
+		}
+		if _, ok := dbMempoolTxIDsSet[txID]; ok {
+			continue
+		}
+		mempoolEntry, err := client.GetMempoolEntry(txID)
+		if err != nil {
+			// The transaction might have left the mempool since
+			// the mempool was fetched, so this is not fatal.
+			log.Debugf("Couldn't fetch mempool transaction %s: %s", txID, err)
+			continue
+		}
+		addedTxs = append(addedTxs, &mempoolEntry.RawTx)
+	}
+
+	if len(removedTxIDs) == 0 && len(addedTxs) == 0 {
+		return nil
+	}
+
+	dbTx, err := database.NewTx()
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessCommitted()
+
+	err = dbaccess.DeleteMempoolTransactions(dbTx, removedTxIDs)
+	if err != nil {
+		return err
+	}
+
+	err = insertMempoolTransactions(dbTx, addedTxs)
+	if err != nil {
+		return err
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Mempool synced: %d transactions added, %d removed", len(addedTxs), len(removedTxIDs))
+	return nil
+}
+
+// previousOutput is the address and value of an output spent by a mempool transaction
+type previousOutput struct {
+	address *string
+	value   uint64
+}
+
+func insertMempoolTransactions(dbTx *database.TxContext, verboseTxs []*rpcmodel.TxRawResult) error {
+	if len(verboseTxs) == 0 {
+		return nil
+	}
+
+	outpointsToPreviousOutputs, err := mempoolPreviousOutputs(dbTx, verboseTxs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, verboseTx := range verboseTxs {
+		dbMempoolTx, err := dbMempoolTransactionFromVerboseTx(verboseTx, outpointsToPreviousOutputs, now)
+		if err != nil {
+			return err
+		}
+		err = dbaccess.InsertMempoolTransaction(dbTx, dbMempoolTx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mempoolPreviousOutputs returns the addresses and values of all the outputs spent by
+// the given transactions that are known, whether they're in the DAG or in the mempool
+func mempoolPreviousOutputs(dbTx *database.TxContext, verboseTxs []*rpcmodel.TxRawResult) (
+	map[dbaccess.Outpoint]*previousOutput, error) {
+
+	outpointsToPreviousOutputs := make(map[dbaccess.Outpoint]*previousOutput)
+	for _, verboseTx := range verboseTxs {
+		for i, txOut := range verboseTx.Vout {
+			outpointsToPreviousOutputs[dbaccess.Outpoint{
+				TransactionID: verboseTx.TxID,
+				Index:         uint32(i),
+			}] = &previousOutput{
+				address: txOut.ScriptPubKey.Address,
+				value:   txOut.Value,
+			}
+		}
+	}
+
+	outpoints := make([]*dbaccess.Outpoint, 0)
+	for _, verboseTx := range verboseTxs {
+		for _, txIn := range verboseTx.Vin {
+			outpoint := dbaccess.Outpoint{
+				TransactionID: txIn.TxID,
+				Index:         txIn.Vout,
+			}
+			if _, ok := outpointsToPreviousOutputs[outpoint]; ok {
+				continue
+			}
+			outpoints = append(outpoints, &outpoint)
+		}
+	}
+	if len(outpoints) == 0 {
+		return outpointsToPreviousOutputs, nil
+	}
+
+	dbOutputs, err := dbaccess.TransactionOutputsByOutpoints(dbTx, outpoints,
+		dbmodels.TransactionOutputFieldNames.Address)
+	if err != nil {
+		return nil, err
+	}
+	for _, dbOutput := range dbOutputs {
+		var address *string
+		if dbOutput.Address != nil {
+			address = &dbOutput.Address.Address
+		}
+		outpointsToPreviousOutputs[dbaccess.Outpoint{
+			TransactionID: dbOutput.Transaction.TransactionID,
+			Index:         dbOutput.Index,
+		}] = &previousOutput{
+			address: address,
+			value:   dbOutput.Value,
+		}
+	}
+
+	dbMempoolOutputs, err := dbaccess.MempoolTransactionOutputsByOutpoints(dbTx, outpoints)
+	if err != nil {
+		return nil, err
+	}
+	for _, dbMempoolOutput := range dbMempoolOutputs {
+		outpointsToPreviousOutputs[dbaccess.Outpoint{
+			TransactionID: dbMempoolOutput.MempoolTransaction.TransactionID,
+			Index:         dbMempoolOutput.Index,
+		}] = &previousOutput{
+			address: dbMempoolOutput.Address,
+			value:   dbMempoolOutput.Value,
+		}
+	}
+
+	return outpointsToPreviousOutputs, nil
+}
+
+func dbMempoolTransactionFromVerboseTx(verboseTx *rpcmodel.TxRawResult,
+	outpointsToPreviousOutputs map[dbaccess.Outpoint]*previousOutput, firstSeenAt time.Time) (
+	*dbmodels.MempoolTransaction, error) {
+
+	rawTransaction, err := hex.DecodeString(verboseTx.Hex)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	payload, err := hex.DecodeString(verboseTx.Payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	outputs := make([]dbmodels.MempoolTransactionOutput, len(verboseTx.Vout))
+	for i, txOut := range verboseTx.Vout {
+		scriptPubKey, err := hex.DecodeString(txOut.ScriptPubKey.Hex)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		outputs[i] = dbmodels.MempoolTransactionOutput{
+			Index:        uint32(i),
+			Value:        txOut.Value,
+			ScriptPubKey: scriptPubKey,
+			Address:      txOut.ScriptPubKey.Address,
+		}
+	}
+
+	inputs := make([]dbmodels.MempoolTransactionInput, len(verboseTx.Vin))
+	for i, txIn := range verboseTx.Vin {
+		signatureScript, err := hex.DecodeString(txIn.ScriptSig.Hex)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		inputs[i] = dbmodels.MempoolTransactionInput{
+			Index:                          uint32(i),
+			PreviousTransactionID:          txIn.TxID,
+			PreviousTransactionOutputIndex: txIn.Vout,
+			SignatureScript:                signatureScript,
+			Sequence:                       serializer.Uint64ToBytes(txIn.Sequence),
+		}
+		previousOutput, ok := outpointsToPreviousOutputs[dbaccess.Outpoint{
+			TransactionID: txIn.TxID,
+			Index:         txIn.Vout,
+		}]
+		if ok {
+			inputs[i].Address = previousOutput.address
+			inputs[i].Value = &previousOutput.value
+		}
+	}
+
+	return &dbmodels.MempoolTransaction{
+		TransactionHash:           verboseTx.Hash,
+		TransactionID:             verboseTx.TxID,
+		LockTime:                  serializer.Uint64ToBytes(verboseTx.LockTime),
+		SubnetworkID:              verboseTx.Subnetwork,
+		Gas:                       verboseTx.Gas,
+		PayloadHash:               verboseTx.PayloadHash,
+		Payload:                   payload,
+		Version:                   verboseTx.Version,
+		RawTransaction:            rawTransaction,
+		FirstSeenAt:               firstSeenAt,
+		MempoolTransactionOutputs: outputs,
+		MempoolTransactionInputs:  inputs,
+	}, nil
+}
+
+// removeIncludedMempoolTransactions removes the given transactions, which were just
+// included in blocks, from the mempool tables
+func removeIncludedMempoolTransactions(dbTx *database.TxContext,
+	transactionHashesToTxsWithMetadata map[string]*txWithMetadata) error {
+
+	txIDs := make([]string, 0, len(transactionHashesToTxsWithMetadata))
+	for _, transaction := range transactionHashesToTxsWithMetadata {
+		txIDs = append(txIDs, transaction.verboseTx.TxID)
+	}
+	return dbaccess.DeleteMempoolTransactions(dbTx, txIDs)
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/jsonrpc"
+	"github.com/kaspanet/kasparov/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// syncLagUpdateInterval is the interval in which the sync lag metric is updated
+const syncLagUpdateInterval = 30 * time.Second
+
+var (
+	blocksAddedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "sync",
+		Name:      "blocks_added_total",
+		Help:      "Number of blocks added to the database",
+	})
+
+	chainChangedMsgsProcessedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "sync",
+		Name:      "chain_changed_msgs_processed_total",
+		Help:      "Number of chain-changed messages processed",
+	})
+
+	pendingChainChangedMsgsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "sync",
+		Name:      "pending_chain_changed_msgs",
+		Help:      "Number of chain-changed messages that are waiting for missing blocks to be processed",
+	})
+
+	syncLagGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "sync",
+		Name:      "selected_tip_blue_score_lag",
+		Help:      "Difference between the blue scores of the selected tips of the node and of the database",
+	})
+)
+
+// updateSyncLag updates the sync lag metric. Failures are
+// only logged, since they shouldn't stop the sync.
+func updateSyncLag(client *jsonrpc.Client) {
+	nodeSelectedTipBlueScore, err := fetchNodeSelectedTipBlueScore(client)
+	if err != nil {
+		log.Warnf("Error fetching the selected tip of the node: %s", err)
+		return
+	}
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		log.Warnf("Error fetching the selected tip from the database: %s", err)
+		return
+	}
+	syncLagGauge.Set(float64(nodeSelectedTipBlueScore) - float64(selectedTipBlueScore))
+}
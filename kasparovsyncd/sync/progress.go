@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+)
+
+// progressLogInterval is the minimal interval between two progress logs
+const progressLogInterval = 10 * time.Second
+
+// syncProgress tracks the progress of a sync phase towards a
+// target and periodically logs it along with its rate and ETA.
+type syncProgress struct {
+	description string
+	unit        string
+	current     uint64
+	target      uint64
+
+	startCurrent uint64
+	startTime    time.Time
+	lastLogTime  time.Time
+}
+
+func newSyncProgress(description string, unit string, current uint64, target uint64) *syncProgress {
+	now := time.Now()
+	return &syncProgress{
+		description:  description,
+		unit:         unit,
+		current:      current,
+		target:       target,
+		startCurrent: current,
+		startTime:    now,
+		lastLogTime:  now,
+	}
+}
+
+// update sets the current progress, and logs it if
+// progressLogInterval had passed since the last log.
+func (p *syncProgress) update(current uint64) {
+	p.current = current
+	if time.Since(p.lastLogTime) < progressLogInterval {
+		return
+	}
+	p.lastLogTime = time.Now()
+	log.Infof("%s: %s", p.description, p.String())
+}
+
+func (p *syncProgress) String() string {
+	elapsed := time.Since(p.startTime)
+	rate := float64(0)
+	if p.current > p.startCurrent && elapsed > 0 {
+		rate = float64(p.current-p.startCurrent) / elapsed.Seconds()
+	}
+
+	if p.target == 0 || p.current >= p.target {
+		return fmt.Sprintf("%d %s (%.1f %s/s)", p.current, p.unit, rate, p.unit)
+	}
+
+	percentage := float64(p.current) / float64(p.target) * 100
+	eta := "unknown"
+	if rate > 0 {
+		remaining := time.Duration(float64(p.target-p.current) / rate * float64(time.Second))
+		eta = remaining.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%d/%d %s (%.2f%%, %.1f %s/s, ETA %s)",
+		p.current, p.target, p.unit, percentage, rate, p.unit, eta)
+}
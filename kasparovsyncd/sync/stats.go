@@ -0,0 +1,21 @@
+package sync
+
+import (
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/pkg/errors"
+)
+
+// updateHourlyStats adds the given blocks, which were just inserted
+// along with their transactions, to the network statistics rollups
+func updateHourlyStats(dbTx *database.TxContext, blocks []*rawAndVerboseBlock, blockHashesToIDs map[string]uint64) error {
+	blockIDs := make([]uint64, len(blocks))
+	for i, block := range blocks {
+		blockID, ok := blockHashesToIDs[block.hash()]
+		if !ok {
+			return errors.Errorf("couldn't find block ID for block %s", block)
+		}
+		blockIDs[i] = blockID
+	}
+	return dbaccess.UpdateHourlyStats(dbTx, blockIDs)
+}
@@ -1,14 +1,17 @@
 package sync
 
 import (
-	"bytes"
-	"encoding/hex"
+	"time"
+
 	"github.com/kaspanet/kasparov/database"
 
+	"github.com/kaspanet/kasparov/dagevents"
 	"github.com/kaspanet/kasparov/dbaccess"
 	"github.com/kaspanet/kasparov/dbmodels"
 	"github.com/kaspanet/kasparov/jsonrpc"
-	"github.com/kaspanet/kasparov/kasparovsyncd/mqtt"
+	"github.com/kaspanet/kasparov/kasparovsyncd/config"
+	"github.com/kaspanet/kasparov/kasparovsyncd/notifications"
+	"github.com/kaspanet/kasparov/kasparovsyncd/webhooks"
 
 	rpcmodel "github.com/kaspanet/kaspad/rpc/model"
 	"github.com/kaspanet/kaspad/util/daghash"
@@ -27,6 +30,8 @@ func StartSync(doneChan chan struct{}) error {
 		return err
 	}
 
+	initFetcher(config.ActiveConfig().FetchWorkers)
+
 	// Mass download missing data
 	err = fetchInitialData(client)
 	if err != nil {
@@ -38,24 +43,47 @@ func StartSync(doneChan chan struct{}) error {
 }
 
 // fetchInitialData downloads all data that's currently missing from
-// the database.
+// the database. If a previous run was interrupted while doing so, it
+// resumes from where it stopped.
 func fetchInitialData(client *jsonrpc.Client) error {
-	log.Infof("Syncing past blocks")
-	err := syncBlocks(client)
+	err := loadSyncState()
 	if err != nil {
 		return err
 	}
+
+	if syncState.Phase == dbmodels.SyncPhaseBlocks {
+		log.Infof("Syncing past blocks")
+		err := syncBlocks(client)
+		if err != nil {
+			return err
+		}
+		err = setSyncPhase(dbmodels.SyncPhaseChain)
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Infof("Syncing past selected parent chain")
 	err = syncSelectedParentChain(client)
 	if err != nil {
 		return err
 	}
+	err = setSyncPhase(dbmodels.SyncPhaseSynced)
+	if err != nil {
+		return err
+	}
 	log.Infof("Finished syncing past data")
 	return nil
 }
 
 // sync keeps the database in sync with the node via notifications
 func sync(client *jsonrpc.Client, doneChan chan struct{}) error {
+	updateSyncLag(client)
+	syncLagTicker := time.NewTicker(syncLagUpdateInterval)
+	defer syncLagTicker.Stop()
+	stopMempoolSync := startMempoolSync(client)
+	defer stopMempoolSync()
+
 	// Handle client notifications until we're told to stop
 	for {
 		select {
@@ -70,6 +98,8 @@ func sync(client *jsonrpc.Client, doneChan chan struct{}) error {
 			if err != nil {
 				return err
 			}
+		case <-syncLagTicker.C:
+			updateSyncLag(client)
 		case <-doneChan:
 			log.Infof("StartSync stopped")
 			return nil
@@ -77,56 +107,80 @@ func sync(client *jsonrpc.Client, doneChan chan struct{}) error {
 	}
 }
 
-// syncBlocks attempts to download all DAG blocks starting with
-// the bluest block, and then inserts them into the database.
+// syncBlocks attempts to download all DAG blocks starting with the
+// last block checkpointed in the sync state, or with the bluest block
+// if there's none, and then inserts them into the database.
 func syncBlocks(client *jsonrpc.Client) error {
-	// Start syncing from the bluest block hash. We use blue score to
-	// simulate the "last" block we have because blue-block order is
-	// the order that the node uses in the various JSONRPC calls.
-	startBlock, err := dbaccess.BluestBlock(database.NoTx())
-	if err != nil {
-		return err
-	}
-	var startHash *string
-	if startBlock != nil {
-		startHash = &startBlock.BlockHash
-	}
-
-	for {
-		if startHash != nil {
-			log.Debugf("Calling getBlocks with start hash %s", *startHash)
-		} else {
-			log.Debugf("Calling getBlocks with no start hash")
-		}
-		blocksResult, err := client.GetBlocks(true, true, startHash)
+	startHash := syncState.LastBlockHash
+	if startHash == nil {
+		// Start syncing from the bluest block hash. We use blue score to
+		// simulate the "last" block we have because blue-block order is
+		// the order that the node uses in the various JSONRPC calls.
+		startBlock, err := dbaccess.BluestBlock(database.NoTx())
 		if err != nil {
 			return err
 		}
-		if len(blocksResult.Hashes) == 0 {
-			break
+		if startBlock != nil {
+			startHash = &startBlock.BlockHash
 		}
-		log.Debugf("Got %d blocks", len(blocksResult.Hashes))
+	}
+
+	progress, err := newBlocksSyncProgress(client)
+	if err != nil {
+		return err
+	}
+
+	doneChan := make(chan struct{})
+	defer close(doneChan)
+	batches, prefetchErrChan := prefetchBlocksBatches(client, startHash,
+		config.ActiveConfig().PrefetchBatches, doneChan)
 
-		startHash = &blocksResult.Hashes[len(blocksResult.Hashes)-1]
-		err = addBlocks(client, blocksResult.RawBlocks, blocksResult.VerboseBlocks)
+	for batch := range batches {
+		addedBlockCount, err := addBlocks(client, batch.rawBlocks, batch.verboseBlocks)
 		if err != nil {
 			return err
 		}
+		progress.update(progress.current + uint64(addedBlockCount))
 	}
 
-	return nil
+	select {
+	case err := <-prefetchErrChan:
+		return err
+	default:
+		return nil
+	}
+}
+
+func newBlocksSyncProgress(client *jsonrpc.Client) (*syncProgress, error) {
+	blockCount, err := dbaccess.BlocksCount(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+	nodeBlockCount, err := client.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+	return newSyncProgress("Syncing past blocks", "blocks", blockCount, uint64(nodeBlockCount)), nil
 }
 
 // syncSelectedParentChain attempts to download the selected parent
-// chain starting with the bluest chain-block, and then updates the
-// database accordingly.
+// chain starting with the last chain block checkpointed in the sync
+// state, or with the selected tip if there's none, and then updates
+// the database accordingly.
 func syncSelectedParentChain(client *jsonrpc.Client) error {
-	// Start syncing from the selected tip hash
 	startBlock, err := dbaccess.SelectedTip(database.NoTx())
 	if err != nil {
 		return err
 	}
 	startHash := startBlock.BlockHash
+	if syncState.LastChainBlockHash != nil {
+		startHash = *syncState.LastChainBlockHash
+	}
+
+	progress, err := newChainSyncProgress(client, startHash)
+	if err != nil {
+		return err
+	}
 
 	for {
 		log.Debugf("Calling getChainFromBlock with start hash %s", startHash)
@@ -144,34 +198,54 @@ func syncSelectedParentChain(client *jsonrpc.Client) error {
 		if err != nil {
 			return err
 		}
+
+		blueScore, err := blockBlueScore(startHash)
+		if err != nil {
+			return err
+		}
+		progress.update(blueScore)
 	}
 	return nil
 }
 
-// fetchBlock downloads the serialized block and raw block data of
-// the block with hash blockHash.
-func fetchBlock(client *jsonrpc.Client, blockHash *daghash.Hash) (
-	*rawAndVerboseBlock, error) {
-	log.Debugf("Getting block %s from the RPC server", blockHash)
-	msgBlock, err := client.GetBlock(blockHash, nil)
+// newChainSyncProgress returns a syncProgress that measures the progress
+// of the selected parent chain sync by the blue score of the last chain
+// block that was synced.
+func newChainSyncProgress(client *jsonrpc.Client, startHash string) (*syncProgress, error) {
+	startBlueScore, err := blockBlueScore(startHash)
 	if err != nil {
 		return nil, err
 	}
-	writer := bytes.NewBuffer(make([]byte, 0, msgBlock.SerializeSize()))
-	err = msgBlock.Serialize(writer)
+	nodeSelectedTipBlueScore, err := fetchNodeSelectedTipBlueScore(client)
 	if err != nil {
 		return nil, err
 	}
-	rawBlock := hex.EncodeToString(writer.Bytes())
+	return newSyncProgress("Syncing past selected parent chain", "blue score", startBlueScore, nodeSelectedTipBlueScore), nil
+}
 
-	verboseBlock, err := client.GetBlockVerboseTx(blockHash, nil)
+// fetchNodeSelectedTipBlueScore returns the blue score of the selected tip of the node
+func fetchNodeSelectedTipBlueScore(client *jsonrpc.Client) (uint64, error) {
+	selectedTipHash, err := client.GetSelectedTipHash()
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+
+	selectedTip, err := client.GetBlockVerboseTx(selectedTipHash, nil)
+	if err != nil {
+		return 0, err
+	}
+	return selectedTip.BlueScore, nil
+}
+
+func blockBlueScore(blockHash string) (uint64, error) {
+	dbBlock, err := dbaccess.BlockByHash(database.NoTx(), blockHash)
+	if err != nil {
+		return 0, err
 	}
-	return &rawAndVerboseBlock{
-		Raw:     rawBlock,
-		Verbose: verboseBlock,
-	}, nil
+	if dbBlock == nil {
+		return 0, errors.Errorf("block %s was not found in the database", blockHash)
+	}
+	return dbBlock.BlueScore, nil
 }
 
 // updateSelectedParentChain updates the database to reflect the current selected
@@ -192,6 +266,11 @@ func updateSelectedParentChain(client *jsonrpc.Client, removedChainHashes []stri
 
 	defer dbTx.RollbackUnlessCommitted()
 
+	previousSelectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(dbTx)
+	if err != nil {
+		return err
+	}
+
 	for _, removedHash := range removedChainHashes {
 		err := updateRemovedChainHashes(dbTx, removedHash)
 		if err != nil {
@@ -211,65 +290,154 @@ func updateSelectedParentChain(client *jsonrpc.Client, removedChainHashes []stri
 		}
 	}
 
-	err = dbTx.Commit()
+	if len(addedChainBlocks) > 0 {
+		err = checkpointChain(dbTx, addedChainBlocks[len(addedChainBlocks)-1].Hash, len(addedChainBlocks))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recordChainChange(dbTx, unacceptedTransactions, removedChainHashes, addedChainBlocks)
 	if err != nil {
 		return err
 	}
 
-	err = mqtt.PublishUnacceptedTransactionsNotifications(unacceptedTransactions)
+	err = notifySelectedParentChainChanged(dbTx, unacceptedTransactions, removedChainHashes,
+		addedChainBlocks, missingBlockHashes)
+	if err != nil {
+		return err
+	}
+
+	err = notifications.PublishUnacceptedTransactionsNotifications(dbTx, unacceptedTransactions)
 	if err != nil {
 		return errors.Wrap(err, "Error while publishing unaccepted transactions notifications")
 	}
 
-	err = mqtt.PublishAcceptedTransactionsNotifications(addedChainBlocks)
+	err = notifications.PublishAcceptedTransactionsNotifications(dbTx, addedChainBlocks)
 	if err != nil {
 		return errors.Wrap(err, "Error while publishing accepted transactions notifications")
 
 	}
 
-	err = mqtt.PublishSelectedParentChainNotifications(removedChainHashes, addedChainBlocks)
+	err = notifications.PublishConfirmedTransactionsNotifications(dbTx, removedChainHashes)
+	if err != nil {
+		return errors.Wrap(err, "Error while publishing confirmed transactions notifications")
+	}
+
+	err = notifications.PublishSelectedParentChainNotifications(dbTx, removedChainHashes, addedChainBlocks)
 	if err != nil {
 		return errors.Wrap(err, "Error while publishing chain changes notifications")
 	}
 
+	if len(addedChainBlocks) > 0 {
+		selectedTipHash := addedChainBlocks[len(addedChainBlocks)-1].Hash
+		err = dagevents.NotifySelectedTipChanged(dbTx, selectedTipHash)
+		if err != nil {
+			return err
+		}
+		err = notifications.PublishSelectedTipNotification(dbTx, selectedTipHash)
+		if err != nil {
+			return errors.Wrap(err, "Error while publishing selected tip notification")
+		}
+	}
+
 	for _, hash := range missingBlockHashes {
-		err := mqtt.PublishBlockAddedNotifications(hash)
+		err := notifications.PublishBlockAddedNotifications(dbTx, hash)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	err = webhooks.EnqueueActivityDeliveries(dbTx, missingBlockHashes)
+	if err != nil {
+		return errors.Wrap(err, "Error while enqueueing activity webhook deliveries")
+	}
+
+	err = webhooks.EnqueueUnacceptedDeliveries(dbTx, unacceptedTransactions)
+	if err != nil {
+		return errors.Wrap(err, "Error while enqueueing unaccepted webhook deliveries")
+	}
+
+	err = webhooks.EnqueueConfirmedDeliveries(dbTx, previousSelectedTipBlueScore, removedChainHashes)
+	if err != nil {
+		return errors.Wrap(err, "Error while enqueueing confirmed webhook deliveries")
+	}
+
+	return dbTx.Commit()
+}
+
+// notifySelectedParentChainChanged sends DAG events about a selected parent chain
+// update. It's called within the database transaction of the update, so that the
+// events are delivered only once the update is committed.
+func notifySelectedParentChainChanged(dbTx *database.TxContext, unacceptedTransactions []*dbmodels.Transaction,
+	removedChainHashes []string, addedChainBlocks []rpcmodel.ChainBlock, missingBlockHashes []string) error {
+
+	unacceptedTransactionIDs := make([]uint64, len(unacceptedTransactions))
+	for i, transaction := range unacceptedTransactions {
+		unacceptedTransactionIDs[i] = transaction.ID
+	}
+	err := dagevents.NotifyTransactionsUnaccepted(dbTx, unacceptedTransactionIDs)
+	if err != nil {
+		return err
+	}
+
+	addedChainBlockHashes := make([]string, len(addedChainBlocks))
+	for i, addedChainBlock := range addedChainBlocks {
+		addedChainBlockHashes[i] = addedChainBlock.Hash
+	}
+	err = dagevents.NotifyChainChanged(dbTx, removedChainHashes, addedChainBlockHashes)
+	if err != nil {
+		return err
+	}
+
+	return dagevents.NotifyBlocksAdded(dbTx, missingBlockHashes)
 }
 
 // fetchAndAddMissingAddedChainBlocks takes cares of cases where a block referenced in a selectedParent-chain
 // have not yet been added to the database. In that case - it fetches it and its missing ancestors and add them
 // to the database.
-func fetchAndAddMissingAddedChainBlocks(client *jsonrpc.Client, dbTx *database.TxContext, addedChainBlocks []rpcmodel.ChainBlock) (missingBlockHashes []string, err error) {
-	missingBlockHashes = make([]string, 0)
-	for _, block := range addedChainBlocks {
-		dbBlock, err := dbaccess.BlockByHash(dbTx, block.Hash)
+func fetchAndAddMissingAddedChainBlocks(client *jsonrpc.Client, dbTx *database.TxContext, addedChainBlocks []rpcmodel.ChainBlock) (insertedBlockHashes []string, err error) {
+	addedChainBlockHashes := make([]string, len(addedChainBlocks))
+	for i, block := range addedChainBlocks {
+		addedChainBlockHashes[i] = block.Hash
+	}
+	missingChainBlockHashes, err := missingBlockHashes(dbTx, addedChainBlockHashes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hashesToFetch := make([]*daghash.Hash, len(missingChainBlockHashes))
+	for i, missingHash := range missingChainBlockHashes {
+		log.Debugf("Block %s not found in the database - fetching from node", missingHash)
+		hashesToFetch[i], err = daghash.NewHashFromStr(missingHash)
 		if err != nil {
 			return nil, err
 		}
+	}
+	blocks, err := fetchBlocks(client, hashesToFetch)
+	if err != nil {
+		return nil, err
+	}
 
-		if dbBlock != nil {
-			continue
-		}
-
-		log.Debugf("Block %s not found in the database - fetching from node", block.Hash)
-		blockHash, err := daghash.NewHashFromStr(block.Hash)
+	insertedBlockHashes = make([]string, 0)
+	for _, block := range blocks {
+		// The block might have been added as a missing
+		// ancestor of a previous missing chain block
+		blockExists, err := dbaccess.DoesBlockExist(dbTx, block.hash())
 		if err != nil {
 			return nil, err
 		}
+		if blockExists {
+			continue
+		}
 
-		addedBlockHashes, err := fetchAndAddBlock(client, dbTx, blockHash)
+		addedBlockHashes, err := addBlockAndMissingAncestors(client, dbTx, block)
 		if err != nil {
 			return nil, err
 		}
-		missingBlockHashes = append(missingBlockHashes, addedBlockHashes...)
+		insertedBlockHashes = append(insertedBlockHashes, addedBlockHashes...)
 	}
-	return missingBlockHashes, nil
+	return insertedBlockHashes, nil
 }
 
 // updateRemovedChainHashes "unaccepts" the block of the given removedHash.
@@ -291,7 +459,13 @@ func updateRemovedChainHashes(dbTx *database.TxContext, removedHash string) erro
 		return errors.Errorf("block erroneously marked as not a chain block: %s", removedHash)
 	}
 
+	err = dbaccess.UpdateHourlyAcceptanceStats(dbTx, dbBlock.ID, false)
+	if err != nil {
+		return err
+	}
+
 	dbTransactions, err := dbaccess.AcceptedTransactionsByBlockID(dbTx, dbBlock.ID,
+		dbmodels.TransactionFieldNames.TransactionOutputs,
 		dbmodels.TransactionFieldNames.InputsPreviousTransactionOutputs)
 	if err != nil {
 		return err
@@ -318,6 +492,11 @@ func updateRemovedChainHashes(dbTx *database.TxContext, removedHash string) erro
 		}
 	}
 
+	err = updateAddressBalances(dbTx, dbTransactions, false, dbBlock.BlueScore)
+	if err != nil {
+		return err
+	}
+
 	err = dbaccess.UpdateBlocksAcceptedByAcceptingBlock(dbTx, dbBlock.ID, nil)
 	if err != nil {
 		return err
@@ -370,6 +549,7 @@ func updateAddedChainBlocks(dbTx *database.TxContext, addedBlock *rpcmodel.Chain
 		// can create a situation with multiple transactions on different blocks with
 		// same ID and different hashes.
 		dbAcceptedTransactions, err := dbaccess.TransactionsByIDsAndBlockID(dbTx, transactionIDsIn, dbAcceptedBlock.ID,
+			dbmodels.TransactionFieldNames.TransactionOutputs,
 			dbmodels.TransactionFieldNames.InputsPreviousTransactionOutputs)
 		if err != nil {
 			return err
@@ -398,6 +578,11 @@ func updateAddedChainBlocks(dbTx *database.TxContext, addedBlock *rpcmodel.Chain
 			}
 		}
 
+		err = updateAddressBalances(dbTx, dbAcceptedTransactions, true, dbAddedBlock.BlueScore)
+		if err != nil {
+			return err
+		}
+
 		err = dbaccess.UpdateBlockAcceptingBlockID(dbTx, dbAcceptedBlock.ID, &dbAddedBlock.ID)
 		if err != nil {
 			return err
@@ -409,7 +594,7 @@ func updateAddedChainBlocks(dbTx *database.TxContext, addedBlock *rpcmodel.Chain
 		return err
 	}
 
-	return nil
+	return dbaccess.UpdateHourlyAcceptanceStats(dbTx, dbAddedBlock.ID, true)
 }
 
 func handleBlockAddedMsg(client *jsonrpc.Client, blockAdded *jsonrpc.BlockAddedMsg) error {
@@ -434,18 +619,24 @@ func handleBlockAddedMsg(client *jsonrpc.Client, blockAdded *jsonrpc.BlockAddedM
 		return err
 	}
 
-	err = dbTx.Commit()
+	err = dagevents.NotifyBlocksAdded(dbTx, addedBlockHashes)
 	if err != nil {
 		return err
 	}
 
 	for _, hash := range addedBlockHashes {
-		err := mqtt.PublishBlockAddedNotifications(hash)
+		err := notifications.PublishBlockAddedNotifications(dbTx, hash)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+
+	err = webhooks.EnqueueActivityDeliveries(dbTx, addedBlockHashes)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
 }
 
 func fetchAndAddBlock(client *jsonrpc.Client, dbTx *database.TxContext,
@@ -456,6 +647,14 @@ func fetchAndAddBlock(client *jsonrpc.Client, dbTx *database.TxContext,
 		return nil, err
 	}
 
+	return addBlockAndMissingAncestors(client, dbTx, block)
+}
+
+// addBlockAndMissingAncestors fetches the ancestors of the given block
+// that are missing from the database, and adds them along with the block.
+func addBlockAndMissingAncestors(client *jsonrpc.Client, dbTx *database.TxContext,
+	block *rawAndVerboseBlock) (addedBlockHashes []string, err error) {
+
 	missingAncestors, err := fetchMissingAncestors(client, dbTx, block, nil)
 	if err != nil {
 		return nil, err
@@ -488,7 +687,7 @@ func fetchMissingAncestors(client *jsonrpc.Client, dbTx *database.TxContext, blo
 		if err != nil {
 			return nil, err
 		}
-		blocksToPrependToPending := make([]*rawAndVerboseBlock, 0, len(missingParentHashes))
+		hashesToFetch := make([]*daghash.Hash, 0, len(missingParentHashes))
 		for _, missingHash := range missingParentHashes {
 			if _, ok := missingAncestorsSet[missingHash]; ok {
 				continue
@@ -497,11 +696,11 @@ func fetchMissingAncestors(client *jsonrpc.Client, dbTx *database.TxContext, blo
 			if err != nil {
 				return nil, err
 			}
-			block, err := fetchBlock(client, hash)
-			if err != nil {
-				return nil, err
-			}
-			blocksToPrependToPending = append(blocksToPrependToPending, block)
+			hashesToFetch = append(hashesToFetch, hash)
+		}
+		blocksToPrependToPending, err := fetchBlocks(client, hashesToFetch)
+		if err != nil {
+			return nil, err
 		}
 		if len(blocksToPrependToPending) == 0 {
 			if currentBlock != block {
@@ -558,6 +757,7 @@ func missingBlockHashes(dbTx *database.TxContext, blockHashes []string,
 // enqueueChainChangedMsg enqueues onChainChanged messages to be handled later
 func enqueueChainChangedMsg(chainChanged *jsonrpc.ChainChangedMsg) {
 	pendingChainChangedMsgs = append(pendingChainChangedMsgs, chainChanged)
+	pendingChainChangedMsgsGauge.Set(float64(len(pendingChainChangedMsgs)))
 }
 
 // processChainChangedMsgs processes all pending onChainChanged messages.
@@ -580,6 +780,7 @@ func processChainChangedMsgs(client *jsonrpc.Client) error {
 		}
 	}
 	pendingChainChangedMsgs = unprocessedChainChangedMessages
+	pendingChainChangedMsgsGauge.Set(float64(len(pendingChainChangedMsgs)))
 	return nil
 }
 
@@ -594,8 +795,8 @@ func handleChainChangedMsg(client *jsonrpc.Client, chainChanged *jsonrpc.ChainCh
 	}
 	log.Infof("Chain changed: removed %d blocks and added %d block",
 		len(removedHashes), len(addedBlocks))
-
-	return mqtt.PublishSelectedTipNotification(addedBlocks[len(addedBlocks)-1].Hash)
+	chainChangedMsgsProcessedCounter.Inc()
+	return nil
 }
 
 // canHandleChainChangedMsg checks whether we have all the necessary data
@@ -672,11 +873,14 @@ func convertChainChangedMsg(chainChanged *jsonrpc.ChainChangedMsg) (
 }
 
 // addBlocks inserts data in the given rawBlocks and verboseBlocks pairwise
-// into the database.
-func addBlocks(client *jsonrpc.Client, rawBlocks []string, verboseBlocks []rpcmodel.GetBlockVerboseResult) error {
+// into the database, and checkpoints the last of them in the sync state.
+// It returns the number of blocks that were added, including missing ancestors.
+func addBlocks(client *jsonrpc.Client, rawBlocks []string, verboseBlocks []rpcmodel.GetBlockVerboseResult) (
+	addedBlockCount int, err error) {
+
 	dbTx, err := database.NewTx()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer dbTx.RollbackUnlessCommitted()
 
@@ -685,7 +889,7 @@ func addBlocks(client *jsonrpc.Client, rawBlocks []string, verboseBlocks []rpcmo
 	for i, rawBlock := range rawBlocks {
 		blockExists, err := dbaccess.DoesBlockExist(dbTx, verboseBlocks[i].Hash)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if blockExists {
 			continue
@@ -700,7 +904,7 @@ func addBlocks(client *jsonrpc.Client, rawBlocks []string, verboseBlocks []rpcmo
 			Verbose: &verboseBlocks[i],
 		}, blockHashesToRawAndVerboseBlock)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		blocks = append(blocks, block)
@@ -713,9 +917,21 @@ func addBlocks(client *jsonrpc.Client, rawBlocks []string, verboseBlocks []rpcmo
 	}
 	err = bulkInsertBlocksData(client, dbTx, blocks)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return dbTx.Commit()
+
+	if len(verboseBlocks) > 0 {
+		err = checkpointBlocks(dbTx, verboseBlocks[len(verboseBlocks)-1].Hash, len(blocks))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return len(blocks), nil
 }
 
 // bulkInsertBlocksData inserts the given blocks and their data (transactions
@@ -731,6 +947,11 @@ func bulkInsertBlocksData(client *jsonrpc.Client, dbTx *database.TxContext, bloc
 		return err
 	}
 
+	err = removeIncludedMempoolTransactions(dbTx, transactionHashesToTxsWithMetadata)
+	if err != nil {
+		return err
+	}
+
 	err = insertTransactionOutputs(dbTx, transactionHashesToTxsWithMetadata)
 	if err != nil {
 		return err
@@ -776,6 +997,12 @@ func bulkInsertBlocksData(client *jsonrpc.Client, dbTx *database.TxContext, bloc
 		return err
 	}
 
+	err = updateHourlyStats(dbTx, blocks, blockHashesToIDs)
+	if err != nil {
+		return err
+	}
+
 	log.Infof("Added %d blocks", len(blocks))
+	blocksAddedCounter.Add(float64(len(blocks)))
 	return nil
 }
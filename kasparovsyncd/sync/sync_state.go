@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+)
+
+// syncState is the sync state of this process. It's checkpointed to the
+// database within the same database transactions that add the synced data,
+// so that an interrupted initial sync resumes exactly where it stopped.
+var syncState *dbmodels.SyncState
+
+// loadSyncState loads the sync state from the database. If the previous
+// run had finished its initial sync, a new initial sync is started in
+// order to catch up with whatever was missed since.
+func loadSyncState() error {
+	var err error
+	syncState, err = dbaccess.SyncState(database.NoTx())
+	if err != nil {
+		return err
+	}
+
+	if syncState != nil && syncState.Phase != dbmodels.SyncPhaseSynced {
+		log.Infof("Resuming interrupted sync from phase '%s' (%d blocks and %d chain blocks already synced)",
+			syncState.Phase, syncState.SyncedBlockCount, syncState.SyncedChainBlockCount)
+		return nil
+	}
+
+	now := time.Now()
+	newSyncState := &dbmodels.SyncState{
+		Phase:     dbmodels.SyncPhaseBlocks,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if syncState != nil {
+		newSyncState.LastChainBlockHash = syncState.LastChainBlockHash
+	}
+	syncState = newSyncState
+	return dbaccess.UpdateSyncState(database.NoTx(), syncState)
+}
+
+// setSyncPhase moves the sync state to the given phase
+func setSyncPhase(phase string) error {
+	syncState.Phase = phase
+	syncState.UpdatedAt = time.Now()
+	return dbaccess.UpdateSyncState(database.NoTx(), syncState)
+}
+
+// checkpointBlocks records that addedBlockCount blocks were added, up to and
+// including lastBlockHash in the order returned by getBlocks. It must be called
+// within the database transaction that adds the blocks.
+func checkpointBlocks(dbTx *database.TxContext, lastBlockHash string, addedBlockCount int) error {
+	syncState.LastBlockHash = &lastBlockHash
+	syncState.SyncedBlockCount += uint64(addedBlockCount)
+	syncState.UpdatedAt = time.Now()
+	return dbaccess.UpdateSyncState(dbTx, syncState)
+}
+
+// checkpointChain records that the selected parent chain was updated up to
+// lastChainBlockHash. It must be called within the database transaction that
+// updates the selected parent chain.
+func checkpointChain(dbTx *database.TxContext, lastChainBlockHash string, addedChainBlockCount int) error {
+	syncState.LastChainBlockHash = &lastChainBlockHash
+	syncState.SyncedChainBlockCount += uint64(addedChainBlockCount)
+	syncState.UpdatedAt = time.Now()
+	return dbaccess.UpdateSyncState(dbTx, syncState)
+}
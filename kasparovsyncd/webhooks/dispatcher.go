@@ -0,0 +1,290 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dispatcherPollInterval is the interval in which due
+	// deliveries are checked for once there are none left
+	dispatcherPollInterval = time.Second
+
+	// dispatcherBatchSize is the maximum number of
+	// deliveries that are read from the database at once
+	dispatcherBatchSize = 100
+
+	// maxConcurrentDeliveries is the maximum number
+	// of deliveries that are attempted at once
+	maxConcurrentDeliveries = 10
+
+	// deliveryTimeout is how long a subscriber has to respond to a delivery
+	deliveryTimeout = 10 * time.Second
+
+	// minRetryDelay and maxRetryDelay bound the delay before
+	// retrying a delivery, which doubles on every failure
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = time.Hour
+
+	// maxDeliveryAttempts is the number of failed attempts
+	// after which a delivery is given up on
+	maxDeliveryAttempts = 12
+
+	// maxErrorResponseBodyLength is the maximum number of bytes of the
+	// body of a non-2xx response that are recorded in the delivery log
+	maxErrorResponseBodyLength = 256
+)
+
+// Delivery request headers
+const (
+	signatureHeader  = "X-Kasparov-Signature"
+	eventHeader      = "X-Kasparov-Event"
+	deliveryIDHeader = "X-Kasparov-Delivery"
+)
+
+// StartDispatcher starts POSTing due webhook deliveries to the URLs of their
+// subscriptions. Each delivery is signed with an HMAC-SHA256 of its body,
+// keyed by the secret of its subscription. A delivery that fails, or gets
+// a non-2xx response, is retried with an exponential backoff until it
+// succeeds or maxDeliveryAttempts is reached. Deliveries are attempted
+// concurrently, so subscribers may receive them out of order. It returns
+// a function to stop the dispatcher.
+func StartDispatcher() func() {
+	doneChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	client := newDeliveryClient()
+
+	spawn("webhooks-StartDispatcher", func() {
+		defer close(stoppedChan)
+
+		for {
+			deliveryCount, err := dispatchDueDeliveries(client)
+			if err != nil {
+				log.Errorf("Error dispatching webhook deliveries: %s", err)
+			}
+
+			delay := dispatcherPollInterval
+			if err == nil && deliveryCount == dispatcherBatchSize {
+				// There may be more due deliveries -- dispatch them right away
+				delay = 0
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-doneChan:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(doneChan)
+		<-stoppedChan
+	}
+}
+
+// nonPublicIPNets are the address ranges that deliveries may not be sent
+// to, so that subscriptions can't be used to reach the internal network
+// of kasparovsyncd, such as loopback, private and link-local addresses
+var nonPublicIPNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	ipNets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		ipNets[i] = ipNet
+	}
+	return ipNets
+}
+
+// newDeliveryClient returns an HTTP client that refuses to connect to
+// non-public addresses. The address is checked when connecting, after the
+// host name was resolved, so that a host name that resolves to a public
+// address when the subscription is created and to a private one later on
+// can't be used to get around the check. Proxies are not used, since
+// they'd connect to the subscriber on behalf of the client.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: rejectNonPublicAddress,
+	}
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: deliveryTimeout,
+			MaxIdleConnsPerHost: maxConcurrentDeliveries,
+		},
+	}
+}
+
+// rejectNonPublicAddress is a net.Dialer Control function that fails
+// connections to addresses in nonPublicIPNets
+func rejectNonPublicAddress(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.Errorf("couldn't parse the IP address %s", host)
+	}
+	for _, ipNet := range nonPublicIPNets {
+		if ipNet.Contains(ip) {
+			return errors.Errorf("deliveries to the non-public address %s are not allowed", ip)
+		}
+	}
+	return nil
+}
+
+// dispatchDueDeliveries attempts a batch of due deliveries and
+// records the results. It returns the number of attempted deliveries.
+func dispatchDueDeliveries(client *http.Client) (int, error) {
+	deliveries, err := dbaccess.DueWebhookDeliveries(database.NoTx(), time.Now(), dispatcherBatchSize,
+		dbmodels.WebhookDeliveryFieldNames.Subscription)
+	if err != nil {
+		return 0, err
+	}
+
+	semaphore := make(chan struct{}, maxConcurrentDeliveries)
+	errChan := make(chan error, len(deliveries))
+	wg := sync.WaitGroup{}
+	for _, delivery := range deliveries {
+		delivery := delivery
+		semaphore <- struct{}{}
+		wg.Add(1)
+		spawn("webhooks-dispatchDueDeliveries", func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			statusCode, deliveryErr := deliver(client, delivery)
+			if deliveryErr != nil {
+				log.Debugf("Webhook delivery %d to %s failed: %s", delivery.ID, delivery.Subscription.URL, deliveryErr)
+			}
+			recordAttempt(delivery, statusCode, deliveryErr, time.Now())
+			errChan <- dbaccess.UpdateWebhookDeliveryAttempt(database.NoTx(), delivery)
+		})
+	}
+	wg.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver POSTs the payload of the given delivery to the URL of its
+// subscription. It returns the status code of the response, if there
+// was one, and an error if the delivery didn't succeed.
+func deliver(client *http.Client, delivery *dbmodels.WebhookDelivery) (*int, error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(eventHeader, delivery.Event)
+	request.Header.Set(deliveryIDHeader, strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set(signatureHeader, sign(delivery.Subscription.Secret, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorResponseBodyLength))
+		return &statusCode, errors.Errorf("got status %s: %s", response.Status, body)
+	}
+
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	return &statusCode, nil
+}
+
+// sign returns the value of the signature header of a delivery with the given
+// payload: "sha256=" followed by the hex-encoded HMAC-SHA256 of the payload,
+// keyed by the secret of the subscription
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordAttempt updates the given delivery with the result of an attempt that
+// finished at `now`, and schedules its next attempt if it should be retried
+func recordAttempt(delivery *dbmodels.WebhookDelivery, statusCode *int, deliveryErr error, now time.Time) {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if deliveryErr == nil {
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+		return
+	}
+
+	errorMessage := deliveryErr.Error()
+	delivery.LastError = &errorMessage
+	if delivery.Attempts >= maxDeliveryAttempts {
+		delivery.NextAttemptAt = nil
+		return
+	}
+	nextAttemptAt := now.Add(retryDelay(delivery.Attempts))
+	delivery.NextAttemptAt = &nextAttemptAt
+}
+
+// retryDelay returns the delay before retrying
+// a delivery that failed `attempts` times
+func retryDelay(attempts uint64) time.Duration {
+	delay := minRetryDelay
+	for i := uint64(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
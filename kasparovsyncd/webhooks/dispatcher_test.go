@@ -0,0 +1,163 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+func TestDeliver(t *testing.T) {
+	const secret = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	payload := []byte(`{"subscriptionId":"abc","event":"activity"}`)
+
+	var receivedRequest *http.Request
+	var receivedBody []byte
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedRequest = r
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	delivery := &dbmodels.WebhookDelivery{
+		ID:      42,
+		Event:   apimodels.WebhookEventActivity,
+		Payload: payload,
+		Subscription: &dbmodels.WebhookSubscription{
+			URL:    server.URL,
+			Secret: secret,
+		},
+	}
+
+	receivedStatusCode, err := deliver(server.Client(), delivery)
+	if err != nil {
+		t.Fatalf("deliver: %s", err)
+	}
+	if receivedStatusCode == nil || *receivedStatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d but got %v", http.StatusOK, receivedStatusCode)
+	}
+	if receivedRequest.Method != http.MethodPost {
+		t.Errorf("Expected method %s but got %s", http.MethodPost, receivedRequest.Method)
+	}
+	if string(receivedBody) != string(payload) {
+		t.Errorf("Expected body %s but got %s", payload, receivedBody)
+	}
+	if event := receivedRequest.Header.Get(eventHeader); event != apimodels.WebhookEventActivity {
+		t.Errorf("Expected event %s but got %s", apimodels.WebhookEventActivity, event)
+	}
+	if deliveryID := receivedRequest.Header.Get(deliveryIDHeader); deliveryID != "42" {
+		t.Errorf("Expected delivery ID 42 but got %s", deliveryID)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(receivedBody)
+	expectedSignature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if signature := receivedRequest.Header.Get(signatureHeader); signature != expectedSignature {
+		t.Errorf("Expected signature %s but got %s", expectedSignature, signature)
+	}
+
+	statusCode = http.StatusInternalServerError
+	receivedStatusCode, err = deliver(server.Client(), delivery)
+	if err == nil {
+		t.Fatalf("deliver: Expected an error for status %d but got none", statusCode)
+	}
+	if receivedStatusCode == nil || *receivedStatusCode != statusCode {
+		t.Errorf("Expected status code %d but got %v", statusCode, receivedStatusCode)
+	}
+}
+
+func TestRecordAttempt(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	delivery := &dbmodels.WebhookDelivery{NextAttemptAt: &now}
+	statusCode := http.StatusServiceUnavailable
+
+	for attempt := uint64(1); attempt < maxDeliveryAttempts; attempt++ {
+		recordAttempt(delivery, &statusCode, errors.New("service unavailable"), now)
+		if delivery.Attempts != attempt {
+			t.Fatalf("Expected %d attempts but got %d", attempt, delivery.Attempts)
+		}
+		if delivery.NextAttemptAt == nil {
+			t.Fatalf("Attempt %d: Expected a next attempt to be scheduled", attempt)
+		}
+		expectedDelay := minRetryDelay << (attempt - 1)
+		if expectedDelay > maxRetryDelay {
+			expectedDelay = maxRetryDelay
+		}
+		if delay := delivery.NextAttemptAt.Sub(now); delay != expectedDelay {
+			t.Errorf("Attempt %d: Expected a delay of %s but got %s", attempt, expectedDelay, delay)
+		}
+		if delivery.LastError == nil || delivery.DeliveredAt != nil {
+			t.Errorf("Attempt %d: Expected the delivery to be recorded as failed", attempt)
+		}
+	}
+
+	recordAttempt(delivery, &statusCode, errors.New("service unavailable"), now)
+	if delivery.NextAttemptAt != nil {
+		t.Errorf("Expected the delivery to be given up on after %d attempts", maxDeliveryAttempts)
+	}
+
+	delivery = &dbmodels.WebhookDelivery{NextAttemptAt: &now}
+	statusCode = http.StatusOK
+	recordAttempt(delivery, &statusCode, nil, now)
+	if delivery.NextAttemptAt != nil || delivery.DeliveredAt == nil || !delivery.DeliveredAt.Equal(now) {
+		t.Errorf("Expected the delivery to be recorded as delivered")
+	}
+}
+
+func TestRejectNonPublicAddress(t *testing.T) {
+	tests := []struct {
+		address       string
+		expectedError bool
+	}{
+		{address: "93.184.216.34:443", expectedError: false},
+		{address: "[2606:2800:220:1:248:1893:25c8:1946]:443", expectedError: false},
+		{address: "127.0.0.1:80", expectedError: true},
+		{address: "10.1.2.3:80", expectedError: true},
+		{address: "172.20.0.1:80", expectedError: true},
+		{address: "192.168.1.1:80", expectedError: true},
+		{address: "169.254.169.254:80", expectedError: true},
+		{address: "0.0.0.0:80", expectedError: true},
+		{address: "[::1]:80", expectedError: true},
+		{address: "[::ffff:127.0.0.1]:80", expectedError: true},
+		{address: "[fd00::1]:80", expectedError: true},
+		{address: "[fe80::1]:80", expectedError: true},
+	}
+
+	for _, test := range tests {
+		err := rejectNonPublicAddress("tcp", test.address, nil)
+		if (err != nil) != test.expectedError {
+			t.Errorf("%s: Expected error: %t, but got: %v", test.address, test.expectedError, err)
+		}
+	}
+}
+
+func TestDeliveryClientRejectsLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected the delivery not to reach the server")
+	}))
+	defer server.Close()
+
+	delivery := &dbmodels.WebhookDelivery{
+		ID:           1,
+		Event:        apimodels.WebhookEventActivity,
+		Payload:      []byte(`{}`),
+		Subscription: &dbmodels.WebhookSubscription{URL: server.URL},
+	}
+	statusCode, err := deliver(newDeliveryClient(), delivery)
+	if err == nil {
+		t.Fatalf("deliver: Expected an error when delivering to %s but got none", server.URL)
+	}
+	if statusCode != nil {
+		t.Errorf("Expected no status code but got %d", *statusCode)
+	}
+}
@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kaspanet/kasparov/apimodels"
+	"github.com/kaspanet/kasparov/database"
+	"github.com/kaspanet/kasparov/dbaccess"
+	"github.com/kaspanet/kasparov/dbmodels"
+	"github.com/pkg/errors"
+)
+
+// EnqueueActivityDeliveries enqueues an activity delivery for each transaction
+// of the blocks with the given hashes that pays to or spends from an address
+// that a webhook subscription watches
+func EnqueueActivityDeliveries(ctx database.Context, blockHashes []string) error {
+	matches, err := dbaccess.WebhookMatchesByBlockHashes(ctx, blockHashes)
+	if err != nil {
+		return err
+	}
+
+	return enqueueDeliveries(ctx, apimodels.WebhookEventActivity, matches)
+}
+
+// EnqueueUnacceptedDeliveries enqueues an unaccepted delivery for each of the
+// given transactions that pays to or spends from an address that a webhook
+// subscription watches. The transactions are no longer considered confirmed,
+// so that a confirmed delivery is enqueued again once they're re-accepted.
+func EnqueueUnacceptedDeliveries(ctx database.Context, unacceptedTransactions []*dbmodels.Transaction) error {
+	transactionIDs := make([]uint64, len(unacceptedTransactions))
+	for i, transaction := range unacceptedTransactions {
+		transactionIDs[i] = transaction.ID
+	}
+
+	err := dbaccess.DeleteWebhookConfirmationsByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return err
+	}
+
+	matches, err := dbaccess.WebhookMatchesByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return err
+	}
+
+	return enqueueDeliveries(ctx, apimodels.WebhookEventUnaccepted, matches)
+}
+
+// EnqueueConfirmedDeliveries enqueues a confirmed delivery for each accepted
+// transaction that pays to or spends from an address that a webhook subscription
+// watches, and has just reached the confirmation threshold of the subscription.
+// It must be called within the database transaction that updates the selected
+// parent chain, after the update, with the selected tip blue score from before
+// the update and the hashes of the chain blocks that were removed by it.
+func EnqueueConfirmedDeliveries(ctx database.Context, previousSelectedTipBlueScore uint64,
+	removedChainHashes []string) error {
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The transactions accepted by the removed chain blocks may be re-accepted
+	// by the chain blocks that replaced them, in which case they may reach the
+	// confirmation thresholds again
+	fromBlueScore := previousSelectedTipBlueScore
+	removedChainBlocks, err := dbaccess.BlocksByHashes(ctx, removedChainHashes)
+	if err != nil {
+		return err
+	}
+	for _, block := range removedChainBlocks {
+		if block.BlueScore <= fromBlueScore {
+			fromBlueScore = 0
+			if block.BlueScore > 0 {
+				fromBlueScore = block.BlueScore - 1
+			}
+		}
+	}
+
+	matches, err := dbaccess.WebhookMatchesReachingConfirmationThreshold(ctx, fromBlueScore, selectedTipBlueScore)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	err = dbaccess.InsertWebhookConfirmations(ctx, matches)
+	if err != nil {
+		return err
+	}
+
+	return enqueueDeliveries(ctx, apimodels.WebhookEventConfirmed, matches)
+}
+
+func enqueueDeliveries(ctx database.Context, event string, matches []*dbaccess.WebhookMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	transactionIDSet := make(map[uint64]struct{}, len(matches))
+	transactionIDs := make([]uint64, 0, len(matches))
+	for _, match := range matches {
+		if _, ok := transactionIDSet[match.TransactionID]; ok {
+			continue
+		}
+		transactionIDSet[match.TransactionID] = struct{}{}
+		transactionIDs = append(transactionIDs, match.TransactionID)
+	}
+
+	transactions, err := dbaccess.TransactionsByDBIDs(ctx, transactionIDs,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+	transactionsByID := make(map[uint64]*dbmodels.Transaction, len(transactions))
+	for _, transaction := range transactions {
+		transactionsByID[transaction.ID] = transaction
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]*dbmodels.WebhookDelivery, len(matches))
+	for i, match := range matches {
+		transaction, ok := transactionsByID[match.TransactionID]
+		if !ok {
+			return errors.Errorf("transaction with database ID %d was not found", match.TransactionID)
+		}
+
+		payload, err := json.Marshal(&apimodels.WebhookPayload{
+			SubscriptionID: match.SubscriptionID,
+			Event:          event,
+			Address:        match.Address,
+			Transaction:    apimodels.ConvertTxModelToTxResponse(transaction, selectedTipBlueScore),
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		deliveries[i] = &dbmodels.WebhookDelivery{
+			SubscriptionID: match.SubscriptionID,
+			Event:          event,
+			Address:        match.Address,
+			TransactionID:  match.TransactionID,
+			Payload:        payload,
+			CreatedAt:      now,
+			NextAttemptAt:  &now,
+		}
+	}
+
+	return dbaccess.InsertWebhookDeliveries(ctx, deliveries)
+}
@@ -0,0 +1,11 @@
+package webhooks
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kasparov/logger"
+)
+
+var (
+	log   = logger.Logger("WHKS")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// dbaccessFunctionPrefix is the prefix of the full names of
+	// the functions in the dbaccess package
+	dbaccessFunctionPrefix = "github.com/kaspanet/kasparov/dbaccess."
+
+	// otherDBFunction is the function label of queries that
+	// were not issued by the dbaccess package
+	otherDBFunction = "other"
+
+	// maxQueryCallerDepth is the number of stack frames that are
+	// searched for the dbaccess function that issued a query
+	maxQueryCallerDepth = 32
+)
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of database queries, by the dbaccess function that issued them",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"function", "failed"})
+
+// DBQueryHook is a go-pg query hook that records the duration of every
+// query, labeled by the dbaccess function that issued it.
+type DBQueryHook struct{}
+
+var _ pg.QueryHook = DBQueryHook{}
+
+// BeforeQuery implements pg.QueryHook
+func (DBQueryHook) BeforeQuery(ctx context.Context, _ *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterQuery implements pg.QueryHook
+func (DBQueryHook) AfterQuery(_ context.Context, event *pg.QueryEvent) error {
+	failed := "false"
+	if event.Err != nil {
+		failed = "true"
+	}
+	dbQueryDuration.WithLabelValues(queryCallerFunction(), failed).Observe(time.Since(event.StartTime).Seconds())
+	return nil
+}
+
+// queryCallerFunction returns the name of the dbaccess function that
+// issued the current query. go-pg runs query hooks in the goroutine
+// that issued the query, so that function is found in the call stack.
+func queryCallerFunction() string {
+	programCounters := make([]uintptr, maxQueryCallerDepth)
+	count := runtime.Callers(3, programCounters)
+	frames := runtime.CallersFrames(programCounters[:count])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, dbaccessFunctionPrefix) {
+			functionName := strings.TrimPrefix(frame.Function, dbaccessFunctionPrefix)
+			// Strip the suffix of closures, such as "Blocks.func1"
+			if dotIndex := strings.Index(functionName, "."); dotIndex != -1 {
+				functionName = functionName[:dotIndex]
+			}
+			return functionName
+		}
+		if !more {
+			return otherDBFunction
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/kaspanet/kasparov/logger"
+)
+
+var (
+	log   = logger.Logger("MTRC")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
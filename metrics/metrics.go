@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the prefix of the names of all Kasparov metrics
+const Namespace = "kasparov"
+
+const gracefulShutdownTimeout = 30 * time.Second
+
+// Handler returns an HTTP handler that serves all registered metrics
+// in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// StartServer starts an HTTP server that serves the metrics on
+// /metrics, and returns a function to gracefully shut it down.
+func StartServer(listenAddr string) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: mux,
+	}
+	spawn("metrics-StartServer", func() {
+		log.Infof("Metrics are served on %s", listenAddr)
+		log.Errorf("%s", httpServer.ListenAndServe())
+	})
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+		defer cancel()
+		err := httpServer.Shutdown(ctx)
+		if err != nil {
+			log.Errorf("Error shutting down the metrics server: %s", err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	rpcCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of RPC calls to the node, by method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	rpcCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "call_errors_total",
+		Help:      "Number of failed RPC calls to the node, by method",
+	}, []string{"method"})
+)
+
+// ObserveRPCCall records the duration of an RPC call to the node that
+// started at `start`, and whether it failed. It should be called right
+// after the call returns.
+func ObserveRPCCall(method string, start time.Time, err error) {
+	rpcCallDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		rpcCallErrors.WithLabelValues(method).Inc()
+	}
+}